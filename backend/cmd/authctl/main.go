@@ -0,0 +1,277 @@
+// Command authctl manages API keys and dashboard users for the analytics API.
+//
+// Usage:
+//
+//	authctl [-file ./data/auth.json] [-audit ./data/audit/audit.log] key mint -name <name> -role admin -scopes analytics:read,events:ingest
+//	authctl key list
+//	authctl key revoke <id>
+//	authctl user add -username <name> -role rep -companies <id,id> -manager <name>   (password read from stdin)
+//	authctl user assign <username> -role manager -companies <id,id>
+//	authctl user list
+//	authctl user remove <username>
+//
+// The running server picks up changes without a restart. Every change is
+// recorded in the server's audit log.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// auditPath is the audit log that admin actions are appended to
+var auditPath string
+
+func main() {
+	file := flag.String("file", "./data/auth.json", "credential store path")
+	flag.StringVar(&auditPath, "audit", "./data/audit/audit.log", "audit log path")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	store, err := auth.NewStore(*file)
+	if err != nil {
+		fatal(err)
+	}
+
+	args := flag.Args()
+	switch args[0] + " " + args[1] {
+	case "key mint":
+		err = mintKey(store, args[2:])
+	case "key list":
+		err = listKeys(store)
+	case "key revoke":
+		err = revokeKey(store, args[2:])
+	case "user add":
+		err = addUser(store, args[2:])
+	case "user assign":
+		err = assignUser(store, args[2:])
+	case "user list":
+		err = listUsers(store)
+	case "user remove":
+		err = removeUser(store, args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// mintKey creates a key and prints its token once
+func mintKey(store *auth.Store, args []string) error {
+	flags := flag.NewFlagSet("key mint", flag.ExitOnError)
+	name := flags.String("name", "", "description of who uses the key")
+	scopes := flags.String("scopes", auth.ScopeAnalyticsRead, "comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	role := flags.String("role", "", "role: "+strings.Join(auth.Roles, ", "))
+	companies := flags.String("companies", "", "comma-separated company IDs the key may see (manager and rep roles)")
+	flags.Parse(args)
+
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("-name is required")
+	}
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+
+	key, token, err := store.MintKey(models.APIKey{
+		Name:      *name,
+		Scopes:    parsed,
+		Role:      *role,
+		Companies: auth.ParseCompanies(*companies),
+	})
+	if err != nil {
+		return err
+	}
+
+	record("key.mint", map[string]string{"id": key.ID, "name": key.Name, "role": key.Role, "scopes": strings.Join(key.Scopes, ",")}, key.Companies)
+	fmt.Printf("Minted %s key %s (%s) with scopes %s\n", key.Role, key.ID, key.Name, strings.Join(key.Scopes, ","))
+	fmt.Println("Store this token now, it cannot be shown again:")
+	fmt.Println(token)
+	return nil
+}
+
+// listKeys prints every key without secrets
+func listKeys(store *auth.Store) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tROLE\tSCOPES\tCOMPANIES\tCREATED\tSTATUS")
+	for _, key := range store.Keys() {
+		status := "active"
+		if key.RevokedAt != nil {
+			status = "revoked " + key.RevokedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, auth.EffectiveRole(key.Role), strings.Join(key.Scopes, ","),
+			displayCompanies(key.Companies), key.CreatedAt.Format("2006-01-02"), status)
+	}
+	return w.Flush()
+}
+
+// revokeKey revokes a key by ID
+func revokeKey(store *auth.Store, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: authctl key revoke <id>")
+	}
+
+	key, err := store.RevokeKey(args[0])
+	if err != nil {
+		return err
+	}
+	record("key.revoke", map[string]string{"id": key.ID, "name": key.Name}, key.Companies)
+	fmt.Printf("Revoked key %s (%s)\n", key.ID, key.Name)
+	return nil
+}
+
+// addUser creates a dashboard user, reading the password from stdin
+func addUser(store *auth.Store, args []string) error {
+	flags := flag.NewFlagSet("user add", flag.ExitOnError)
+	username := flags.String("username", "", "login name")
+	scopes := flags.String("scopes", auth.ScopeAnalyticsRead, "comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	role := flags.String("role", "", "role: "+strings.Join(auth.Roles, ", "))
+	companies := flags.String("companies", "", "comma-separated company IDs assigned to the user")
+	manager := flags.String("manager", "", "username of the manager a rep reports to")
+	flags.Parse(args)
+
+	if strings.TrimSpace(*username) == "" {
+		return errors.New("-username is required")
+	}
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	user, err := store.AddUser(models.DashboardUser{
+		Username:  *username,
+		Scopes:    parsed,
+		Role:      *role,
+		Companies: auth.ParseCompanies(*companies),
+		Manager:   *manager,
+	}, password)
+	if err != nil {
+		return err
+	}
+	record("user.add", map[string]string{"username": user.Username, "role": user.Role, "scopes": strings.Join(user.Scopes, ",")}, user.Companies)
+	fmt.Printf("Added %s %s with scopes %s\n", user.Role, user.Username, strings.Join(user.Scopes, ","))
+	return nil
+}
+
+// assignUser replaces a user's role, companies and manager
+func assignUser(store *auth.Store, args []string) error {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		return errors.New("usage: authctl user assign <username> -role <role> [-companies id,id] [-manager name]")
+	}
+
+	flags := flag.NewFlagSet("user assign", flag.ExitOnError)
+	role := flags.String("role", "", "role: "+strings.Join(auth.Roles, ", "))
+	companies := flags.String("companies", "", "comma-separated company IDs assigned to the user")
+	manager := flags.String("manager", "", "username of the manager a rep reports to")
+	flags.Parse(args[1:])
+
+	user, err := store.AssignUser(args[0], *role, auth.ParseCompanies(*companies), *manager)
+	if err != nil {
+		return err
+	}
+	record("user.assign", map[string]string{"username": user.Username, "role": user.Role, "manager": user.Manager}, user.Companies)
+	fmt.Printf("Assigned %s as %s of %s\n", user.Username, user.Role, displayCompanies(user.Companies))
+	return nil
+}
+
+// listUsers prints every dashboard user
+func listUsers(store *auth.Store) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USERNAME\tROLE\tSCOPES\tCOMPANIES\tMANAGER\tCREATED")
+	for _, user := range store.Users() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", user.Username, auth.EffectiveRole(user.Role), strings.Join(user.Scopes, ","),
+			displayCompanies(user.Companies), user.Manager, user.CreatedAt.Format("2006-01-02"))
+	}
+	return w.Flush()
+}
+
+// displayCompanies formats a company assignment for listings
+func displayCompanies(companies []string) string {
+	if len(companies) == 0 {
+		return "-"
+	}
+	return strings.Join(companies, ",")
+}
+
+// removeUser deletes a dashboard user, ending their sessions
+func removeUser(store *auth.Store, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: authctl user remove <username>")
+	}
+
+	if err := store.RemoveUser(args[0]); err != nil {
+		return err
+	}
+	record("user.remove", map[string]string{"username": args[0]}, nil)
+	fmt.Printf("Removed user %s\n", args[0])
+	return nil
+}
+
+// record appends an admin action to the audit log, attributed to the local OS user
+// The change has already been saved, so a failure is reported without failing the command
+func record(action string, params map[string]string, companies []string) {
+	operator := "unknown"
+	if current, err := user.Current(); err == nil {
+		operator = current.Username
+	}
+
+	err := audit.Append(auditPath, models.AuditEntry{
+		Kind:      audit.KindAdmin,
+		Subject:   "cli:" + operator,
+		Action:    action,
+		Params:    params,
+		Companies: companies,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "authctl: warning: %v\n", err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: authctl [-file path] [-audit path] <command>
+
+Commands:
+  key mint -name <name> -role <role> [-scopes ...] [-companies ...]    Mint an API key
+  key list                                                            List API keys
+  key revoke <id>                                                     Revoke an API key
+  user add -username <name> -role <role> [-scopes ...] [-companies ...] [-manager ...]
+                                                                      Add a dashboard user (password on stdin)
+  user assign <username> -role <role> [-companies ...] [-manager ...] Change a user's role and companies
+  user list                                                           List dashboard users
+  user remove <username>                                              Remove a dashboard user
+
+Roles: admin sees every company, rep sees its assigned companies, manager
+sees its own companies plus those of the reps that report to it.`)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "authctl: %v\n", err)
+	os.Exit(1)
+}
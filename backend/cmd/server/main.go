@@ -1,64 +1,451 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"usage-analytics-dashboard/internal/alerts"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/cache"
+	"usage-analytics-dashboard/internal/companies"
+	"usage-analytics-dashboard/internal/config"
+	"usage-analytics-dashboard/internal/graphql"
 	"usage-analytics-dashboard/internal/handlers"
+	"usage-analytics-dashboard/internal/health"
+	"usage-analytics-dashboard/internal/ingest"
+	"usage-analytics-dashboard/internal/metrics"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/openapi"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/ratelimit"
+	"usage-analytics-dashboard/internal/reports"
+	"usage-analytics-dashboard/internal/retention"
 	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/stream"
 	"usage-analytics-dashboard/internal/utils"
+	"usage-analytics-dashboard/internal/views"
+	"usage-analytics-dashboard/internal/webhooks"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Load settings from the config file, env variables and flags
+	cfg, options, err := config.Load(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if options.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+	if options.File != "" {
+		log.Printf("Loaded configuration from %s", options.File)
+	}
+	// Today's date and report schedules follow the configured timezone
+	time.Local = cfg.Location()
+	handlers.DefaultDateRange = cfg.Analytics.DefaultDateRange
+	port := strconv.Itoa(cfg.Server.Port)
 
-	// Initialize CSV parser
-	csvParser := utils.NewCSVParser("./data/assembly-takehome2.csv")
+	// Serve health, readiness and metrics while the dataset loads; other routes answer 503 until then
+	readiness := health.NewReadiness()
+	dataset := health.NewStatus(errors.New("events are still loading"))
+	readiness.Add("dataset", dataset.Err)
+	metricsRegistry := metrics.NewRegistry()
+	metrics.RegisterRuntime(metricsRegistry)
+	httpMetrics := metrics.NewHTTPMetrics(metricsRegistry)
+	healthHandler := handlers.NewHealthHandler(readiness)
 
-	// Parse CSV events
-	events, err := csvParser.ParseEvents()
+	startup := gin.New()
+	startup.Use(gin.Logger(), gin.Recovery(), httpMetrics.Middleware())
+	registerProbes(startup, healthHandler, metricsRegistry)
+	startup.NoRoute(healthHandler.Starting)
+
+	app := &swapHandler{}
+	app.Set(startup)
+	listener, err := net.Listen("tcp", ":"+port)
 	if err != nil {
-		log.Fatalf("Failed to parse CSV events: %v", err)
+		log.Fatalf("Failed to start server: %v", err)
+	}
+	server := &http.Server{
+		Handler:        http.MaxBytesHandler(app, int64(cfg.Server.MaxBodyMB)<<20),
+		ReadTimeout:    cfg.Server.ReadTimeout.Std(),
+		WriteTimeout:   cfg.Server.WriteTimeout.Std(),
+		IdleTimeout:    cfg.Server.IdleTimeout.Std(),
+		MaxHeaderBytes: cfg.Server.MaxHeaderKB << 10,
+	}
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.Serve(listener)
+	}()
+	log.Printf("Server starting on port %s...", port)
+
+	// Read the events file in its configured format, or the one its extension names
+	eventSource, err := utils.NewEventSource(cfg.Data.Events, cfg.Data.EventsFormat)
+	if err != nil {
+		log.Fatalf("Failed to open events file: %v", err)
 	}
 
-	log.Printf("Successfully loaded %d events from CSV", len(events))
+	events, err := eventSource.ParseEvents()
+	if err != nil {
+		log.Fatalf("Failed to parse events: %v", err)
+	}
+
+	log.Printf("Successfully loaded %d events from %s", len(events), cfg.Data.Events)
+
+	// Add events ingested through the API before the last shutdown
+	ingestJournal, journaled, err := ingest.OpenJournal(cfg.Data.Ingested)
+	if err != nil {
+		log.Fatalf("Failed to load ingest journal: %v", err)
+	}
+	if len(journaled) > 0 {
+		events = ingest.Merge(events, journaled)
+		log.Printf("Restored %d ingested events from %s", len(journaled), cfg.Data.Ingested)
+	}
+	datasetLoadedAt := time.Now()
+
+	// Background work stops when the server shuts down
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Initialize email pseudonymization; the key keeps pseudonyms stable across restarts
+	privacyKey, err := privacy.LoadOrCreateKey(cfg.Data.PrivacyKey)
+	if err != nil {
+		log.Fatalf("Failed to load privacy key: %v", err)
+	}
+	privacyPolicy, err := privacy.ParsePolicy(cfg.Privacy.Policy)
+	if err != nil {
+		log.Fatalf("Invalid privacy policy: %v", err)
+	}
+
+	// Initialize the company registry of canonical names and account metadata
+	companyRegistry, err := companies.NewRegistry(cfg.Data.Companies)
+	if err != nil {
+		log.Fatalf("Failed to load company registry: %v", err)
+	}
 
 	// Initialize analytics service
-	analyticsService := services.NewAnalyticsService(events)
+	redactor := privacy.NewRedactor(privacyKey)
+	analyticsService := services.NewAnalyticsService(events, redactor, companyRegistry)
+
+	// Initialize the audit log of API requests and admin actions
+	auditLog, err := audit.Open(cfg.Audit.Log, int64(cfg.Audit.MaxSizeMB)<<20, cfg.Audit.MaxFiles)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+
+	// Initialize data retention; previously purged events are removed before anything else reads them
+	retentionManager, err := retention.NewManager(analyticsService, cfg.Data.Retention, models.RetentionPolicy{
+		RawEvents:       cfg.Retention.RawEvents,
+		DailyAggregates: cfg.Retention.DailyAggregates,
+		MaxPurgePercent: cfg.Retention.MaxPurgePercent,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize retention: %v", err)
+	}
+	retentionManager.Subscribe(func(record models.PurgeRecord) {
+		auditLog.Record(models.AuditEntry{
+			Kind:      audit.KindAdmin,
+			Subject:   record.RequestedBy,
+			Action:    "purge." + record.Kind,
+			Params:    map[string]string{"purgeId": record.ID, "subject": record.Subject},
+			Companies: record.Companies,
+			Details:   fmt.Sprintf("removed %d events", record.EventsRemoved),
+		})
+	})
+
+	// Initialize saved views
+	viewStore, err := views.NewStore(cfg.Data.Views)
+	if err != nil {
+		log.Fatalf("Failed to load saved views: %v", err)
+	}
+
+	// Initialize scheduled reports
+	reportDefinitions, err := reports.LoadDefinitions(cfg.Data.Reports)
+	if err != nil {
+		log.Fatalf("Failed to load report definitions: %v", err)
+	}
+	reportScheduler, err := reports.NewScheduler(analyticsService, viewStore, cfg.Data.ReportsDir, reportDefinitions)
+	if err != nil {
+		log.Fatalf("Failed to initialize report scheduler: %v", err)
+	}
+	reportScheduler.Start(background)
+
+	// Initialize alerting
+	alertEngine, err := alerts.NewEngine(analyticsService, cfg.Data.Alerts)
+	if err != nil {
+		log.Fatalf("Failed to load alert rules: %v", err)
+	}
+	alertEngine.Start(background, time.Minute)
+
+	// Initialize outbound webhooks, driven by newly ingested events
+	webhookManager, err := webhooks.NewManager(analyticsService, cfg.Data.Webhooks)
+	if err != nil {
+		log.Fatalf("Failed to load webhooks: %v", err)
+	}
+	analyticsService.Subscribe(webhookManager.HandleIngest)
+	analyticsService.SubscribePurge(webhookManager.HandlePurge)
+	webhookManager.Start(background)
+
+	// Keep ingested events across restarts; purged ones are dropped from the journal too
+	// The ingest route flushes before it responds; the interval retries failed flushes
+	analyticsService.Subscribe(ingestJournal.HandleIngest)
+	analyticsService.SubscribePurge(ingestJournal.HandlePurge)
+	ingestJournal.Start(background, 5*time.Second)
+
+	// Wake live analytics streams as events arrive or are purged
+	streamHub := stream.NewHub(1000)
+	analyticsService.Subscribe(streamHub.HandleIngest)
+	analyticsService.SubscribePurge(streamHub.HandlePurge)
+
+	// Cache encoded analytics responses until the data behind them changes
+	responseCache := cache.NewLRU[cache.Response](cfg.Cache.Size, cfg.Cache.TTL.Std())
+	analyticsService.Subscribe(func([]models.UsageEvent) { responseCache.Invalidate() })
+	analyticsService.SubscribePurge(func([]models.UsageEvent) { responseCache.Invalidate() })
+	companyRegistry.Subscribe(func(models.CompanyProfile) { responseCache.Invalidate() })
+
+	// Apply retention once the purge subscribers are in place
+	retentionManager.Start(background, time.Hour)
+
+	// Initialize authentication
+	credentialStore, err := auth.NewStore(cfg.Data.Credentials)
+	if err != nil {
+		log.Fatalf("Failed to load credentials: %v", err)
+	}
+	credentialStore.SubscribeReload(func(keys, users int) {
+		auditLog.Record(models.AuditEntry{
+			Kind:    audit.KindAdmin,
+			Subject: "system",
+			Action:  "auth.reload",
+			Details: fmt.Sprintf("loaded %d keys and %d users", keys, users),
+		})
+	})
+	authenticator := auth.NewAuthenticator(credentialStore, auth.Options{
+		SessionSecret: cfg.Auth.SessionSecret,
+		SessionTTL:    cfg.Auth.SessionTTL.Std(),
+		Disabled:      cfg.Auth.Disabled,
+		PrivacyPolicy: privacyPolicy,
+	})
+	if cfg.Auth.Disabled {
+		log.Printf("WARNING: authentication is disabled, every request has admin access")
+	}
 
 	// Initialize HTTP handler
-	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService, viewStore, responseCache)
+	eventsHandler := handlers.NewEventsHandler(analyticsService, ingestJournal)
+	exportHandler := handlers.NewExportHandler(analyticsService)
+	reportsHandler := handlers.NewReportsHandler(reportScheduler)
+	viewsHandler := handlers.NewViewsHandler(analyticsService, viewStore)
+	alertsHandler := handlers.NewAlertsHandler(analyticsService, alertEngine)
+	webhooksHandler := handlers.NewWebhooksHandler(webhookManager)
+	authHandler := handlers.NewAuthHandler(authenticator)
+	retentionHandler := handlers.NewRetentionHandler(retentionManager)
+	auditHandler := handlers.NewAuditHandler(auditLog)
+	companiesHandler := handlers.NewCompaniesHandler(analyticsService, companyRegistry)
+	streamHandler := handlers.NewStreamHandler(analyticsHandler, streamHub, cfg.Stream.Heartbeat.Std())
+	server.RegisterOnShutdown(streamHandler.Close)
+	graphqlHandler, err := handlers.NewGraphQLHandler(analyticsService, graphql.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	})
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
 
 	// Setup Gin router
 	router := gin.Default()
+	router.Use(httpMetrics.Middleware())
+	// Client addresses come from X-Forwarded-For only when sent by a trusted proxy
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	rateLimit := ratelimit.NewLimiter(cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst).Middleware(rateLimitKey)
 
 	// Add CORS middleware
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173", "http://127.0.0.1:3000", "http://127.0.0.1:5173"},
+		AllowOrigins:     cfg.CORS.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition"},
 		AllowCredentials: true,
 	}))
 
 	// Setup routes
-	api := router.Group("/api")
+	api := router.Group("/api", audit.Middleware(auditLog, redactor))
 	{
-		api.GET("/analytics", analyticsHandler.GetAnalytics)
+		api.POST("/auth/login", rateLimit, authHandler.Login)
+		api.POST("/auth/logout", rateLimit, authHandler.Logout)
 	}
 
-	// Health check endpoint
-	router.GET("/health", analyticsHandler.HealthCheck)
+	// Versioned routes are registered through the spec, which documents and validates them
+	spec := openapi.NewSpec(openapi.Info{
+		Title:       "Usage Analytics API",
+		Version:     "1.0.0",
+		Description: "Usage analytics over product events, grouped by company and user",
+	})
+	api.GET("/v1/openapi.json", spec.Serve)
 
-	// Start server
-	log.Printf("Server starting on port %s...", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Every other API route requires credentials
+	authenticated := api.Group("", authenticator.Middleware(), rateLimit)
+	authenticated.GET("/auth/me", authHandler.Me)
+
+	read := authenticated.Group("", auth.RequireScope(auth.ScopeAnalyticsRead), audit.MarkDataAccess())
+	{
+		read.GET("/analytics", analyticsHandler.GetAnalytics)
+		read.GET("/analytics/export", exportHandler.ExportAnalytics)
+		read.GET("/analytics/stream", streamHandler.StreamAnalytics)
+		read.GET("/events", eventsHandler.ListEvents)
+		read.GET("/events/search", eventsHandler.SearchEvents)
+		read.GET("/aggregates/daily", retentionHandler.GetDailyAggregates)
+		read.GET("/companies", companiesHandler.ListCompanies)
+		read.GET("/companies/:id", companiesHandler.GetCompany)
+		read.GET("/views", viewsHandler.ListViews)
+		read.POST("/views", viewsHandler.CreateView)
+		read.GET("/views/:id", viewsHandler.GetView)
+		read.PUT("/views/:id", viewsHandler.UpdateView)
+		read.DELETE("/views/:id", viewsHandler.DeleteView)
+
+		spec.Handle(read, http.MethodGet, "/v1/analytics", handlers.AnalyticsV1Operation, analyticsHandler.GetAnalyticsV1)
+		spec.Handle(read, http.MethodGet, "/v1/events", handlers.EventsV1Operation, eventsHandler.ListEvents)
+		spec.Handle(read, http.MethodGet, "/v1/events/search", handlers.SearchEventsV1Operation, eventsHandler.SearchEvents)
+		spec.Handle(read, http.MethodGet, "/v1/aggregates/daily", handlers.DailyAggregatesV1Operation, retentionHandler.GetDailyAggregates)
+		spec.Handle(read, http.MethodGet, "/v1/companies", handlers.ListCompaniesV1Operation, companiesHandler.ListCompanies)
+		spec.Handle(read, http.MethodGet, "/v1/companies/:id", handlers.GetCompanyV1Operation, companiesHandler.GetCompany)
+	}
+
+	// Reports and alert state span every company, so company-scoped roles cannot read them
+	allCompanies := read.Group("", auth.RequireAllCompanies())
+	{
+		allCompanies.GET("/reports", reportsHandler.ListReports)
+		allCompanies.GET("/reports/:name", reportsHandler.DownloadReport)
+		allCompanies.GET("/alerts", alertsHandler.GetAlerts)
+		allCompanies.GET("/alerts/history", alertsHandler.GetHistory)
+	}
+
+	ingestGroup := authenticated.Group("", auth.RequireScope(auth.ScopeEventsIngest))
+	{
+		ingestGroup.POST("/events/ingest", eventsHandler.IngestEvents)
+	}
+
+	// Admin routes are marked first so rejected attempts are audited as admin actions too
+	admin := authenticated.Group("", audit.MarkAdmin(), auth.RequireScope(auth.ScopeAdmin), auth.RequireAllCompanies())
+	{
+		admin.POST("/alerts/evaluate", alertsHandler.EvaluateAlerts)
+		admin.GET("/alerts/rules", alertsHandler.ListRules)
+		admin.POST("/alerts/rules", alertsHandler.CreateRule)
+		admin.GET("/alerts/rules/:id", alertsHandler.GetRule)
+		admin.PUT("/alerts/rules/:id", alertsHandler.UpdateRule)
+		admin.DELETE("/alerts/rules/:id", alertsHandler.DeleteRule)
+		admin.GET("/webhooks", webhooksHandler.ListEndpoints)
+		admin.POST("/webhooks", webhooksHandler.CreateEndpoint)
+		admin.GET("/webhooks/:id", webhooksHandler.GetEndpoint)
+		admin.PUT("/webhooks/:id", webhooksHandler.UpdateEndpoint)
+		admin.DELETE("/webhooks/:id", webhooksHandler.DeleteEndpoint)
+		admin.GET("/webhooks/:id/deliveries", webhooksHandler.GetDeliveries)
+		admin.POST("/webhooks/:id/test", webhooksHandler.SendTest)
+		admin.GET("/retention", retentionHandler.GetStatus)
+		admin.POST("/retention/run", retentionHandler.RunRetention)
+		admin.GET("/retention/purges", retentionHandler.ListPurges)
+		admin.DELETE("/users/:email/data", retentionHandler.EraseUser)
+		admin.GET("/audit", auditHandler.QueryLog)
+		admin.GET("/cache", analyticsHandler.GetCacheStats)
+		admin.PUT("/companies/:id", companiesHandler.UpdateCompany)
+
+		spec.Handle(admin, http.MethodPut, "/v1/companies/:id", handlers.UpdateCompanyV1Operation, companiesHandler.UpdateCompany)
 	}
+
+	// GraphQL shares the API's auditing and credentials but sits outside /api
+	graphqlRoutes := router.Group("/graphql", audit.Middleware(auditLog, redactor), authenticator.Middleware(), rateLimit,
+		auth.RequireScope(auth.ScopeAnalyticsRead), audit.MarkDataAccess())
+	{
+		graphqlRoutes.GET("", graphqlHandler.Query)
+		graphqlRoutes.POST("", graphqlHandler.Query)
+		graphqlRoutes.GET("/schema", graphqlHandler.Schema)
+	}
+
+	// Health, readiness and metrics endpoints
+	registerProbes(router, healthHandler, metricsRegistry)
+	registerDataMetrics(metricsRegistry, analyticsService, responseCache, readiness, datasetLoadedAt)
+	readiness.Add("credentials", credentialStore.Check)
+
+	// Serve the API, then report ready
+	app.Set(router)
+	dataset.Set(nil)
+	log.Printf("Server ready on port %s", port)
+
+	// Serve until the server fails or is asked to stop
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serverErrors:
+		log.Fatalf("Server failed: %v", err)
+	case received := <-signals:
+		log.Printf("Received %s, shutting down", received)
+	}
+
+	// Finish in-flight requests, then the work they queued, within one deadline
+	drain, cancelDrain := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Std())
+	defer cancelDrain()
+	if err := server.Shutdown(drain); err != nil {
+		log.Printf("Failed to drain requests: %v", err)
+	}
+	if err := webhookManager.Drain(drain); err != nil {
+		log.Printf("Failed to drain webhook deliveries: %v", err)
+	}
+	stopBackground()
+
+	// Events whose flush failed are still buffered; try once more before exiting
+	if err := ingestJournal.Flush(); err != nil {
+		log.Printf("Failed to flush ingest journal: %v", err)
+	}
+	if err := auditLog.Close(); err != nil {
+		log.Printf("Failed to close audit log: %v", err)
+	}
+	log.Printf("Server stopped")
+}
+
+// rateLimitKey names the client a request counts against: its key or user once authenticated, otherwise its address
+func rateLimitKey(c *gin.Context) string {
+	if principal, ok := auth.PrincipalFrom(c); ok && principal.Method != auth.MethodDisabled {
+		return principal.Method + ":" + principal.Subject
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// registerProbes adds the routes served both while starting and once running
+func registerProbes(router *gin.Engine, healthHandler *handlers.HealthHandler, registry *metrics.Registry) {
+	router.GET("/health", healthHandler.HealthCheck)
+	router.GET("/ready", healthHandler.Ready)
+	router.GET("/metrics", metrics.Handler(registry))
+}
+
+// swapHandler serves requests with a router that can be replaced while serving
+type swapHandler struct {
+	current atomic.Pointer[gin.Engine]
+}
+
+// Set replaces the router used for new requests
+func (h *swapHandler) Set(router *gin.Engine) {
+	h.current.Store(router)
+}
+
+func (h *swapHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.current.Load().ServeHTTP(w, r)
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/cache"
+	"usage-analytics-dashboard/internal/health"
+	"usage-analytics-dashboard/internal/metrics"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// registerDataMetrics registers the dataset, ingest, cache and readiness metrics
+// Each source is read once per scrape so the values of one scrape are consistent
+func registerDataMetrics(registry *metrics.Registry, analyticsService *services.AnalyticsService,
+	responseCache *cache.LRU[cache.Response], readiness *health.Readiness, loadedAt time.Time) {
+	var (
+		mu         sync.Mutex
+		dataset    models.DatasetStats
+		cacheStats cache.Stats
+		ready      bool
+	)
+	registry.OnScrape(func() {
+		datasetStats := analyticsService.DatasetStats()
+		stats := responseCache.Stats()
+		report := readiness.Check()
+
+		mu.Lock()
+		defer mu.Unlock()
+		dataset, cacheStats, ready = datasetStats, stats, report.Ready
+	})
+	read := func(value func() float64) func() float64 {
+		return func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+			return value()
+		}
+	}
+
+	registry.GaugeFunc("usage_ready", "1 when every readiness check passes", read(func() float64 {
+		if ready {
+			return 1
+		}
+		return 0
+	}))
+
+	registry.GaugeFunc("usage_events_loaded", "Events held in memory", read(func() float64 {
+		return float64(dataset.Events)
+	}))
+	registry.GaugeFunc("usage_companies", "Companies with at least one event", read(func() float64 {
+		return float64(dataset.Companies)
+	}))
+	registry.GaugeFunc("usage_dataset_loaded_timestamp_seconds", "Time the dataset was loaded since the Unix epoch", func() float64 {
+		return float64(loadedAt.Unix())
+	})
+	registry.GaugeFunc("usage_dataset_newest_event_timestamp_seconds", "Creation time of the newest event since the Unix epoch", read(func() float64 {
+		if dataset.NewestEvent.IsZero() {
+			return 0
+		}
+		return float64(dataset.NewestEvent.Unix())
+	}))
+	registry.GaugeFunc("usage_dataset_age_seconds", "Seconds since the newest event was created", read(func() float64 {
+		if dataset.NewestEvent.IsZero() {
+			return 0
+		}
+		return time.Since(dataset.NewestEvent).Seconds()
+	}))
+
+	registry.CounterFunc("usage_ingest_batches_total", "Ingest requests processed", read(func() float64 {
+		return float64(dataset.Ingest.Batches)
+	}))
+	registry.CounterFunc("usage_ingest_accepted_events_total", "Events accepted by ingest", read(func() float64 {
+		return float64(dataset.Ingest.Accepted)
+	}))
+	registry.CounterFunc("usage_ingest_invalid_events_total", "Events rejected by ingest as invalid", read(func() float64 {
+		return float64(dataset.Ingest.Invalid)
+	}))
+	registry.CounterFunc("usage_ingest_duplicate_events_total", "Events rejected by ingest as duplicates", read(func() float64 {
+		return float64(dataset.Ingest.Duplicate)
+	}))
+
+	registry.GaugeFunc("usage_analytics_cache_entries", "Analytics responses cached", read(func() float64 {
+		return float64(cacheStats.Entries)
+	}))
+	registry.GaugeFunc("usage_analytics_cache_capacity", "Most analytics responses cached", read(func() float64 {
+		return float64(cacheStats.Capacity)
+	}))
+	registry.CounterFunc("usage_analytics_cache_hits_total", "Analytics requests answered from the cache", read(func() float64 {
+		return float64(cacheStats.Hits)
+	}))
+	registry.CounterFunc("usage_analytics_cache_misses_total", "Analytics requests computed", read(func() float64 {
+		return float64(cacheStats.Misses)
+	}))
+	registry.CounterFunc("usage_analytics_cache_evictions_total", "Cached responses evicted to stay within capacity", read(func() float64 {
+		return float64(cacheStats.Evictions)
+	}))
+	registry.CounterFunc("usage_analytics_cache_expirations_total", "Cached responses dropped after their TTL", read(func() float64 {
+		return float64(cacheStats.Expirations)
+	}))
+	registry.CounterFunc("usage_analytics_cache_invalidations_total", "Times the cache was cleared by data changes", read(func() float64 {
+		return float64(cacheStats.Invalidations)
+	}))
+}
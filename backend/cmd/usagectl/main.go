@@ -0,0 +1,345 @@
+// Command usagectl runs analytics queries offline, against the same data the server loads.
+//
+// Usage:
+//
+//	usagectl [-config config.yaml] [-events ./data/export.parquet] summary -company <id|name> -from 2025-05-01
+//	usagectl top-users [-limit 10] [-format table|csv|json]
+//	usagectl trends -granularity week -range 90 -format csv
+//	usagectl validate ./data/export.csv [-strict] [-format json]
+//
+// Data sources, the default date range and the timezone come from the server's
+// config file and env variables. Events ingested through the API are included, and
+// events purged by retention or erasure are left out, as on the server.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/companies"
+	"usage-analytics-dashboard/internal/config"
+	"usage-analytics-dashboard/internal/ingest"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/retention"
+	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// dateLayout is the format of -from and -to
+const dateLayout = "2006-01-02"
+
+// maxTopUsers is how many users the analytics service ranks
+const maxTopUsers = 10
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "server config file to read data sources from")
+	eventsFile := flag.String("events", "", "events file, overriding the config")
+	eventsFormat := flag.String("events-format", "", "format of the events file: "+strings.Join(utils.SourceFormats, ", ")+"; empty picks it from the extension")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	// validate checks a standalone file, so a missing or broken server config must not stop it
+	if flag.Arg(0) == "validate" {
+		err := validate(flag.Args()[1:], *eventsFormat, os.Stdout)
+		// The report already explains the failure
+		if errors.Is(err, errFileRejected) {
+			os.Exit(1)
+		}
+		if err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	cfg, err := config.FromFile(*configFile)
+	if err != nil {
+		fatal(err)
+	}
+	// A file given here is read in its own format, not the configured file's
+	if *eventsFile != "" {
+		cfg.Data.Events = *eventsFile
+		cfg.Data.EventsFormat = ""
+	}
+	if *eventsFormat != "" {
+		cfg.Data.EventsFormat = *eventsFormat
+	}
+	// Today's date, which open date ranges end on, follows the server's timezone
+	time.Local = cfg.Location()
+
+	args := flag.Args()
+	switch args[0] {
+	case "summary":
+		err = summary(cfg, args[1:])
+	case "top-users":
+		err = topUsers(cfg, args[1:])
+	case "trends":
+		err = trends(cfg, args[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// summaryResult is the JSON form of a summary
+type summaryResult struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	models.DashboardSummary
+}
+
+// summary prints event and company totals and the busiest day
+func summary(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("summary", flag.ExitOnError)
+	query := newQueryFlags(flags, cfg)
+	flags.Parse(args)
+
+	service, params, err := query.load(cfg)
+	if err != nil {
+		return err
+	}
+
+	snapshot := service.GenerateSnapshot(params)
+	result := summaryResult{DashboardSummary: snapshot.Summary}
+	if days := snapshot.Trends.Days; len(days) > 0 {
+		result.From, result.To = days[0], days[len(days)-1]
+	}
+
+	return output{
+		columns: []string{"from", "to", "total_events", "companies", "peak_usage_day"},
+		rows: [][]string{{
+			result.From,
+			result.To,
+			fmt.Sprint(result.TotalEvents),
+			fmt.Sprint(result.TotalCompanies),
+			result.PeakUsageDay,
+		}},
+		value: result,
+	}.write(os.Stdout, *query.format)
+}
+
+// topUsers prints the most active users
+func topUsers(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("top-users", flag.ExitOnError)
+	query := newQueryFlags(flags, cfg)
+	limit := flags.Int("limit", maxTopUsers, fmt.Sprintf("users to list, at most %d", maxTopUsers))
+	flags.Parse(args)
+
+	if *limit <= 0 || *limit > maxTopUsers {
+		return fmt.Errorf("-limit must be between 1 and %d", maxTopUsers)
+	}
+	service, params, err := query.load(cfg)
+	if err != nil {
+		return err
+	}
+
+	users := service.GenerateAnalyticsV1(params).TopUsers
+	if len(users) > *limit {
+		users = users[:*limit]
+	}
+
+	rows := make([][]string, 0, len(users))
+	for i, user := range users {
+		rows = append(rows, []string{fmt.Sprint(i + 1), user.Email, user.CompanyName, fmt.Sprint(user.EventCount)})
+	}
+	return output{
+		columns: []string{"rank", "email", "company", "events"},
+		rows:    rows,
+		value:   users,
+	}.write(os.Stdout, *query.format)
+}
+
+// trends prints event counts per day, week or month, with a column per company
+func trends(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("trends", flag.ExitOnError)
+	query := newQueryFlags(flags, cfg)
+	granularity := flags.String("granularity", services.GranularityDay, "bucket size: "+strings.Join(services.Granularities, ", "))
+	flags.Parse(args)
+
+	if !slices.Contains(services.Granularities, *granularity) {
+		return fmt.Errorf("-granularity must be one of %s", strings.Join(services.Granularities, ", "))
+	}
+	service, params, err := query.load(cfg)
+	if err != nil {
+		return err
+	}
+
+	resampled, err := services.ResampleTrends(service.GenerateSnapshot(params).Trends, *granularity)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"date", "total"}
+	for _, companyID := range resampled.Order {
+		columns = append(columns, resampled.Series[companyID].Label)
+	}
+	rows := make([][]string, 0, len(resampled.Days))
+	for i, day := range resampled.Days {
+		row := []string{day, fmt.Sprint(resampled.Totals[i].Events)}
+		for _, companyID := range resampled.Order {
+			row = append(row, fmt.Sprint(resampled.Series[companyID].Points[i].Events))
+		}
+		rows = append(rows, row)
+	}
+	return output{columns: columns, rows: rows, value: resampled}.write(os.Stdout, *query.format)
+}
+
+// queryFlags are the filters and output format shared by every command
+type queryFlags struct {
+	company   *string
+	from      *string
+	to        *string
+	dateRange *int
+	search    *string
+	plan      *string
+	region    *string
+	csmOwner  *string
+	tag       *string
+	format    *string
+}
+
+func newQueryFlags(flags *flag.FlagSet, cfg config.Config) *queryFlags {
+	return &queryFlags{
+		company:   flags.String("company", "", "company ID, name or slug"),
+		from:      flags.String("from", "", "first day, YYYY-MM-DD"),
+		to:        flags.String("to", "", "last day, YYYY-MM-DD; defaults to today when -from is set"),
+		dateRange: flags.Int("range", cfg.Analytics.DefaultDateRange, "days up to today, when -from is not set"),
+		search:    flags.String("search", "", "only count events matching this text"),
+		plan:      flags.String("plan", "", "only companies on this plan"),
+		region:    flags.String("region", "", "only companies in this region"),
+		csmOwner:  flags.String("csm-owner", "", "only companies owned by this CSM"),
+		tag:       flags.String("tag", "", "only companies with this tag"),
+		format:    flags.String("format", "table", "output format: "+strings.Join(formats, ", ")),
+	}
+}
+
+// load checks the flags, loads the data and returns the query they describe
+func (q *queryFlags) load(cfg config.Config) (*services.AnalyticsService, models.QueryParams, error) {
+	params := models.QueryParams{
+		DateRange: *q.dateRange,
+		FromDate:  *q.from,
+		ToDate:    *q.to,
+		Search:    strings.TrimSpace(*q.search),
+		Plan:      *q.plan,
+		Region:    *q.region,
+		CSMOwner:  *q.csmOwner,
+		Tag:       *q.tag,
+	}
+	if !slices.Contains(formats, *q.format) {
+		return nil, params, fmt.Errorf("-format must be one of %s", strings.Join(formats, ", "))
+	}
+	if params.DateRange <= 0 || params.DateRange > 365 {
+		return nil, params, errors.New("-range must be between 1 and 365")
+	}
+	var from, to time.Time
+	var err error
+	if params.FromDate != "" {
+		if from, err = time.Parse(dateLayout, params.FromDate); err != nil {
+			return nil, params, errors.New("-from must be a date like 2025-05-01")
+		}
+	}
+	if params.ToDate != "" {
+		if params.FromDate == "" {
+			return nil, params, errors.New("-to needs -from")
+		}
+		if to, err = time.Parse(dateLayout, params.ToDate); err != nil {
+			return nil, params, errors.New("-to must be a date like 2025-05-31")
+		}
+		if to.Before(from) {
+			return nil, params, errors.New("-to must not be before -from")
+		}
+	}
+
+	service, err := loadService(cfg)
+	if err != nil {
+		return nil, params, err
+	}
+	if *q.company != "" {
+		if params.CompanyID, err = resolveCompany(service, *q.company); err != nil {
+			return nil, params, err
+		}
+	}
+	return service, params, nil
+}
+
+// loadService loads events the way the server does: the events file, then the events ingested
+// through the API, without those purged by retention or erasure
+func loadService(cfg config.Config) (*services.AnalyticsService, error) {
+	source, err := utils.NewEventSource(cfg.Data.Events, cfg.Data.EventsFormat)
+	if err != nil {
+		return nil, err
+	}
+	events, err := source.ParseEvents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	_, journaled, err := ingest.OpenJournal(cfg.Data.Ingested)
+	if err != nil {
+		return nil, err
+	}
+	events = ingest.Merge(events, journaled)
+
+	registry, err := companies.NewRegistry(cfg.Data.Companies)
+	if err != nil {
+		return nil, err
+	}
+	// Emails are shown as-is; this tool reads the raw data files anyway
+	service := services.NewAnalyticsService(events, nil, registry)
+
+	// Loading the retention state removes purged events again; only the server applies the policy
+	if _, err := retention.NewManager(service, cfg.Data.Retention, models.RetentionPolicy{}); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// resolveCompany finds a company by ID, or by name or slug ignoring case
+func resolveCompany(service *services.AnalyticsService, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	for _, profile := range service.CompanyProfiles(nil) {
+		if profile.ID == value || strings.EqualFold(profile.Name, value) || (profile.Slug != "" && strings.EqualFold(profile.Slug, value)) {
+			return profile.ID, nil
+		}
+	}
+	return "", fmt.Errorf("unknown company %q", value)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: usagectl [-config path] [-events path] [-events-format csv|ndjson|parquet] <command> [flags]
+
+Commands:
+  summary                                   Total events, companies and the busiest day
+  top-users [-limit n]                      Most active users, at most 10
+  trends [-granularity day|week|month]      Events per day, week or month, with a column per company
+  validate <file> [-strict] [-max-issues n] Check an events file without loading it; exits 1 if
+                                            rows would be lost, or with -strict on any warning
+
+The query commands take these flags:
+  -company <id|name>    Only this company
+  -from YYYY-MM-DD      First day; -to sets the last, otherwise it is today
+  -range <days>         Days up to today when -from is not set (default from the config)
+  -search <text>        Only events matching this text
+  -plan, -region, -csm-owner, -tag
+                        Only companies with this profile value
+  -format table|csv|json
+
+Data sources are read from the server's config file (-config or CONFIG_FILE)
+and env variables, so the results match what the dashboard shows.`)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "usagectl: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatJSON  = "json"
+)
+
+var formats = []string{formatTable, formatCSV, formatJSON}
+
+// output is a command's result: rows for table and CSV output, and a value for JSON
+// JSON keeps the API's field names and number types, so scripts can share parsers with it
+type output struct {
+	columns []string
+	rows    [][]string
+	value   any
+}
+
+// write prints the result in format
+func (o output) write(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(o.value)
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		writer.Write(o.columns)
+		writer.WriteAll(o.rows)
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(o.columns, "\t")))
+		for _, row := range o.rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+}
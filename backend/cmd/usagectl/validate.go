@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// errFileRejected is returned by validate after the report says why the file fails
+var errFileRejected = errors.New("file rejected")
+
+// validate dry-runs the events parser over a file and writes what loading it would do to w
+// It returns errFileRejected when the file would fail to load or lose rows, and with -strict on warnings too
+// The file is read in -events-format, or the format its extension names
+func validate(args []string, eventsFormat string, w io.Writer) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	format := flags.String("format", formatTable, "output format: table or json")
+	strict := flags.Bool("strict", false, "also fail on warnings")
+	maxIssues := flags.Int("max-issues", 20, "rows listed per problem in table output; 0 lists all")
+	flags.StringVar(&eventsFormat, "events-format", eventsFormat, "format of the file: "+strings.Join(utils.SourceFormats, ", ")+"; empty picks it from the extension")
+
+	// Flags may come before or after the file
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+	flags.Parse(args)
+	if file == "" && flags.NArg() > 0 {
+		file = flags.Arg(0)
+	}
+	if file == "" {
+		fmt.Fprintln(os.Stderr, "Usage: usagectl validate <file> [flags]")
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+	if *format != formatTable && *format != formatJSON {
+		return errors.New("-format must be table or json")
+	}
+
+	source, err := utils.NewEventSource(file, eventsFormat)
+	if err != nil {
+		return err
+	}
+	report, err := source.DryRun()
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else if err := writeReport(w, report, *maxIssues); err != nil {
+		return err
+	}
+
+	if report.Failed() || (*strict && report.Warned()) {
+		return errFileRejected
+	}
+	return nil
+}
+
+// writeReport prints a report section by section, listing at most limit rows per problem
+func writeReport(w io.Writer, report utils.SourceReport, limit int) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "File:\t%s\n", report.File)
+	fmt.Fprintf(writer, "Columns:\t%s\n", strings.Join(report.Columns, ", "))
+	if len(report.MissingColumns) > 0 {
+		fmt.Fprintf(writer, "Missing columns:\t%s\n", strings.Join(report.MissingColumns, ", "))
+	}
+	if len(report.UnknownColumns) > 0 {
+		fmt.Fprintf(writer, "Ignored columns:\t%s\n", strings.Join(report.UnknownColumns, ", "))
+	}
+	fmt.Fprintf(writer, "Rows:\t%d (%d accepted, %d rejected)\n", report.Rows, report.Accepted, len(report.Rejected))
+	for _, column := range []struct {
+		name  string
+		value *utils.TimeRange
+	}{
+		{"created_at", report.CreatedAt},
+		{"updated_at", report.UpdatedAt},
+		{"original_timestamp", report.OriginalTimestamp},
+	} {
+		if column.value != nil {
+			fmt.Fprintf(writer, "%s:\t%s to %s\n", column.name, column.value.First.Format(time.RFC3339), column.value.Last.Format(time.RFC3339))
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Companies) > 0 {
+		fmt.Fprintf(w, "\nCompanies (%d):\n", len(report.Companies))
+		writer = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "  COMPANY_ID\tNAME\tROWS")
+		for _, company := range report.Companies {
+			fmt.Fprintf(writer, "  %s\t%s\t%d\n", company.CompanyID, company.Name, company.Rows)
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := writeIssues(w, "Rejected rows", report.Rejected, limit); err != nil {
+		return err
+	}
+	if len(report.DuplicateIDs) > 0 {
+		issues := make([]utils.RowIssue, 0, len(report.DuplicateIDs))
+		for _, duplicate := range report.DuplicateIDs {
+			lines := make([]string, 0, len(duplicate.Lines))
+			for _, line := range duplicate.Lines {
+				lines = append(lines, fmt.Sprint(line))
+			}
+			issues = append(issues, utils.RowIssue{
+				Line:   duplicate.Lines[0],
+				ID:     duplicate.ID,
+				Reason: "also on line " + strings.Join(lines[1:], ", "),
+			})
+		}
+		if err := writeIssues(w, "Duplicate IDs", issues, limit); err != nil {
+			return err
+		}
+	}
+	if err := writeIssues(w, "Content without a user or company", report.UnparseableContent, limit); err != nil {
+		return err
+	}
+	if err := writeIssues(w, "updated_at before created_at", report.UpdatedBeforeCreated, limit); err != nil {
+		return err
+	}
+
+	switch {
+	case report.Failed():
+		fmt.Fprintln(w, "\nResult: FAIL")
+	case report.Warned():
+		fmt.Fprintln(w, "\nResult: OK with warnings")
+	default:
+		fmt.Fprintln(w, "\nResult: OK")
+	}
+	return nil
+}
+
+// writeIssues prints a titled list of row problems, if there are any
+func writeIssues(w io.Writer, title string, issues []utils.RowIssue, limit int) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\n%s (%d):\n", title, len(issues))
+	shown := issues
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "  LINE\tID\tREASON")
+	for _, issue := range shown {
+		fmt.Fprintf(writer, "  %d\t%s\t%s\n", issue.Line, issue.ID, issue.Reason)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if hidden := len(issues) - len(shown); hidden > 0 {
+		fmt.Fprintf(w, "  ... and %d more\n", hidden)
+	}
+	return nil
+}
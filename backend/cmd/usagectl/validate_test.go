@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	validExport = "id,created_at,company_id,type,content\n" +
+		"1,2025-05-31 19:17:29+00,company-1,Action,Login - Sample Company ann@sample.com /home\n"
+	// The second row only warns, since its content names no user
+	warningExport = validExport + "2,2025-05-31 19:17:30+00,company-1,Action,Login\n"
+	// The second row is rejected for its timestamp
+	failingExport = validExport + "2,yesterday,company-1,Action,Login - Sample Company ann@sample.com /home\n"
+)
+
+// TestMain runs the command instead of the tests when the test binary is started by runUsagectl
+func TestMain(m *testing.M) {
+	if args := os.Getenv("USAGECTL_ARGS"); args != "" {
+		os.Args = append([]string{"usagectl"}, strings.Split(args, " ")...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runUsagectl runs the command in a new process with configFile as CONFIG_FILE, and returns its exit code and output
+func runUsagectl(t *testing.T, configFile string, args ...string) (int, string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "USAGECTL_ARGS="+strings.Join(args, " "), "CONFIG_FILE="+configFile)
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), string(output)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0, string(output)
+}
+
+func TestValidateExitCode(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"valid": validExport, "warning": warningExport, "failing": failingExport}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name+".csv"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		result string
+	}{
+		{"valid", []string{"valid.csv"}, 0, "Result: OK"},
+		{"warnings", []string{"warning.csv"}, 0, "Result: OK with warnings"},
+		{"warnings with -strict", []string{"warning.csv", "-strict"}, 1, "Result: OK with warnings"},
+		{"rejected row", []string{"failing.csv"}, 1, "Result: FAIL"},
+		{"rejected row as JSON", []string{"failing.csv", "-format", "json"}, 1, `"rejected": [`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"validate", filepath.Join(dir, tt.args[0])}, tt.args[1:]...)
+			code, output := runUsagectl(t, "", args...)
+			if code != tt.code {
+				t.Errorf("exit code %d, want %d:\n%s", code, tt.code, output)
+			}
+			if !strings.Contains(output, tt.result) {
+				t.Errorf("output does not contain %q:\n%s", tt.result, output)
+			}
+		})
+	}
+}
+
+func TestValidateIgnoresServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "valid.csv")
+	if err := os.WriteFile(file, []byte(validExport), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(broken, []byte("server:\n  port: -1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, configFile := range map[string]string{"missing": filepath.Join(dir, "missing.yaml"), "invalid": broken} {
+		t.Run(name, func(t *testing.T) {
+			if code, output := runUsagectl(t, configFile, "validate", file); code != 0 {
+				t.Errorf("exit code %d, want 0:\n%s", code, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Command webhook-receiver is a stub endpoint for testing alert and webhook delivery locally.
+// It logs every request it receives and replies with a configurable status code.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"usage-analytics-dashboard/internal/webhooks"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	status := flag.Int("status", http.StatusOK, "status code to reply with, e.g. 500 to test retries")
+	secret := flag.String("secret", "", "webhook signing secret; when set, signatures are verified")
+	flag.Parse()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("%s %s", r.Method, r.URL.RequestURI())
+
+		// Print headers in a stable order
+		names := make([]string, 0, len(r.Header))
+		for name := range r.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			log.Printf("  %s: %s", name, r.Header.Get(name))
+		}
+
+		// Pretty-print JSON bodies, fall back to raw text
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, body, "  ", "  ") == nil {
+			log.Printf("  %s", pretty.String())
+		} else if len(body) > 0 {
+			log.Printf("  %s", body)
+		}
+
+		// Check the HMAC signature of usage webhooks
+		if *secret != "" {
+			timestamp, _ := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+			if webhooks.Verify(*secret, timestamp, body, r.Header.Get(webhooks.HeaderSignature)) {
+				log.Printf("  signature: valid")
+			} else {
+				log.Printf("  signature: INVALID")
+			}
+		}
+
+		w.WriteHeader(*status)
+	})
+
+	log.Printf("Webhook receiver listening on %s, replying %d", *addr, *status)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatalf("Failed to start webhook receiver: %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// maxHistory bounds the number of state transitions kept
+const maxHistory = 500
+
+// ErrRuleNotFound is returned when an alert rule does not exist
+var ErrRuleNotFound = errors.New("alert rule not found")
+
+// engineState is the on-disk representation of the engine
+type engineState struct {
+	Rules    []models.AlertRule   `json:"rules"`
+	Statuses []models.AlertStatus `json:"statuses"`
+	History  []models.AlertEvent  `json:"history"`
+}
+
+// Engine stores alert rules, evaluates them against the analytics service
+// and notifies channels when a rule starts or stops firing
+type Engine struct {
+	analyticsService *services.AnalyticsService
+	path             string
+
+	mu       sync.Mutex
+	rules    map[string]models.AlertRule
+	statuses map[string]models.AlertStatus
+	history  []models.AlertEvent
+}
+
+// NewEngine loads alert rules and state from path, starting empty if the file does not exist
+func NewEngine(analyticsService *services.AnalyticsService, path string) (*Engine, error) {
+	engine := &Engine{
+		analyticsService: analyticsService,
+		path:             path,
+		rules:            make(map[string]models.AlertRule),
+		statuses:         make(map[string]models.AlertStatus),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return engine, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alerts file: %w", err)
+	}
+
+	var state engineState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse alerts file: %w", err)
+	}
+	for _, rule := range state.Rules {
+		engine.rules[rule.ID] = rule
+	}
+	for _, status := range state.Statuses {
+		engine.statuses[status.RuleID] = status
+	}
+	engine.history = state.History
+
+	return engine, nil
+}
+
+// Start evaluates every rule on the given interval until ctx is cancelled
+func (e *Engine) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.Evaluate(ctx)
+			}
+		}
+	}()
+}
+
+// Evaluate checks every enabled rule, records state transitions and sends notifications
+// Rules are evaluated as of the day of the most recent event, so a static dataset
+// behaves the same as a live one
+func (e *Engine) Evaluate(ctx context.Context) []models.AlertStatus {
+	latest := e.analyticsService.LatestEventTime()
+	if latest.IsZero() {
+		return e.Statuses()
+	}
+	asOf := startOfDay(latest)
+	now := time.Now().UTC()
+
+	type delivery struct {
+		rule  models.AlertRule
+		event models.AlertEvent
+	}
+	var deliveries []delivery
+
+	e.mu.Lock()
+	for id, rule := range e.rules {
+		if rule.Disabled {
+			continue
+		}
+
+		result := evaluateRule(e.analyticsService, rule, asOf)
+		previous, known := e.statuses[id]
+
+		state := StateOK
+		if result.firing {
+			state = StateFiring
+		}
+
+		status := models.AlertStatus{
+			RuleID:        id,
+			RuleName:      rule.Name,
+			State:         state,
+			Value:         result.value,
+			Message:       result.message,
+			Since:         previous.Since,
+			LastEvaluated: now,
+		}
+
+		// Only transitions are recorded and delivered
+		changed := !known || previous.State != state
+		if changed {
+			status.Since = now
+		}
+		e.statuses[id] = status
+
+		if changed && (state == StateFiring || known) {
+			eventState := state
+			if state == StateOK {
+				eventState = StateResolved
+			}
+			event := models.AlertEvent{
+				RuleID:    id,
+				RuleName:  rule.Name,
+				CompanyID: rule.CompanyID,
+				State:     eventState,
+				Value:     result.value,
+				Message:   result.message,
+				AsOf:      asOf.Format("2006-01-02"),
+				At:        now,
+			}
+			e.appendHistoryLocked(event)
+			deliveries = append(deliveries, delivery{rule: rule, event: event})
+		}
+	}
+	if err := e.saveLocked(); err != nil {
+		log.Printf("Failed to save alert state: %v", err)
+	}
+	e.mu.Unlock()
+
+	// Deliver outside the lock so slow channels never block the API
+	for _, d := range deliveries {
+		e.deliver(ctx, d.rule, d.event)
+	}
+
+	return e.Statuses()
+}
+
+// deliver sends an event to each of the rule's channels
+func (e *Engine) deliver(ctx context.Context, rule models.AlertRule, event models.AlertEvent) {
+	log.Printf("Alert %q %s: %s", rule.Name, event.State, event.Message)
+
+	for _, channel := range rule.Channels {
+		notifier, err := NewNotifier(channel)
+		if err != nil {
+			log.Printf("Alert %q: invalid %s channel: %v", rule.Name, channel.Type, err)
+			continue
+		}
+		if err := notifier.Notify(ctx, event); err != nil {
+			log.Printf("Alert %q: %s delivery failed: %v", rule.Name, channel.Type, err)
+		}
+	}
+}
+
+// Rules returns every alert rule ordered by name
+func (e *Engine) Rules() []models.AlertRule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.sortedRulesLocked()
+}
+
+// Rule returns an alert rule by ID
+func (e *Engine) Rule(id string) (models.AlertRule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rule, ok := e.rules[id]
+	if !ok {
+		return models.AlertRule{}, ErrRuleNotFound
+	}
+	return rule, nil
+}
+
+// CreateRule stores a new alert rule
+func (e *Engine) CreateRule(rule models.AlertRule) (models.AlertRule, error) {
+	id, err := newRuleID()
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	now := time.Now().UTC()
+	rule.ID = id
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules[id] = rule
+	if err := e.saveLocked(); err != nil {
+		delete(e.rules, id)
+		return models.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces an alert rule, resetting its state so it is re-evaluated from scratch
+func (e *Engine) UpdateRule(id string, rule models.AlertRule) (models.AlertRule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	previous, ok := e.rules[id]
+	if !ok {
+		return models.AlertRule{}, ErrRuleNotFound
+	}
+
+	rule.ID = id
+	rule.CreatedAt = previous.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+
+	e.rules[id] = rule
+	status, hadStatus := e.statuses[id]
+	delete(e.statuses, id)
+	if err := e.saveLocked(); err != nil {
+		e.rules[id] = previous
+		if hadStatus {
+			e.statuses[id] = status
+		}
+		return models.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes an alert rule and its state; history is kept
+func (e *Engine) DeleteRule(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rule, ok := e.rules[id]
+	if !ok {
+		return ErrRuleNotFound
+	}
+
+	status, hadStatus := e.statuses[id]
+	delete(e.rules, id)
+	delete(e.statuses, id)
+	if err := e.saveLocked(); err != nil {
+		e.rules[id] = rule
+		if hadStatus {
+			e.statuses[id] = status
+		}
+		return err
+	}
+	return nil
+}
+
+// Statuses returns the current state of every evaluated rule
+func (e *Engine) Statuses() []models.AlertStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make([]models.AlertStatus, 0, len(e.statuses))
+	for _, rule := range e.sortedRulesLocked() {
+		if status, ok := e.statuses[rule.ID]; ok {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// History returns state transitions newest first, optionally for a single rule
+func (e *Engine) History(ruleID string, limit int) []models.AlertEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	events := []models.AlertEvent{}
+	for i := len(e.history) - 1; i >= 0 && (limit <= 0 || len(events) < limit); i-- {
+		if ruleID == "" || e.history[i].RuleID == ruleID {
+			events = append(events, e.history[i])
+		}
+	}
+	return events
+}
+
+// sortedRulesLocked returns rules ordered by name; caller must hold mu
+func (e *Engine) sortedRulesLocked() []models.AlertRule {
+	rules := make([]models.AlertRule, 0, len(e.rules))
+	for _, rule := range e.rules {
+		rules = append(rules, rule)
+	}
+	sortRules(rules)
+	return rules
+}
+
+// appendHistoryLocked records a transition, dropping the oldest beyond maxHistory
+func (e *Engine) appendHistoryLocked(event models.AlertEvent) {
+	e.history = append(e.history, event)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// saveLocked writes rules, statuses and history to disk atomically; caller must hold mu
+func (e *Engine) saveLocked() error {
+	state := engineState{
+		Rules:    e.sortedRulesLocked(),
+		Statuses: make([]models.AlertStatus, 0, len(e.statuses)),
+		History:  e.history,
+	}
+	for _, status := range e.statuses {
+		state.Statuses = append(state.Statuses, status)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode alerts: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create alerts directory: %w", err)
+	}
+
+	tmp := e.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write alerts file: %w", err)
+	}
+	if err := os.Rename(tmp, e.path); err != nil {
+		return fmt.Errorf("failed to write alerts file: %w", err)
+	}
+	return nil
+}
+
+// newRuleID generates a random alert rule identifier
+func newRuleID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate rule id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// startOfDay truncates t to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
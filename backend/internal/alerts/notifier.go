@@ -0,0 +1,130 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Notifier delivers alert state changes to an external destination
+type Notifier interface {
+	Notify(ctx context.Context, event models.AlertEvent) error
+}
+
+// NotifierFactory builds a notifier from a rule's channel configuration
+type NotifierFactory func(channel models.AlertChannel) (Notifier, error)
+
+// Built-in channel types
+const (
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]NotifierFactory{
+		ChannelWebhook: newWebhookNotifier,
+		ChannelLog:     newLogFileNotifier,
+	}
+)
+
+// RegisterNotifier adds or replaces the factory for a channel type
+func RegisterNotifier(channelType string, factory NotifierFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[channelType] = factory
+}
+
+// NewNotifier builds the notifier for a channel
+func NewNotifier(channel models.AlertChannel) (Notifier, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[channel.Type]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown channel type %q", channel.Type)
+	}
+	return factory(channel)
+}
+
+// WebhookNotifier posts alert events as JSON to a URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookNotifier(channel models.AlertChannel) (Notifier, error) {
+	if channel.URL == "" {
+		return nil, fmt.Errorf("webhook channel requires a url")
+	}
+	return &WebhookNotifier{
+		url:    channel.URL,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify posts the event and treats any non-2xx response as a failure
+func (n *WebhookNotifier) Notify(ctx context.Context, event models.AlertEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LogFileNotifier appends alert events as JSON lines to a file
+type LogFileNotifier struct {
+	path string
+}
+
+// logFileMu serializes appends so concurrent notifications never interleave lines
+var logFileMu sync.Mutex
+
+func newLogFileNotifier(channel models.AlertChannel) (Notifier, error) {
+	if channel.Path == "" {
+		return nil, fmt.Errorf("log channel requires a path")
+	}
+	return &LogFileNotifier{path: channel.Path}, nil
+}
+
+// Notify appends the event to the log file
+func (n *LogFileNotifier) Notify(ctx context.Context, event models.AlertEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	logFileMu.Lock()
+	defer logFileMu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open alert log: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,177 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// Supported metrics
+const (
+	MetricEvents      = "events"
+	MetricActiveUsers = "activeUsers"
+)
+
+// Supported conditions
+const (
+	ConditionBelow    = "below"
+	ConditionAbove    = "above"
+	ConditionDecrease = "decrease"
+	ConditionIncrease = "increase"
+)
+
+// Alert states
+const (
+	StateOK       = "ok"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// evaluation is the outcome of checking one rule
+type evaluation struct {
+	firing  bool
+	value   float64
+	message string
+}
+
+// ValidateRule checks a rule definition, using companyExists to verify the referenced company
+func ValidateRule(rule models.AlertRule, companyExists func(string) bool) error {
+	var problems []string
+
+	if strings.TrimSpace(rule.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if rule.CompanyID != "" && !companyExists(rule.CompanyID) {
+		problems = append(problems, fmt.Sprintf("companyId %q does not match any company", rule.CompanyID))
+	}
+	if rule.Metric != MetricEvents && rule.Metric != MetricActiveUsers {
+		problems = append(problems, "metric must be events or activeUsers")
+	}
+
+	switch rule.Condition {
+	case ConditionBelow, ConditionAbove:
+		if rule.Threshold < 0 {
+			problems = append(problems, "threshold must not be negative")
+		}
+		if rule.ConsecutiveDays < 0 || rule.ConsecutiveDays > 90 {
+			problems = append(problems, "consecutiveDays must be between 0 (the default, 1 day) and 90")
+		}
+	case ConditionDecrease, ConditionIncrease:
+		if rule.Threshold <= 0 {
+			problems = append(problems, "threshold must be a positive percentage")
+		}
+		if rule.PeriodDays < 0 || rule.PeriodDays > 180 {
+			problems = append(problems, "periodDays must be between 0 (the default, 7 days) and 180")
+		}
+	default:
+		problems = append(problems, "condition must be below, above, decrease or increase")
+	}
+
+	for i, channel := range rule.Channels {
+		if _, err := NewNotifier(channel); err != nil {
+			problems = append(problems, fmt.Sprintf("channels[%d]: %v", i, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// evaluateRule checks a rule against the analytics data as of the given day
+func evaluateRule(service *services.AnalyticsService, rule models.AlertRule, asOf time.Time) evaluation {
+	switch rule.Condition {
+	case ConditionBelow, ConditionAbove:
+		return evaluateThreshold(service, rule, asOf)
+	default:
+		return evaluateChange(service, rule, asOf)
+	}
+}
+
+// evaluateThreshold fires when every one of the last N days breaches the threshold
+func evaluateThreshold(service *services.AnalyticsService, rule models.AlertRule, asOf time.Time) evaluation {
+	days := max(rule.ConsecutiveDays, 1)
+
+	breaching := true
+	var latest float64
+	for offset := 0; offset < days; offset++ {
+		day := asOf.AddDate(0, 0, -offset)
+		value := metricValue(service.Activity(rule.CompanyID, day, day), rule.Metric)
+		if offset == 0 {
+			latest = value
+		}
+
+		if (rule.Condition == ConditionBelow && value >= rule.Threshold) ||
+			(rule.Condition == ConditionAbove && value <= rule.Threshold) {
+			breaching = false
+			break
+		}
+	}
+
+	message := fmt.Sprintf("%s daily %s %s %g for %d consecutive day(s) (latest %g on %s)",
+		subject(rule), rule.Metric, rule.Condition, rule.Threshold, days, latest, asOf.Format("2006-01-02"))
+	return evaluation{firing: breaching, value: latest, message: message}
+}
+
+// evaluateChange fires when the current period changed by more than the threshold percentage
+func evaluateChange(service *services.AnalyticsService, rule models.AlertRule, asOf time.Time) evaluation {
+	period := rule.PeriodDays
+	if period <= 0 {
+		period = 7
+	}
+
+	currentFrom := asOf.AddDate(0, 0, -period+1)
+	previousTo := currentFrom.AddDate(0, 0, -1)
+	previousFrom := previousTo.AddDate(0, 0, -period+1)
+
+	current := metricValue(service.Activity(rule.CompanyID, currentFrom, asOf), rule.Metric)
+	previous := metricValue(service.Activity(rule.CompanyID, previousFrom, previousTo), rule.Metric)
+
+	if previous == 0 {
+		// No baseline to compare against
+		return evaluation{
+			value:   0,
+			message: fmt.Sprintf("%s %s had no baseline in the previous %d days", subject(rule), rule.Metric, period),
+		}
+	}
+
+	change := math.Round((current-previous)/previous*1000) / 10
+	firing := (rule.Condition == ConditionDecrease && change <= -rule.Threshold) ||
+		(rule.Condition == ConditionIncrease && change >= rule.Threshold)
+
+	message := fmt.Sprintf("%s %s changed %+g%% over %d days (%g vs %g), alert on %s of %g%%",
+		subject(rule), rule.Metric, change, period, current, previous, rule.Condition, rule.Threshold)
+	return evaluation{firing: firing, value: change, message: message}
+}
+
+// metricValue extracts the rule's metric from an activity summary
+func metricValue(activity models.ActivitySummary, metric string) float64 {
+	if metric == MetricActiveUsers {
+		return float64(activity.ActiveUsers)
+	}
+	return float64(activity.Events)
+}
+
+// subject describes what the rule is watching
+func subject(rule models.AlertRule) string {
+	if rule.CompanyID == "" {
+		return "All companies"
+	}
+	return "Company " + rule.CompanyID
+}
+
+// sortRules orders rules by name for stable listings
+func sortRules(rules []models.AlertRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Name != rules[j].Name {
+			return rules[i].Name < rules[j].Name
+		}
+		return rules[i].ID < rules[j].ID
+	})
+}
@@ -0,0 +1,289 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Entry kinds
+const (
+	KindRequest = "request"
+	KindAdmin   = "admin"
+)
+
+// AllCompanies marks an entry that could read every company's data
+const AllCompanies = "*"
+
+// rotatedSuffix timestamps rotated files so they sort oldest first
+const rotatedSuffix = "20060102T150405.000000000Z"
+
+// Logger appends audit entries to a JSON lines file and rotates it by size
+// Entries are only ever appended; rotated files are kept until there are more than maxFiles
+type Logger struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens the audit log at path for appending, creating it if needed
+// A maxFiles of zero keeps every rotated file
+func Open(path string, maxBytes int64, maxFiles int) (*Logger, error) {
+	logger := &Logger{
+		path:     path,
+		maxBytes: maxBytes,
+		maxFiles: maxFiles,
+	}
+	if err := logger.openLocked(); err != nil {
+		return nil, err
+	}
+	return logger, nil
+}
+
+// Record appends an entry, filling in its ID and time when missing
+// Failures are logged rather than returned so auditing never fails a request
+func (l *Logger) Record(entry models.AuditEntry) {
+	line, err := encode(entry)
+	if err != nil {
+		log.Printf("Failed to encode audit entry: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err := l.rotateLocked(); err != nil {
+			log.Printf("Failed to rotate audit log: %v", err)
+		}
+	}
+	if l.file == nil {
+		if err := l.openLocked(); err != nil {
+			log.Printf("Failed to write audit entry: %v", err)
+			return
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		log.Printf("Failed to write audit entry: %v", err)
+	}
+}
+
+// Query returns matching entries from the current and rotated files, newest first
+func (l *Logger) Query(query models.AuditQuery) (models.AuditResponse, error) {
+	files, err := l.files()
+	if err != nil {
+		return models.AuditResponse{}, err
+	}
+
+	response := models.AuditResponse{
+		Entries: []models.AuditEntry{},
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		entries, err := readEntries(files[i])
+		if err != nil {
+			return models.AuditResponse{}, err
+		}
+		for j := len(entries) - 1; j >= 0; j-- {
+			if !matches(entries[j], query) {
+				continue
+			}
+			if response.Total >= query.Offset && len(response.Entries) < query.Limit {
+				response.Entries = append(response.Entries, entries[j])
+			}
+			response.Total++
+		}
+	}
+	return response, nil
+}
+
+// Close closes the current file
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Append writes one entry to the audit log at path without rotating it
+// It lets command line tools record admin actions next to the server's entries
+func Append(path string, entry models.AuditEntry) error {
+	line, err := encode(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return file.Close()
+}
+
+// openLocked opens the current file for appending; caller must hold mu
+func (l *Logger) openLocked() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotateLocked renames the current file aside, removes rotated files beyond
+// maxFiles and starts a new file; caller must hold mu
+func (l *Logger) rotateLocked() error {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+
+	rotated := l.path + "." + time.Now().UTC().Format(rotatedSuffix)
+	if err := os.Rename(l.path, rotated); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if l.maxFiles > 0 {
+		previous, err := l.rotatedFiles()
+		if err != nil {
+			return err
+		}
+		for len(previous) > l.maxFiles {
+			if err := os.Remove(previous[0]); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			previous = previous[1:]
+		}
+	}
+
+	return l.openLocked()
+}
+
+// files returns the rotated files oldest first, followed by the current file
+func (l *Logger) files() ([]string, error) {
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	return append(files, l.path), nil
+}
+
+// rotatedFiles returns the rotated files, oldest first
+func (l *Logger) rotatedFiles() ([]string, error) {
+	files, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readEntries reads every complete entry in a file; a missing file has none
+func readEntries(path string) ([]models.AuditEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var entries []models.AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry models.AuditEntry
+		// A line still being written by another process is skipped
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// matches reports whether an entry satisfies every filter in query
+func matches(entry models.AuditEntry, query models.AuditQuery) bool {
+	if query.Subject != "" && entry.Subject != query.Subject {
+		return false
+	}
+	if query.Kind != "" && entry.Kind != query.Kind {
+		return false
+	}
+	if query.Action != "" && !strings.Contains(strings.ToLower(entry.Action), strings.ToLower(query.Action)) {
+		return false
+	}
+	if query.CompanyID != "" && !slices.Contains(entry.Companies, query.CompanyID) && !slices.Contains(entry.Companies, AllCompanies) {
+		return false
+	}
+	if !query.From.IsZero() && entry.Time.Before(query.From) {
+		return false
+	}
+	return query.To.IsZero() || entry.Time.Before(query.To)
+}
+
+// encode fills in missing fields and renders an entry as one JSON line
+func encode(entry models.AuditEntry) ([]byte, error) {
+	if entry.ID == "" {
+		entry.ID = newID()
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// newID generates a random audit entry identifier
+func newID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf) // crypto/rand.Read never fails since Go 1.24
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context keys set by route groups and handlers for the audit middleware
+const (
+	dataAccessKey = "audit.dataAccess"
+	adminKey      = "audit.admin"
+	subjectKey    = "audit.subject"
+	companiesKey  = "audit.companies"
+)
+
+// Middleware records every request that passes through it once the handler has finished,
+// including requests rejected by the auth middleware
+// Emails in the request are recorded as the redactor's digests
+func Middleware(logger *Logger, redactor *privacy.Redactor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		entry := models.AuditEntry{
+			Time:       start.UTC(),
+			Kind:       KindRequest,
+			Subject:    "anonymous",
+			ClientIP:   c.ClientIP(),
+			Action:     c.Request.Method + " " + route,
+			Route:      route,
+			Params:     params(c, redactor),
+			Query:      query(c, redactor),
+			Status:     c.Writer.Status(),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if c.GetBool(adminKey) {
+			entry.Kind = KindAdmin
+		}
+		if subject := c.GetString(subjectKey); subject != "" {
+			entry.Subject = subject
+		}
+
+		principal, authenticated := auth.PrincipalFrom(c)
+		if authenticated {
+			entry.Subject = principal.Subject
+			entry.Method = principal.Method
+			entry.Role = auth.EffectiveRole(principal.Role)
+		}
+
+		// Record whose data the request could read, so access to one account can be traced
+		// Handlers name the companies they resolved from path parameters, saved views or request bodies
+		if c.GetBool(dataAccessKey) && authenticated {
+			switch companyID := strings.TrimSpace(c.Query("companyId")); {
+			case len(c.GetStringSlice(companiesKey)) > 0:
+				entry.Companies = c.GetStringSlice(companiesKey)
+			case companyID != "":
+				entry.Companies = []string{companyID}
+			case auth.CompanyScope(principal) != nil:
+				entry.Companies = principal.Companies
+			default:
+				entry.Companies = []string{AllCompanies}
+			}
+		}
+
+		if len(c.Errors) > 0 {
+			entry.Details = c.Errors.String()
+		}
+		logger.Record(entry)
+	}
+}
+
+// MarkDataAccess tags requests in a route group as reading usage data
+func MarkDataAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(dataAccessKey, true)
+		c.Next()
+	}
+}
+
+// MarkAdmin tags requests in a route group as admin actions
+func MarkAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(adminKey, true)
+		c.Next()
+	}
+}
+
+// SetSubject names the caller of a request that has no principal, such as a login attempt
+func SetSubject(c *gin.Context, subject string) {
+	c.Set(subjectKey, subject)
+}
+
+// SetCompanies names the companies whose data a request read, when they are not
+// given by its companyId query parameter
+func SetCompanies(c *gin.Context, companyIDs ...string) {
+	c.Set(companiesKey, companyIDs)
+}
+
+// params returns the route parameters, with emails replaced by their digest so
+// erasure requests do not keep the erased address in the log
+func params(c *gin.Context, redactor *privacy.Redactor) map[string]string {
+	if len(c.Params) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		value := param.Value
+		if param.Key == "email" {
+			value = redactor.Digest(value)
+		}
+		values[param.Key] = value
+	}
+	return values
+}
+
+// query returns the query string, with every email in its values replaced by the email's digest
+// Searches by email are common, and the log must not become a list of the people looked up
+func query(c *gin.Context, redactor *privacy.Redactor) map[string][]string {
+	values := c.Request.URL.Query()
+	for key, list := range values {
+		for i, value := range list {
+			for _, email := range privacy.Emails(value) {
+				value = strings.ReplaceAll(value, email, redactor.Digest(email))
+			}
+			list[i] = value
+		}
+		values[key] = list
+	}
+	return values
+}
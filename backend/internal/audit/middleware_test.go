@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddlewareDigestsEmails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := Open(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	redactor := privacy.NewRedactor([]byte("0123456789abcdef"))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(logger, redactor))
+	router.GET("/api/users/:email/events", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	target := "/api/users/Jane.Doe@acme.com/events?search=jane.doe%40acme.com&q=user%3Abob%40acme.com+login&companyId=acme"
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+
+	response, err := logger.Query(models.AuditQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(response.Entries))
+	}
+	entry := response.Entries[0]
+
+	if got, want := entry.Params["email"], redactor.Digest("jane.doe@acme.com"); got != want {
+		t.Errorf("email param = %q, want %q", got, want)
+	}
+	if got, want := entry.Query["search"], []string{redactor.Digest("jane.doe@acme.com")}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("search = %q, want %q", got, want)
+	}
+	if got, want := entry.Query["q"], "user:"+redactor.Digest("bob@acme.com")+" login"; len(got) != 1 || got[0] != want {
+		t.Errorf("q = %q, want %q", got, want)
+	}
+	if got := entry.Query["companyId"]; len(got) != 1 || got[0] != "acme" {
+		t.Errorf("companyId = %q, want unchanged", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log := strings.ToLower(string(data)); strings.Contains(log, "jane.doe@") || strings.Contains(log, "bob@") {
+		t.Errorf("log keeps an email: %s", data)
+	}
+}
+
+func TestDigestsAreKeyed(t *testing.T) {
+	redactor := privacy.NewRedactor([]byte("0123456789abcdef"))
+	digest := redactor.Digest("jane.doe@acme.com")
+
+	if got := redactor.Digest("  Jane.Doe@ACME.com "); got != digest {
+		t.Errorf("digest of the same address with other case and spacing = %q, want %q", got, digest)
+	}
+	if other := privacy.NewRedactor([]byte("fedcba9876543210")).Digest("jane.doe@acme.com"); other == digest {
+		t.Error("digests do not depend on the key")
+	}
+	if pseudonym := strings.TrimPrefix(redactor.Pseudonym("jane.doe@acme.com"), "user-"); strings.Contains(digest, pseudonym) {
+		t.Errorf("digest %q contains the pseudonym %q", digest, pseudonym)
+	}
+}
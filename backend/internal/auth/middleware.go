@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authentication methods recorded on principals
+const (
+	MethodAPIKey   = "apikey"
+	MethodSession  = "session"
+	MethodDisabled = "disabled"
+)
+
+// SessionCookie carries the dashboard session token for browser clients
+const SessionCookie = "uad_session"
+
+// principalKey is the gin context key holding the authenticated principal
+const principalKey = "auth.principal"
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrSessionsDisabled is returned by Login when no session secret is configured
+	ErrSessionsDisabled = errors.New("session login is not enabled")
+)
+
+// Options configures an Authenticator
+type Options struct {
+	// SessionSecret signs dashboard session tokens; login is disabled when empty
+	SessionSecret string
+	SessionTTL    time.Duration
+	// Disabled lets every request through with admin scope, for local development
+	Disabled bool
+	// PrivacyPolicy sets the email redaction mode for each role
+	PrivacyPolicy privacy.Policy
+}
+
+// Authenticator resolves request credentials to principals
+type Authenticator struct {
+	store         *Store
+	sessionSecret []byte
+	sessionTTL    time.Duration
+	disabled      bool
+	privacyPolicy privacy.Policy
+}
+
+// NewAuthenticator creates an authenticator backed by a credential store
+func NewAuthenticator(store *Store, options Options) *Authenticator {
+	ttl := options.SessionTTL
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
+	}
+	return &Authenticator{
+		store:         store,
+		sessionSecret: []byte(options.SessionSecret),
+		sessionTTL:    ttl,
+		disabled:      options.Disabled,
+		privacyPolicy: options.PrivacyPolicy,
+	}
+}
+
+// Login checks dashboard credentials and issues a signed session token
+func (a *Authenticator) Login(username, password string) (models.LoginResponse, error) {
+	if len(a.sessionSecret) == 0 {
+		return models.LoginResponse{}, ErrSessionsDisabled
+	}
+
+	user, err := a.store.AuthenticateUser(username, password)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(a.sessionTTL)
+	token, err := signToken(a.sessionSecret, sessionClaims{
+		Subject:   user.Username,
+		Scopes:    user.Scopes,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Principal: a.userPrincipal(user),
+	}, nil
+}
+
+// Authenticate resolves the caller from an API key or session token on the request
+func (a *Authenticator) Authenticate(r *http.Request) (models.Principal, error) {
+	token := requestToken(r)
+	if token == "" {
+		return models.Principal{}, ErrNoCredentials
+	}
+
+	if IsAPIKey(token) {
+		key, err := a.store.AuthenticateKey(token)
+		if err != nil {
+			return models.Principal{}, err
+		}
+		role := EffectiveRole(key.Role)
+		return models.Principal{
+			Subject:   key.Name,
+			Method:    MethodAPIKey,
+			Scopes:    key.Scopes,
+			Role:      role,
+			Companies: key.Companies,
+			Privacy:   a.privacyPolicy.Mode(role),
+		}, nil
+	}
+
+	if len(a.sessionSecret) == 0 {
+		return models.Principal{}, ErrInvalidCredentials
+	}
+	claims, err := parseToken(a.sessionSecret, token, time.Now())
+	if err != nil {
+		return models.Principal{}, ErrInvalidCredentials
+	}
+
+	// Use the user's current scopes and assignment so changes apply to open sessions
+	user, err := a.store.User(claims.Subject)
+	if errors.Is(err, ErrUserNotFound) {
+		return models.Principal{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+	return a.userPrincipal(user), nil
+}
+
+// userPrincipal builds the principal for a dashboard user
+func (a *Authenticator) userPrincipal(user models.DashboardUser) models.Principal {
+	role := EffectiveRole(user.Role)
+	return models.Principal{
+		Subject:   user.Username,
+		Method:    MethodSession,
+		Scopes:    user.Scopes,
+		Role:      role,
+		Companies: a.store.VisibleCompanies(user),
+		Privacy:   a.privacyPolicy.Mode(role),
+	}
+}
+
+// Middleware authenticates every request in a route group
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.disabled {
+			c.Set(principalKey, models.Principal{Subject: "anonymous", Method: MethodDisabled, Scopes: []string{ScopeAdmin}, Role: RoleAdmin, Privacy: privacy.ModeNone})
+			c.Next()
+			return
+		}
+
+		principal, err := a.Authenticate(c.Request)
+		switch {
+		case err == nil:
+			c.Set(principalKey, principal)
+			c.Next()
+		case errors.Is(err, ErrNoCredentials):
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Authentication required",
+				"details": "provide an API key or session token",
+			})
+		case errors.Is(err, ErrInvalidCredentials):
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Invalid credentials",
+				"details": err.Error(),
+			})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to authenticate request",
+				"details": err.Error(),
+			})
+		}
+	}
+}
+
+// RequireScope rejects callers whose principal lacks scope
+// It must run after Middleware
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFrom(c)
+		if !ok || !HasScope(principal.Scopes, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Insufficient scope",
+				"details": "requires " + scope,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAllCompanies rejects callers limited to a subset of companies,
+// for routes whose data cannot be filtered per company
+// It must run after Middleware
+func RequireAllCompanies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFrom(c)
+		if !ok || CompanyScope(principal) != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Insufficient role",
+				"details": "requires access to all companies",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the principal authenticated for the request
+func PrincipalFrom(c *gin.Context) (models.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return models.Principal{}, false
+	}
+	principal, ok := value.(models.Principal)
+	return principal, ok
+}
+
+// requestToken extracts credentials from the Authorization header, X-API-Key header or session cookie
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"usage-analytics-dashboard/internal/models"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$600000$") {
+		t.Errorf("hash = %q, want the pbkdf2-sha256 format", hash)
+	}
+	if other, _ := HashPassword("correct horse battery"); other == hash {
+		t.Error("two hashes of one password are equal, so the salt is not random")
+	}
+
+	parts := strings.Split(hash, "$")
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     bool
+	}{
+		{"correct", hash, "correct horse battery", true},
+		{"wrong", hash, "correct horse battery staple", false},
+		{"empty", hash, "", false},
+		{"other salt", strings.Join([]string{parts[0], parts[1], strings.Repeat("00", passwordSaltLength), parts[3]}, "$"), "correct horse battery", false},
+		{"other iterations", strings.Join([]string{parts[0], "1000", parts[2], parts[3]}, "$"), "correct horse battery", false},
+		{"unknown scheme", "bcrypt$" + strings.Join(parts[1:], "$"), "correct horse battery", false},
+		{"malformed", "pbkdf2-sha256$zero$salt$hash", "correct horse battery", false},
+	}
+	for _, tt := range tests {
+		if got := checkPassword(tt.encoded, tt.password); got != tt.want {
+			t.Errorf("%s: checkPassword = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateKeyVerifiesTheSecretHash(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "auth.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	minted, token, err := store.MintKey(models.APIKey{Name: "ci", Scopes: []string{ScopeAnalyticsRead}, Role: RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(minted.Hash, strings.TrimPrefix(token, apiKeyPrefix+minted.ID+"_")) {
+		t.Error("the stored hash contains the secret")
+	}
+
+	key, err := store.AuthenticateKey(token)
+	if err != nil {
+		t.Fatalf("valid key rejected: %v", err)
+	}
+	if key.ID != minted.ID {
+		t.Errorf("authenticated key %q, want %q", key.ID, minted.ID)
+	}
+
+	id, secret, _ := parseKeyToken(token)
+	flipped := []byte(secret)
+	flipped[0] ^= 1
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong secret", apiKeyPrefix + id + "_" + string(flipped)},
+		{"truncated secret", apiKeyPrefix + id + "_" + secret[:len(secret)-1]},
+		{"unknown id", apiKeyPrefix + "unknown_" + secret},
+		{"no secret", apiKeyPrefix + id + "_"},
+		{"no prefix", id + "_" + secret},
+	}
+	for _, tt := range tests {
+		if _, err := store.AuthenticateKey(tt.token); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, ErrInvalidCredentials)
+		}
+	}
+
+	if _, err := store.RevokeKey(minted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AuthenticateKey(token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("revoked key: err = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Roles control which companies a caller can see
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleRep     = "rep"
+)
+
+// Roles lists every known role
+var Roles = []string{RoleAdmin, RoleManager, RoleRep}
+
+// ValidateAssignment checks a role, its scopes and its company assignment
+func ValidateAssignment(role string, scopes []string, companies []string) error {
+	var problems []string
+
+	if !slices.Contains(Roles, role) {
+		problems = append(problems, fmt.Sprintf("role must be one of %s", strings.Join(Roles, ", ")))
+	}
+	if role != RoleAdmin && slices.Contains(scopes, ScopeAdmin) {
+		problems = append(problems, "only the admin role can hold the admin scope")
+	}
+	if role == RoleRep && len(companies) == 0 {
+		problems = append(problems, "reps must be assigned at least one company")
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ParseCompanies splits a comma-separated list of company IDs
+func ParseCompanies(value string) []string {
+	var companies []string
+	for _, companyID := range strings.Split(value, ",") {
+		companyID = strings.TrimSpace(companyID)
+		if companyID != "" && !slices.Contains(companies, companyID) {
+			companies = append(companies, companyID)
+		}
+	}
+	return companies
+}
+
+// CompanyScope returns the query scope for a principal, or nil when it can see every company
+func CompanyScope(principal models.Principal) *models.CompanyScope {
+	if EffectiveRole(principal.Role) == RoleAdmin {
+		return nil
+	}
+	return &models.CompanyScope{CompanyIDs: principal.Companies}
+}
+
+// EffectiveRole treats credentials created before roles existed as admins,
+// which matches the access they had at the time
+func EffectiveRole(role string) string {
+	if role == "" {
+		return RoleAdmin
+	}
+	return role
+}
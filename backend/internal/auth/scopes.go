@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scopes grantable to API keys and dashboard users
+const (
+	ScopeAnalyticsRead = "analytics:read"
+	ScopeEventsIngest  = "events:ingest"
+	ScopeAdmin         = "admin"
+)
+
+// Scopes lists every known scope
+var Scopes = []string{ScopeAnalyticsRead, ScopeEventsIngest, ScopeAdmin}
+
+// HasScope reports whether granted includes scope; admin implies every scope
+func HasScope(granted []string, scope string) bool {
+	return slices.Contains(granted, scope) || slices.Contains(granted, ScopeAdmin)
+}
+
+// ParseScopes splits a comma-separated scope list and checks every entry is known
+func ParseScopes(value string) ([]string, error) {
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q, expected one of %s", scope, strings.Join(Scopes, ", "))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
@@ -0,0 +1,428 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// apiKeyPrefix marks tokens that are API keys rather than session tokens
+const apiKeyPrefix = "uak_"
+
+var (
+	// ErrKeyNotFound is returned when an API key does not exist
+	ErrKeyNotFound = errors.New("api key not found")
+	// ErrUserNotFound is returned when a dashboard user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists is returned when adding a user whose name is taken
+	ErrUserExists = errors.New("user already exists")
+	// ErrInvalidCredentials is returned for unknown, revoked or mismatched credentials
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// storeState is the on-disk representation of the store
+type storeState struct {
+	Keys  []models.APIKey        `json:"keys"`
+	Users []models.DashboardUser `json:"users"`
+}
+
+// Store persists API keys and dashboard users to a JSON file
+// The server only reads the file and reloads it when it changes, so keys
+// minted or revoked with the CLI take effect without a restart
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	keys    map[string]models.APIKey
+	users   map[string]models.DashboardUser
+
+	reloadSubscribers []func(keys, users int)
+}
+
+// NewStore loads credentials from path, starting empty if the file does not exist
+func NewStore(path string) (*Store, error) {
+	store := &Store{
+		path:  path,
+		keys:  make(map[string]models.APIKey),
+		users: make(map[string]models.DashboardUser),
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// SubscribeReload registers a function to be called whenever a changed file is reloaded
+// The initial load happens in NewStore, before any subscriber can be registered
+func (s *Store) SubscribeReload(subscriber func(keys, users int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadSubscribers = append(s.reloadSubscribers, subscriber)
+}
+
+// Check reloads the file if it changed and returns the error of a failed reload
+// Credentials cannot be checked while the file is unreadable, so readiness depends on it
+func (s *Store) Check() error {
+	return s.load()
+}
+
+// load reads the file if it changed since the last load
+func (s *Store) load() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read auth file: %w", err)
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read auth file: %w", err)
+	}
+
+	var state storeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse auth file: %w", err)
+	}
+
+	keys := make(map[string]models.APIKey, len(state.Keys))
+	for _, key := range state.Keys {
+		keys[key.ID] = key
+	}
+	users := make(map[string]models.DashboardUser, len(state.Users))
+	for _, user := range state.Users {
+		users[user.Username] = user
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.users = users
+	s.modTime = info.ModTime()
+	subscribers := s.reloadSubscribers
+	s.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(len(keys), len(users))
+	}
+	return nil
+}
+
+// AuthenticateKey checks an API key token and returns the key it belongs to
+func (s *Store) AuthenticateKey(token string) (models.APIKey, error) {
+	if err := s.load(); err != nil {
+		return models.APIKey{}, err
+	}
+
+	id, secret, ok := parseKeyToken(token)
+	if !ok {
+		return models.APIKey{}, ErrInvalidCredentials
+	}
+
+	s.mu.RLock()
+	key, found := s.keys[id]
+	s.mu.RUnlock()
+
+	if !found || key.RevokedAt != nil {
+		return models.APIKey{}, ErrInvalidCredentials
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.Hash)) != 1 {
+		return models.APIKey{}, ErrInvalidCredentials
+	}
+	return key, nil
+}
+
+// AuthenticateUser checks a dashboard username and password
+func (s *Store) AuthenticateUser(username, password string) (models.DashboardUser, error) {
+	if err := s.load(); err != nil {
+		return models.DashboardUser{}, err
+	}
+
+	s.mu.RLock()
+	user, found := s.users[username]
+	s.mu.RUnlock()
+
+	if !found || !checkPassword(user.PasswordHash, password) {
+		return models.DashboardUser{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// User returns a dashboard user by name
+func (s *Store) User(username string) (models.DashboardUser, error) {
+	if err := s.load(); err != nil {
+		return models.DashboardUser{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, found := s.users[username]
+	if !found {
+		return models.DashboardUser{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Keys returns every API key ordered by creation time
+func (s *Store) Keys() []models.APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// Users returns every dashboard user ordered by name
+func (s *Store) Users() []models.DashboardUser {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]models.DashboardUser, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users
+}
+
+// MintKey stores a new API key with the name, scopes, role and companies of key
+// and returns it with the plaintext token, which is not stored and cannot be recovered
+func (s *Store) MintKey(key models.APIKey) (models.APIKey, string, error) {
+	if err := ValidateAssignment(key.Role, key.Scopes, key.Companies); err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	secret := randomHex(32)
+	key.ID = randomHex(8)
+	key.Hash = hashSecret(secret)
+	key.CreatedAt = time.Now().UTC()
+	key.RevokedAt = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = key
+	if err := s.saveLocked(); err != nil {
+		delete(s.keys, key.ID)
+		return models.APIKey{}, "", err
+	}
+	return key, apiKeyPrefix + key.ID + "_" + secret, nil
+}
+
+// RevokeKey marks an API key as revoked; revoked keys are kept for auditing
+func (s *Store) RevokeKey(id string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, found := s.keys[id]
+	if !found {
+		return models.APIKey{}, ErrKeyNotFound
+	}
+	if previous.RevokedAt != nil {
+		return previous, nil
+	}
+
+	key := previous
+	now := time.Now().UTC()
+	key.RevokedAt = &now
+
+	s.keys[id] = key
+	if err := s.saveLocked(); err != nil {
+		s.keys[id] = previous
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+// AddUser stores a new dashboard user with the scopes and assignment of user and a hashed password
+func (s *Store) AddUser(user models.DashboardUser, password string) (models.DashboardUser, error) {
+	if err := ValidateAssignment(user.Role, user.Scopes, user.Companies); err != nil {
+		return models.DashboardUser{}, err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return models.DashboardUser{}, err
+	}
+	user.PasswordHash = hash
+	user.CreatedAt = time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Username]; exists {
+		return models.DashboardUser{}, ErrUserExists
+	}
+	s.users[user.Username] = user
+	if err := s.saveLocked(); err != nil {
+		delete(s.users, user.Username)
+		return models.DashboardUser{}, err
+	}
+	return user, nil
+}
+
+// AssignUser changes a dashboard user's role, companies and manager
+func (s *Store) AssignUser(username, role string, companies []string, manager string) (models.DashboardUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, found := s.users[username]
+	if !found {
+		return models.DashboardUser{}, ErrUserNotFound
+	}
+	if err := ValidateAssignment(role, previous.Scopes, companies); err != nil {
+		return models.DashboardUser{}, err
+	}
+
+	user := previous
+	user.Role = role
+	user.Companies = companies
+	user.Manager = manager
+
+	s.users[username] = user
+	if err := s.saveLocked(); err != nil {
+		s.users[username] = previous
+		return models.DashboardUser{}, err
+	}
+	return user, nil
+}
+
+// VisibleCompanies returns the companies a user may see: their own assignment
+// plus, for managers, the assignments of the reps reporting to them
+func (s *Store) VisibleCompanies(user models.DashboardUser) []string {
+	companies := slices.Clone(user.Companies)
+	if EffectiveRole(user.Role) != RoleManager {
+		return companies
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rep := range s.users {
+		if rep.Manager != user.Username {
+			continue
+		}
+		for _, companyID := range rep.Companies {
+			if !slices.Contains(companies, companyID) {
+				companies = append(companies, companyID)
+			}
+		}
+	}
+	sort.Strings(companies)
+	return companies
+}
+
+// RemoveUser deletes a dashboard user
+func (s *Store) RemoveUser(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, found := s.users[username]
+	if !found {
+		return ErrUserNotFound
+	}
+
+	delete(s.users, username)
+	if err := s.saveLocked(); err != nil {
+		s.users[username] = user
+		return err
+	}
+	return nil
+}
+
+// saveLocked writes keys and users to disk atomically; caller must hold mu
+func (s *Store) saveLocked() error {
+	state := storeState{
+		Keys:  make([]models.APIKey, 0, len(s.keys)),
+		Users: make([]models.DashboardUser, 0, len(s.users)),
+	}
+	for _, key := range s.keys {
+		state.Keys = append(state.Keys, key)
+	}
+	sort.Slice(state.Keys, func(i, j int) bool {
+		return state.Keys[i].CreatedAt.Before(state.Keys[j].CreatedAt)
+	})
+	for _, user := range s.users {
+		state.Users = append(state.Users, user)
+	}
+	sort.Slice(state.Users, func(i, j int) bool {
+		return state.Users[i].Username < state.Users[j].Username
+	})
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode auth file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create auth directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write auth file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write auth file: %w", err)
+	}
+	return nil
+}
+
+// IsAPIKey reports whether a bearer token looks like an API key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// parseKeyToken splits "uak_<id>_<secret>" into its parts
+func parseKeyToken(token string) (id, secret string, ok bool) {
+	rest, found := strings.CutPrefix(token, apiKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found = strings.Cut(rest, "_")
+	if !found || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// hashSecret hashes a key secret; secrets are random so a fast hash is sufficient
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes, hex-encoded
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf) // crypto/rand.Read never fails since Go 1.24
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// tokenHeader is the fixed JOSE header for HS256 tokens
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// sessionClaims are the JWT claims carried by dashboard session tokens
+type sessionClaims struct {
+	Subject   string   `json:"sub"`
+	Scopes    []string `json:"scopes"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// errInvalidToken is returned for malformed, tampered or expired tokens
+var errInvalidToken = errors.New("invalid or expired token")
+
+// signToken encodes and signs claims as an HS256 JWT
+func signToken(secret []byte, claims sessionClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + tokenSignature(secret, unsigned), nil
+}
+
+// parseToken verifies an HS256 JWT and returns its claims
+func parseToken(secret []byte, token string, now time.Time) (sessionClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return sessionClaims{}, errInvalidToken
+	}
+
+	expected := tokenSignature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return sessionClaims{}, errInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return sessionClaims{}, errInvalidToken
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return sessionClaims{}, errInvalidToken
+	}
+	if claims.Subject == "" || now.Unix() >= claims.ExpiresAt {
+		return sessionClaims{}, errInvalidToken
+	}
+	return claims, nil
+}
+
+// tokenSignature computes the base64url HMAC-SHA256 of the signing input
+func tokenSignature(secret []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTokenRejectsTamperedAndExpiredTokens(t *testing.T) {
+	secret := []byte("session-secret")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	claims := sessionClaims{Subject: "ann", Scopes: []string{ScopeAnalyticsRead}, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	token, err := signToken(secret, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	got, err := parseToken(secret, token, now)
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if got.Subject != "ann" || len(got.Scopes) != 1 || got.Scopes[0] != ScopeAnalyticsRead {
+		t.Errorf("claims = %+v, want %+v", got, claims)
+	}
+
+	// A payload granting admin, signed with the original signature
+	escalated := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"ann","scopes":["admin"],"iat":0,"exp":9999999999}`))
+	otherHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	resigned, err := signToken([]byte("other-secret"), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+	}{
+		{"tampered payload", parts[0] + "." + escalated + "." + parts[2], now},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2])), now},
+		{"other header", otherHeader + "." + parts[1] + "." + parts[2], now},
+		{"unsigned", parts[0] + "." + parts[1] + ".", now},
+		{"other secret", resigned, now},
+		{"malformed", "not-a-token", now},
+		{"expired", token, now.Add(time.Hour)},
+		{"long expired", token, now.Add(48 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if _, err := parseToken(secret, tt.token, tt.now); err != errInvalidToken {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, errInvalidToken)
+		}
+	}
+}
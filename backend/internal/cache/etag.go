@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ETag returns a strong entity tag for a response body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified reports whether an If-None-Match header lists etag, so the client already has the body
+// Weak tags compare equal to strong ones, as If-None-Match uses weak comparison
+func NotModified(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Response is an encoded response body and its entity tag
+type Response struct {
+	Body []byte
+	ETag string
+}
+
+// NewResponse tags an encoded response body
+func NewResponse(body []byte) Response {
+	return Response{Body: body, ETag: ETag(body)}
+}
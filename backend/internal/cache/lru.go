@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats counts cache activity since startup
+type Stats struct {
+	Entries       int    `json:"entries"`
+	Capacity      int    `json:"capacity"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`
+	Expirations   uint64 `json:"expirations"`
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// entry is a cached value and when it stops being served
+type entry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+// LRU is a size and age bounded cache evicting the least recently used entry
+// Invalidate bumps a generation, so values computed before it are never stored after it
+type LRU[V any] struct {
+	capacity int
+	ttl      time.Duration
+
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	generation uint64
+	stats      Stats
+}
+
+// NewLRU creates a cache holding at most capacity entries for at most ttl each
+// A capacity of zero disables caching
+func NewLRU[V any](capacity int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value cached under key, counting a hit or a miss
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	cached := element.Value.(*entry[V])
+	if time.Now().After(cached.expires) {
+		c.removeLocked(element)
+		c.stats.Expirations++
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	c.stats.Hits++
+	return cached.value, true
+}
+
+// Generation returns the current generation, to be read before computing a value for Add
+func (c *LRU[V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Add caches a value computed during generation, dropping it if the cache was invalidated since
+func (c *LRU[V]) Add(key string, value V, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity <= 0 || generation != c.generation {
+		return
+	}
+
+	expires := time.Now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		cached := element.Value.(*entry[V])
+		cached.value, cached.expires = value, expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expires: expires})
+	for c.order.Len() > c.capacity {
+		c.removeLocked(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Invalidate drops every entry and any value still being computed
+func (c *LRU[V]) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	c.stats.Invalidations++
+}
+
+// Stats returns the cache counters
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+// removeLocked removes an entry; caller must hold mu
+func (c *LRU[V]) removeLocked(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry[V]).key)
+}
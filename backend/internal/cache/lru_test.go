@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddDropsValuesComputedBeforeInvalidate(t *testing.T) {
+	c := NewLRU[string](4, time.Minute)
+
+	// A request reads the generation, then an ingest invalidates while it computes
+	generation := c.Generation()
+	c.Invalidate()
+	c.Add("analytics", "stale", generation)
+	if value, ok := c.Get("analytics"); ok {
+		t.Fatalf("Get = %q after a stale Add, want a miss", value)
+	}
+
+	c.Add("analytics", "fresh", c.Generation())
+	if value, ok := c.Get("analytics"); !ok || value != "fresh" {
+		t.Errorf("Get = %q, %v, want %q", value, ok, "fresh")
+	}
+}
+
+func TestAddNeverStoresStaleValuesUnderConcurrentInvalidate(t *testing.T) {
+	c := NewLRU[int](64, time.Minute)
+	var version int
+	var mu sync.Mutex
+
+	// Each writer computes the current version as a request would; invalidations bump it
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				generation := c.Generation()
+				mu.Lock()
+				value := version
+				mu.Unlock()
+				c.Add("key", value, generation)
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		// Data changes before the cache is invalidated, as the analytics service does
+		mu.Lock()
+		version++
+		mu.Unlock()
+		c.Invalidate()
+
+		mu.Lock()
+		current := version
+		mu.Unlock()
+		if value, ok := c.Get("key"); ok && value != current {
+			t.Fatalf("Get = %d after invalidation %d", value, current)
+		}
+	}
+	wg.Wait()
+}
+
+func TestLRUEvictsAndExpires(t *testing.T) {
+	c := NewLRU[int](2, time.Minute)
+	c.Add("a", 1, c.Generation())
+	c.Add("b", 2, c.Generation())
+	c.Get("a")
+	c.Add("c", 3, c.Generation())
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry was kept")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("recently used entry was evicted")
+	}
+
+	expiring := NewLRU[int](2, -time.Second)
+	expiring.Add("a", 1, expiring.Generation())
+	if _, ok := expiring.Get("a"); ok {
+		t.Error("expired entry was served")
+	}
+	if stats := expiring.Stats(); stats.Expirations != 1 || stats.Entries != 0 {
+		t.Errorf("stats = %+v, want one expiration and no entries", stats)
+	}
+}
@@ -0,0 +1,328 @@
+package companies
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+var (
+	// ErrCompanyNotFound is returned when a company is not registered
+	ErrCompanyNotFound = errors.New("company not found")
+	// ErrSlugTaken is returned when another company already uses a slug
+	ErrSlugTaken = errors.New("slug is already used by another company")
+)
+
+// csvColumns are the columns of the CSV registry format
+// Tags are separated by semicolons within their column
+var csvColumns = []string{"id", "name", "slug", "plan", "arr", "csm_owner", "region", "tags"}
+
+var (
+	// slugPattern matches valid slugs
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// slugSeparators matches runs of characters replaced by a dash when deriving a slug
+	slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Registry holds company profiles, persisted to a JSON or CSV file chosen by extension
+type Registry struct {
+	path string
+
+	mu          sync.RWMutex
+	profiles    map[string]models.CompanyProfile
+	subscribers []func(profile models.CompanyProfile)
+}
+
+// NewRegistry loads company profiles from path, starting empty if the file does not exist
+func NewRegistry(path string) (*Registry, error) {
+	registry := &Registry{
+		path:     path,
+		profiles: make(map[string]models.CompanyProfile),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read companies file: %w", err)
+	}
+
+	var profiles []models.CompanyProfile
+	if isCSV(path) {
+		profiles, err = parseCSV(data)
+	} else {
+		err = json.Unmarshal(data, &profiles)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse companies file: %w", err)
+	}
+
+	for i, profile := range profiles {
+		profile, err := normalize(profile.ID, requestFrom(profile))
+		if err != nil {
+			return nil, fmt.Errorf("invalid company at entry %d: %w", i+1, err)
+		}
+		if _, exists := registry.profiles[profile.ID]; exists {
+			return nil, fmt.Errorf("duplicate company %s in companies file", profile.ID)
+		}
+		profile.UpdatedAt = profiles[i].UpdatedAt
+		registry.profiles[profile.ID] = profile
+	}
+
+	return registry, nil
+}
+
+// Profile returns the registered profile of a company
+func (r *Registry) Profile(companyID string) (models.CompanyProfile, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	profile, ok := r.profiles[companyID]
+	return profile, ok
+}
+
+// Profiles returns every registered profile ordered by name
+func (r *Registry) Profiles() []models.CompanyProfile {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sortedLocked()
+}
+
+// Subscribe registers a function called with each saved profile
+func (r *Registry) Subscribe(subscriber func(profile models.CompanyProfile)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, subscriber)
+}
+
+// Update creates or replaces the profile of a company, saves the registry and notifies subscribers
+func (r *Registry) Update(companyID string, request models.CompanyProfileRequest) (models.CompanyProfile, error) {
+	profile, err := normalize(companyID, request)
+	if err != nil {
+		return models.CompanyProfile{}, err
+	}
+	profile.UpdatedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	for id, existing := range r.profiles {
+		if id != companyID && existing.Slug == profile.Slug {
+			r.mu.Unlock()
+			return models.CompanyProfile{}, ErrSlugTaken
+		}
+	}
+
+	previous, existed := r.profiles[companyID]
+	r.profiles[companyID] = profile
+	if err := r.saveLocked(); err != nil {
+		if existed {
+			r.profiles[companyID] = previous
+		} else {
+			delete(r.profiles, companyID)
+		}
+		r.mu.Unlock()
+		return models.CompanyProfile{}, err
+	}
+	subscribers := r.subscribers
+	r.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(profile)
+	}
+	return profile, nil
+}
+
+// ValidateProfile checks a profile request for a company
+func ValidateProfile(companyID string, request models.CompanyProfileRequest) error {
+	_, err := normalize(companyID, request)
+	return err
+}
+
+// normalize validates a profile request and fills in derived fields
+func normalize(companyID string, request models.CompanyProfileRequest) (models.CompanyProfile, error) {
+	profile := models.CompanyProfile{
+		ID:         strings.TrimSpace(companyID),
+		Name:       strings.TrimSpace(request.Name),
+		Slug:       strings.TrimSpace(request.Slug),
+		Plan:       strings.ToLower(strings.TrimSpace(request.Plan)),
+		ARR:        request.ARR,
+		CSMOwner:   strings.TrimSpace(request.CSMOwner),
+		Region:     strings.TrimSpace(request.Region),
+		Tags:       []string{},
+		Registered: true,
+	}
+
+	if profile.ID == "" {
+		return models.CompanyProfile{}, errors.New("id is required")
+	}
+	if profile.Name == "" {
+		return models.CompanyProfile{}, errors.New("name is required")
+	}
+	if profile.Slug == "" {
+		profile.Slug = Slugify(profile.Name)
+	}
+	if !slugPattern.MatchString(profile.Slug) {
+		return models.CompanyProfile{}, fmt.Errorf("invalid slug %q, use lowercase letters, digits and dashes", profile.Slug)
+	}
+	if profile.ARR < 0 {
+		return models.CompanyProfile{}, errors.New("arr must not be negative")
+	}
+
+	// Tags are matched case-insensitively, so they are stored lowercase and de-duplicated
+	for _, tag := range request.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(profile.Tags, tag) {
+			profile.Tags = append(profile.Tags, tag)
+		}
+	}
+	sort.Strings(profile.Tags)
+
+	return profile, nil
+}
+
+// Slugify derives a URL-friendly slug from a name: "Sample Company" -> "sample-company"
+func Slugify(name string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// requestFrom converts a stored profile back into a request for validation
+func requestFrom(profile models.CompanyProfile) models.CompanyProfileRequest {
+	return models.CompanyProfileRequest{
+		Name:     profile.Name,
+		Slug:     profile.Slug,
+		Plan:     profile.Plan,
+		ARR:      profile.ARR,
+		CSMOwner: profile.CSMOwner,
+		Region:   profile.Region,
+		Tags:     profile.Tags,
+	}
+}
+
+// sortedLocked returns the profiles ordered by name; caller must hold mu
+func (r *Registry) sortedLocked() []models.CompanyProfile {
+	profiles := make([]models.CompanyProfile, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Name != profiles[j].Name {
+			return profiles[i].Name < profiles[j].Name
+		}
+		return profiles[i].ID < profiles[j].ID
+	})
+	return profiles
+}
+
+// saveLocked writes the registry to disk atomically in the file's format; caller must hold mu
+func (r *Registry) saveLocked() error {
+	var data []byte
+	var err error
+	if isCSV(r.path) {
+		data, err = formatCSV(r.sortedLocked())
+	} else {
+		data, err = json.MarshalIndent(r.sortedLocked(), "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode companies: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create companies directory: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write companies file: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write companies file: %w", err)
+	}
+	return nil
+}
+
+// parseCSV reads profiles from CSV with a header row naming csvColumns in any order
+func parseCSV(data []byte) ([]models.CompanyProfile, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, errors.New("missing id column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	profiles := make([]models.CompanyProfile, 0, len(records)-1)
+	for row, record := range records[1:] {
+		profile := models.CompanyProfile{
+			ID:       field(record, "id"),
+			Name:     field(record, "name"),
+			Slug:     field(record, "slug"),
+			Plan:     field(record, "plan"),
+			CSMOwner: field(record, "csm_owner"),
+			Region:   field(record, "region"),
+		}
+		if arr := field(record, "arr"); arr != "" {
+			if profile.ARR, err = strconv.ParseFloat(arr, 64); err != nil {
+				return nil, fmt.Errorf("row %d: invalid arr %q", row+2, arr)
+			}
+		}
+		if tags := field(record, "tags"); tags != "" {
+			profile.Tags = strings.Split(tags, ";")
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// formatCSV writes profiles as CSV with a header row
+func formatCSV(profiles []models.CompanyProfile) ([]byte, error) {
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+	if err := writer.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		record := []string{
+			profile.ID,
+			profile.Name,
+			profile.Slug,
+			profile.Plan,
+			strconv.FormatFloat(profile.ARR, 'f', -1, 64),
+			profile.CSMOwner,
+			profile.Region,
+			strings.Join(profile.Tags, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return []byte(builder.String()), writer.Error()
+}
+
+// isCSV reports whether path uses the CSV format
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
@@ -0,0 +1,291 @@
+// Package config loads the server configuration from a YAML or TOML file,
+// environment variables and command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/retention"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// Config is the complete server configuration
+// Every setting can come from the config file, its env variable or its flag, named after the file keys
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Data      DataConfig      `yaml:"data" toml:"data"`
+	CORS      CORSConfig      `yaml:"cors" toml:"cors"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	Privacy   PrivacyConfig   `yaml:"privacy" toml:"privacy"`
+	Analytics AnalyticsConfig `yaml:"analytics" toml:"analytics"`
+	Cache     CacheConfig     `yaml:"cache" toml:"cache"`
+	Retention RetentionConfig `yaml:"retention" toml:"retention"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Audit     AuditConfig     `yaml:"audit" toml:"audit"`
+	Stream    StreamConfig    `yaml:"stream" toml:"stream"`
+	GraphQL   GraphQLConfig   `yaml:"graphql" toml:"graphql"`
+}
+
+// ServerConfig configures the HTTP listener
+type ServerConfig struct {
+	Port            int      `yaml:"port" toml:"port" env:"PORT" usage:"port to listen on"`
+	Timezone        string   `yaml:"timezone" toml:"timezone" env:"TIMEZONE" usage:"IANA timezone for today's date and report schedules, or Local"`
+	ReadTimeout     Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT" usage:"longest time to read a request, body included"`
+	WriteTimeout    Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" usage:"longest time to write a response; live streams are exempt"`
+	IdleTimeout     Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" usage:"how long an idle keep-alive connection stays open"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" usage:"longest time to drain requests and deliveries on shutdown"`
+	MaxHeaderKB     int      `yaml:"max_header_kb" toml:"max_header_kb" env:"SERVER_MAX_HEADER_KB" usage:"largest request header size in kilobytes"`
+	MaxBodyMB       int      `yaml:"max_body_mb" toml:"max_body_mb" env:"SERVER_MAX_BODY_MB" usage:"largest request body size in megabytes"`
+	TrustedProxies  []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES" usage:"comma-separated proxy IPs or CIDRs whose X-Forwarded-For is believed"`
+}
+
+// DataConfig locates the event source and the files the server keeps its state in
+type DataConfig struct {
+	Events       string `yaml:"events" toml:"events" env:"EVENTS_FILE" usage:"export of usage events: CSV, NDJSON or Parquet"`
+	EventsFormat string `yaml:"events_format" toml:"events_format" env:"EVENTS_FORMAT" usage:"format of the events file: csv, ndjson or parquet; empty picks it from the extension"`
+	Ingested     string `yaml:"ingested" toml:"ingested" env:"INGEST_JOURNAL_FILE" usage:"journal of events ingested through the API"`
+	Companies    string `yaml:"companies" toml:"companies" env:"COMPANIES_FILE" usage:"company registry"`
+	Credentials  string `yaml:"credentials" toml:"credentials" env:"AUTH_FILE" usage:"API keys and dashboard users"`
+	PrivacyKey   string `yaml:"privacy_key" toml:"privacy_key" env:"PRIVACY_KEY_FILE" usage:"key for email pseudonyms and audit digests, created if missing"`
+	Views        string `yaml:"views" toml:"views" env:"VIEWS_FILE" usage:"saved views"`
+	Reports      string `yaml:"reports" toml:"reports" env:"REPORTS_FILE" usage:"scheduled report definitions"`
+	ReportsDir   string `yaml:"reports_dir" toml:"reports_dir" env:"REPORTS_DIR" usage:"directory generated reports are written to"`
+	Alerts       string `yaml:"alerts" toml:"alerts" env:"ALERTS_FILE" usage:"alert rules and history"`
+	Webhooks     string `yaml:"webhooks" toml:"webhooks" env:"WEBHOOKS_FILE" usage:"webhook endpoints and deliveries"`
+	Retention    string `yaml:"retention" toml:"retention" env:"RETENTION_FILE" usage:"retention state and purge records"`
+}
+
+// CORSConfig lists the browser origins allowed to call the API
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" usage:"comma-separated origins allowed to call the API"`
+}
+
+// AuthConfig configures authentication
+type AuthConfig struct {
+	Disabled      bool     `yaml:"disabled" toml:"disabled" env:"AUTH_DISABLED" usage:"give every request admin access"`
+	SessionSecret string   `yaml:"session_secret" toml:"session_secret" env:"AUTH_SESSION_SECRET" usage:"secret signing dashboard sessions, random when empty"`
+	SessionTTL    Duration `yaml:"session_ttl" toml:"session_ttl" env:"AUTH_SESSION_TTL" usage:"dashboard session lifetime"`
+}
+
+// PrivacyConfig configures email pseudonymization
+type PrivacyConfig struct {
+	Policy string `yaml:"policy" toml:"policy" env:"PRIVACY_POLICY" usage:"email handling per role, such as rep=pseudonymize,viewer=hide"`
+}
+
+// AnalyticsConfig configures analytics queries
+type AnalyticsConfig struct {
+	DefaultDateRange int `yaml:"default_date_range" toml:"default_date_range" env:"DEFAULT_DATE_RANGE" usage:"days covered when a request gives no range"`
+}
+
+// CacheConfig configures the analytics response cache
+type CacheConfig struct {
+	Size int      `yaml:"size" toml:"size" env:"ANALYTICS_CACHE_SIZE" usage:"cached analytics responses, 0 disables caching"`
+	TTL  Duration `yaml:"ttl" toml:"ttl" env:"ANALYTICS_CACHE_TTL" usage:"how long a cached response is served"`
+}
+
+// RetentionConfig is the retention policy applied when none was set through the API
+type RetentionConfig struct {
+	RawEvents       string `yaml:"raw_events" toml:"raw_events" env:"RETENTION_RAW_EVENTS" usage:"raw event retention, such as 90d or 13mo; empty keeps forever"`
+	DailyAggregates string `yaml:"daily_aggregates" toml:"daily_aggregates" env:"RETENTION_DAILY_AGGREGATES" usage:"daily aggregate retention; empty keeps forever"`
+	MaxPurgePercent int    `yaml:"max_purge_percent" toml:"max_purge_percent" env:"RETENTION_MAX_PURGE_PERCENT" usage:"largest share of raw events a scheduled purge removes; larger ones need POST /api/retention/run?confirm=true"`
+}
+
+// RateLimitConfig limits API requests per client: its key or user once authenticated, otherwise its address
+type RateLimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute" toml:"requests_per_minute" env:"RATE_LIMIT_PER_MINUTE" usage:"sustained requests a minute per client, 0 disables limiting"`
+	Burst             int `yaml:"burst" toml:"burst" env:"RATE_LIMIT_BURST" usage:"requests a client can make at once"`
+}
+
+// AuditConfig configures the audit log
+type AuditConfig struct {
+	Log       string `yaml:"log" toml:"log" env:"AUDIT_LOG" usage:"audit log path"`
+	MaxSizeMB int    `yaml:"max_size_mb" toml:"max_size_mb" env:"AUDIT_MAX_SIZE_MB" usage:"size in megabytes at which the audit log rotates"`
+	MaxFiles  int    `yaml:"max_files" toml:"max_files" env:"AUDIT_MAX_FILES" usage:"rotated audit logs kept, 0 keeps all"`
+}
+
+// StreamConfig configures live analytics streams
+type StreamConfig struct {
+	Heartbeat Duration `yaml:"heartbeat" toml:"heartbeat" env:"STREAM_HEARTBEAT" usage:"interval of keep-alive comments on idle streams"`
+}
+
+// GraphQLConfig limits GraphQL queries
+type GraphQLConfig struct {
+	MaxDepth      int `yaml:"max_depth" toml:"max_depth" env:"GRAPHQL_MAX_DEPTH" usage:"deepest selection allowed"`
+	MaxComplexity int `yaml:"max_complexity" toml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" usage:"highest query cost allowed"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            8080,
+			Timezone:        "Local",
+			ReadTimeout:     Duration(30 * time.Second),
+			WriteTimeout:    Duration(60 * time.Second),
+			IdleTimeout:     Duration(120 * time.Second),
+			ShutdownTimeout: Duration(30 * time.Second),
+			MaxHeaderKB:     64,
+			MaxBodyMB:       16,
+		},
+		Data: DataConfig{
+			Events:      "./data/assembly-takehome2.csv",
+			Ingested:    "./data/ingested.ndjson",
+			Companies:   "./data/companies.json",
+			Credentials: "./data/auth.json",
+			PrivacyKey:  "./data/privacy.key",
+			Views:       "./data/views.json",
+			Reports:     "./data/reports.json",
+			ReportsDir:  "./reports",
+			Alerts:      "./data/alerts.json",
+			Webhooks:    "./data/webhooks.json",
+			Retention:   "./data/retention.json",
+		},
+		CORS: CORSConfig{AllowedOrigins: []string{
+			"http://localhost:3000",
+			"http://localhost:5173",
+			"http://127.0.0.1:3000",
+			"http://127.0.0.1:5173",
+		}},
+		Auth:      AuthConfig{SessionTTL: Duration(12 * time.Hour)},
+		Analytics: AnalyticsConfig{DefaultDateRange: 30},
+		Cache:     CacheConfig{Size: 256, TTL: Duration(5 * time.Minute)},
+		RateLimit: RateLimitConfig{RequestsPerMinute: 600, Burst: 100},
+		Audit:     AuditConfig{Log: "./data/audit/audit.log", MaxSizeMB: 10, MaxFiles: 10},
+		Retention: RetentionConfig{MaxPurgePercent: retention.DefaultMaxPurgePercent},
+		Stream:    StreamConfig{Heartbeat: Duration(15 * time.Second)},
+		GraphQL:   GraphQLConfig{MaxDepth: 8, MaxComplexity: 1000},
+	}
+}
+
+// Validate reports every invalid setting at once, named by its file key
+func (c Config) Validate() error {
+	var problems []error
+	check := func(key string, ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check("server.port", c.Server.Port > 0 && c.Server.Port <= 65535, "must be between 1 and 65535")
+	if _, err := time.LoadLocation(c.Server.Timezone); err != nil {
+		check("server.timezone", false, "unknown timezone %q", c.Server.Timezone)
+	}
+	check("server.read_timeout", c.Server.ReadTimeout > 0, "must be a positive duration")
+	check("server.write_timeout", c.Server.WriteTimeout > 0, "must be a positive duration")
+	check("server.idle_timeout", c.Server.IdleTimeout > 0, "must be a positive duration")
+	check("server.shutdown_timeout", c.Server.ShutdownTimeout > 0, "must be a positive duration")
+	check("server.max_header_kb", c.Server.MaxHeaderKB > 0, "must be a positive number of kilobytes")
+	check("server.max_body_mb", c.Server.MaxBodyMB > 0, "must be a positive number of megabytes")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check("server.trusted_proxies", cidrErr == nil || net.ParseIP(proxy) != nil, "invalid proxy %q, expected an IP or CIDR", proxy)
+	}
+
+	paths := map[string]string{
+		"data.events":      c.Data.Events,
+		"data.ingested":    c.Data.Ingested,
+		"data.companies":   c.Data.Companies,
+		"data.credentials": c.Data.Credentials,
+		"data.privacy_key": c.Data.PrivacyKey,
+		"data.views":       c.Data.Views,
+		"data.reports":     c.Data.Reports,
+		"data.reports_dir": c.Data.ReportsDir,
+		"data.alerts":      c.Data.Alerts,
+		"data.webhooks":    c.Data.Webhooks,
+		"data.retention":   c.Data.Retention,
+		"audit.log":        c.Audit.Log,
+	}
+	for _, key := range slices.Sorted(maps.Keys(paths)) {
+		check(key, strings.TrimSpace(paths[key]) != "", "path is required")
+	}
+
+	if _, err := utils.NewEventSource(c.Data.Events, c.Data.EventsFormat); err != nil {
+		// Without a format, the file's extension is what is wrong
+		key := "data.events_format"
+		if c.Data.EventsFormat == "" {
+			key = "data.events"
+		}
+		check(key, false, "%v", err)
+	}
+
+	check("cors.allowed_origins", len(c.CORS.AllowedOrigins) > 0, "at least one origin is required")
+	for _, origin := range c.CORS.AllowedOrigins {
+		parsed, err := url.Parse(origin)
+		valid := err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && strings.Trim(parsed.Path, "/") == ""
+		check("cors.allowed_origins", valid || origin == "*", "invalid origin %q, expected scheme://host[:port]", origin)
+	}
+
+	check("auth.session_ttl", c.Auth.SessionTTL > 0, "must be a positive duration")
+	if _, err := privacy.ParsePolicy(c.Privacy.Policy); err != nil {
+		check("privacy.policy", false, "%v", err)
+	}
+
+	check("analytics.default_date_range", c.Analytics.DefaultDateRange > 0 && c.Analytics.DefaultDateRange <= 365, "must be between 1 and 365 days")
+	check("cache.size", c.Cache.Size >= 0, "must be zero or a positive number of responses")
+	check("cache.ttl", c.Cache.TTL > 0, "must be a positive duration")
+
+	if _, err := retention.ParsePeriod(c.Retention.RawEvents); err != nil {
+		check("retention.raw_events", false, "%v", err)
+	}
+	if _, err := retention.ParsePeriod(c.Retention.DailyAggregates); err != nil {
+		check("retention.daily_aggregates", false, "%v", err)
+	}
+	check("retention.max_purge_percent", c.Retention.MaxPurgePercent > 0 && c.Retention.MaxPurgePercent <= 100, "must be between 1 and 100")
+
+	check("rate_limit.requests_per_minute", c.RateLimit.RequestsPerMinute >= 0, "must be zero or a positive number of requests")
+	check("rate_limit.burst", c.RateLimit.Burst > 0, "must be a positive number of requests")
+	check("audit.max_size_mb", c.Audit.MaxSizeMB > 0, "must be a positive number of megabytes")
+	check("audit.max_files", c.Audit.MaxFiles >= 0, "must be zero or a positive number of files")
+	check("stream.heartbeat", c.Stream.Heartbeat > 0, "must be a positive duration")
+	check("graphql.max_depth", c.GraphQL.MaxDepth > 0, "must be a positive number")
+	check("graphql.max_complexity", c.GraphQL.MaxComplexity > 0, "must be a positive number")
+
+	return errors.Join(problems...)
+}
+
+// Location returns the configured timezone; call after Validate
+func (c Config) Location() *time.Location {
+	location, err := time.LoadLocation(c.Server.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// Redacted returns a copy safe to print, with secrets masked
+func (c Config) Redacted() Config {
+	if c.Auth.SessionSecret != "" {
+		c.Auth.SessionSecret = "<redacted>"
+	}
+	return c
+}
+
+// Duration is a time.Duration written as text, such as "90s" or "12h"
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText writes the duration the way UnmarshalText reads it
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Std returns the duration as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
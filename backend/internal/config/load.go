@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Options are the command-line switches that control loading rather than the server
+type Options struct {
+	// File is the config file read, empty when none was given
+	File string
+	// PrintConfig asks for the effective configuration to be printed instead of serving
+	PrintConfig bool
+}
+
+// setting is one leaf of the configuration with the names it is set by
+type setting struct {
+	key   string // dotted file key, such as cache.ttl
+	flag  string // flag name, the key with dashes for underscores
+	env   string
+	usage string
+	value reflect.Value
+}
+
+// Load builds the configuration from defaults, the config file, env variables and flags, each
+// overriding the one before, and validates it
+// The file is named by -config or CONFIG_FILE; its extension picks YAML or TOML
+func Load(name string, args []string) (Config, Options, error) {
+	cfg := Default()
+	var options Options
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&options.File, "config", os.Getenv("CONFIG_FILE"), "YAML or TOML config `file`")
+	flags.BoolVar(&options.PrintConfig, "print-config", false, "print the effective configuration and exit")
+
+	// Flags are only recorded while parsing so they can be applied after the file and env
+	settings := settingsOf(&cfg)
+	flagged := make(map[string]string)
+	for _, s := range settings {
+		record := func(raw string) error {
+			if err := setValue(reflect.New(s.value.Type()).Elem(), raw); err != nil {
+				return err
+			}
+			flagged[s.key] = raw
+			return nil
+		}
+		usage := fmt.Sprintf("%s (env %s, default %s)", s.usage, s.env, formatValue(s.value))
+		if s.value.Kind() == reflect.Bool {
+			flags.BoolFunc(s.flag, usage, record)
+		} else {
+			flags.Func(s.flag, usage, record)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return cfg, options, err
+	}
+	if flags.NArg() > 0 {
+		return cfg, options, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if options.File != "" {
+		if err := decodeFile(options.File, &cfg); err != nil {
+			return cfg, options, fmt.Errorf("config file %s: %w", options.File, err)
+		}
+	}
+
+	if err := applyEnv(settings); err != nil {
+		return cfg, options, err
+	}
+
+	for _, s := range settings {
+		if raw, ok := flagged[s.key]; ok {
+			// Already checked while parsing
+			setValue(s.value, raw)
+		}
+	}
+
+	return cfg, options, cfg.Validate()
+}
+
+// FromFile builds the configuration from defaults, the config file at path if any, and env
+// variables, and validates it
+// Command-line tools use it to read the same data sources as the server
+func FromFile(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := decodeFile(path, &cfg); err != nil {
+			return cfg, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(settingsOf(&cfg)); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// applyEnv sets every setting whose env variable is set and not empty
+func applyEnv(settings []setting) error {
+	for _, s := range settings {
+		raw, ok := os.LookupEnv(s.env)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := setValue(s.value, raw); err != nil {
+			return fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+	return nil
+}
+
+// decodeFile reads a YAML or TOML file over cfg, rejecting unknown keys so typos are caught
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported format %q, expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	return nil
+}
+
+// Print writes the configuration as YAML, with secrets masked
+func (c Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// settingsOf lists the leaves of cfg in declaration order
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key := prefix + field.Tag.Get("yaml")
+			if field.Tag.Get("env") == "" {
+				walk(key+".", v.Field(i))
+				continue
+			}
+			settings = append(settings, setting{
+				key:   key,
+				flag:  strings.ReplaceAll(key, "_", "-"),
+				env:   field.Tag.Get("env"),
+				usage: field.Tag.Get("usage"),
+				value: v.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return settings
+}
+
+// setValue parses raw into a setting the way env variables and flags are written
+// Lists are comma-separated
+func setValue(v reflect.Value, raw string) error {
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// formatValue writes a default value for flag usage
+func formatValue(v reflect.Value) string {
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, _ := marshaler.MarshalText()
+		return string(text)
+	}
+	if v.Kind() == reflect.Slice {
+		return strconv.Quote(strings.Join(v.Interface().([]string), ","))
+	}
+	if v.Kind() == reflect.String {
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a config file into a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	files := map[string]string{
+		"server.yaml": `
+server:
+  port: 9001
+  max_body_mb: 4
+  read_timeout: 10s
+graphql:
+  max_depth: 7
+`,
+		"server.toml": `
+[server]
+port = 9001
+max_body_mb = 4
+read_timeout = "10s"
+
+[graphql]
+max_depth = 7
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, name, content)
+			t.Setenv("PORT", "9002")
+			t.Setenv("GRAPHQL_MAX_DEPTH", "8")
+			// An empty variable leaves the file's value in place
+			t.Setenv("SERVER_READ_TIMEOUT", "")
+
+			cfg, options, err := Load("server", []string{"-config", path, "-server.port", "9003"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if options.File != path {
+				t.Errorf("options.File = %q, want %q", options.File, path)
+			}
+
+			tests := []struct {
+				name      string
+				got, want any
+			}{
+				{"flag over env and file", cfg.Server.Port, 9003},
+				{"env over file", cfg.GraphQL.MaxDepth, 8},
+				{"file over default", cfg.Server.MaxBodyMB, 4},
+				{"empty env keeps the file", cfg.Server.ReadTimeout, Duration(10 * time.Second)},
+				{"default", cfg.Server.MaxHeaderKB, Default().Server.MaxHeaderKB},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "server.yaml", "server:\n  port: 9001\n"))
+
+	cfg, _, err := Load("server", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 9001 {
+		t.Errorf("port = %d, want 9001 from the file named by CONFIG_FILE", cfg.Server.Port)
+	}
+}
+
+func TestLoadRejectsBadInput(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"unknown file key", "server:\n  prot: 9001\n", nil, nil, "prot"},
+		{"bad env value", "", map[string]string{"PORT": "eighty"}, nil, "PORT"},
+		{"bad flag value", "", nil, []string{"-server.port", "eighty"}, "invalid number"},
+		{"invalid result", "", nil, []string{"-server.timezone", "Mars/Olympus"}, "unknown timezone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "server.yaml", tt.file)}, args...)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, _, err := Load("server", args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// formulaCells are event contents a spreadsheet would evaluate, with the cells an export must hold
+var formulaCells = []struct {
+	content string
+	want    string
+}{
+	{"=HYPERLINK(\"http://evil.example\",\"open\")", "'=HYPERLINK(\"http://evil.example\",\"open\")"},
+	{"+1+cmd|' /C calc'!A0", "'+1+cmd|' /C calc'!A0"},
+	{"-2+3", "'-2+3"},
+	{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+	{"\t=1+1", "'\t=1+1"},
+	{"-2.5", "-2.5"},
+	{"+7", "+7"},
+	{"Login - Sample Company a@b.com /home", "Login - Sample Company a@b.com /home"},
+}
+
+func formulaTable() Table {
+	rows := make([][]interface{}, len(formulaCells))
+	for i, cell := range formulaCells {
+		rows[i] = []interface{}{cell.content, -3}
+	}
+	return staticTable("Events", []string{"content", "count"}, rows)
+}
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Table{formulaTable()}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, cell := range formulaCells {
+		if got := records[i+1][0]; got != cell.want {
+			t.Errorf("cell %q written as %q, want %q", cell.content, got, cell.want)
+		}
+		if got := records[i+1][1]; got != "-3" {
+			t.Errorf("number written as %q, want -3", got)
+		}
+	}
+}
+
+func TestWriteXLSXEscapesFormulas(t *testing.T) {
+	sheet := xlsxPart(t, []Table{formulaTable()}, "xl/worksheets/sheet1.xml")
+	for _, cell := range formulaCells {
+		if want := ">" + escapeXML(cell.want) + "<"; !strings.Contains(sheet, want) {
+			t.Errorf("sheet does not contain %q", want)
+		}
+	}
+	if !strings.Contains(sheet, "<v>-3</v>") {
+		t.Error("negative number is not a numeric cell")
+	}
+}
+
+func TestSheetNames(t *testing.T) {
+	long := strings.Repeat("é", 40)
+	names := sheetNames([]Table{{Name: long}, {Name: long}, {Name: "a/b"}, {Name: ""}})
+
+	for _, name := range names {
+		if !utf8.ValidString(name) {
+			t.Errorf("sheet name %q is not valid UTF-8", name)
+		}
+		if n := utf8.RuneCountInString(name); n > maxSheetName {
+			t.Errorf("sheet name %q has %d characters", name, n)
+		}
+	}
+	want := []string{strings.Repeat("é", 31), strings.Repeat("é", 27) + " (2)", "a_b", "Sheet4"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("sheet %d named %q, want %q", i+1, names[i], want[i])
+		}
+	}
+
+	workbook := xlsxPart(t, []Table{{Name: long, Header: []string{"a"}, Rows: func(func([]interface{}) bool) {}}}, "xl/workbook.xml")
+	if !strings.Contains(workbook, `name="`+want[0]+`"`) {
+		t.Errorf("workbook does not name the sheet %q:\n%s", want[0], workbook)
+	}
+}
+
+// xlsxPart writes tables as a workbook and returns one of its files
+func xlsxPart(t *testing.T, tables []Table, path string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, tables); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := archive.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Table is a named, tabular section of an export
+// Rows is an iterator so large tables can be streamed without buffering
+type Table struct {
+	Name   string
+	Header []string
+	Rows   iter.Seq[[]interface{}]
+}
+
+// AnalyticsTables converts an analytics response into exportable tables
+func AnalyticsTables(response models.AnalyticsResponse) []Table {
+	return []Table{
+		SummaryTable(response.Summary),
+		TrendsTable(response.Trends),
+		CompaniesTable(response.Companies),
+		TopUsersTable(response.TopUsers),
+	}
+}
+
+// SummaryTable renders dashboard summary metrics as metric/value rows
+func SummaryTable(summary models.DashboardSummary) Table {
+	return staticTable("Summary", []string{"Metric", "Value"}, [][]interface{}{
+		{"Total Events", summary.TotalEvents},
+		{"Total Companies", summary.TotalCompanies},
+		{"Peak Usage Day", summary.PeakUsageDay},
+	})
+}
+
+// TrendsTable renders per-company daily trends
+func TrendsTable(trends models.UsageTrends) Table {
+	// Sort company keys for deterministic output
+	companies := make([]string, 0, len(trends.Trends))
+	for company := range trends.Trends {
+		companies = append(companies, company)
+	}
+	sort.Strings(companies)
+
+	var rows [][]interface{}
+	for _, company := range companies {
+		for _, trend := range trends.Trends[company] {
+			rows = append(rows, []interface{}{company, trend.Date, trend.Events})
+		}
+	}
+
+	return staticTable("Trends", []string{"Company", "Date", "Events"}, rows)
+}
+
+// CompaniesTable renders company metrics
+func CompaniesTable(companies []models.Company) Table {
+	rows := make([][]interface{}, 0, len(companies))
+	for _, company := range companies {
+		rows = append(rows, []interface{}{
+			company.ID,
+			company.Name,
+			company.EventCount,
+			company.ActiveUsers,
+			company.LastActivity,
+		})
+	}
+
+	return staticTable("Companies", []string{"Company ID", "Name", "Events", "Active Users", "Last Activity"}, rows)
+}
+
+// TopUsersTable renders the top users ranking
+func TopUsersTable(users []models.UserActivity) Table {
+	rows := make([][]interface{}, 0, len(users))
+	for i, user := range users {
+		rows = append(rows, []interface{}{i + 1, user.Email, user.CompanyName, user.EventCount})
+	}
+
+	return staticTable("Top Users", []string{"Rank", "Email", "Company", "Events"}, rows)
+}
+
+// EventsTable renders raw events, reading them lazily from the iterator
+func EventsTable(events iter.Seq[models.UsageEvent]) Table {
+	return Table{
+		Name: "Events",
+		Header: []string{
+			"id", "created_at", "company_id", "type", "content",
+			"attribute", "updated_at", "original_timestamp", "value",
+		},
+		Rows: func(yield func([]interface{}) bool) {
+			for event := range events {
+				value := ""
+				if event.Value != nil {
+					value = *event.Value
+				}
+				row := []interface{}{
+					event.ID, event.CreatedAt, event.CompanyID, event.Type, event.Content,
+					event.Attribute, event.UpdatedAt, event.OriginalTimestamp, value,
+				}
+				if !yield(row) {
+					return
+				}
+			}
+		},
+	}
+}
+
+// staticTable builds a table from in-memory rows
+func staticTable(name string, header []string, rows [][]interface{}) Table {
+	return Table{
+		Name:   name,
+		Header: header,
+		Rows: func(yield func([]interface{}) bool) {
+			for _, row := range rows {
+				if !yield(row) {
+					return
+				}
+			}
+		},
+	}
+}
+
+// formatCell converts a cell value to its text representation
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// spreadsheetCell formats a cell for CSV and XLSX, where text starting with =, +, - or @
+// is prefixed with ' so spreadsheets do not evaluate it as a formula
+// Numbers written as text, such as "-2.5", are left as they are
+func spreadsheetCell(value interface{}) string {
+	text := formatCell(value)
+	if text == "" || !strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return text
+	}
+	if number, err := strconv.ParseFloat(text, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+		return text
+	}
+	return "'" + text
+}
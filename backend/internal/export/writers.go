@@ -0,0 +1,132 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Supported export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+// ContentType returns the MIME type for an export format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// IsFormat reports whether format is a supported export format
+func IsFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON || format == FormatXLSX
+}
+
+// WriteCSV writes tables as CSV sections
+// A single table is written as plain CSV; multiple tables are separated by
+// a title row and a blank line so spreadsheets still open the file cleanly
+func WriteCSV(w io.Writer, tables []Table) error {
+	writer := csv.NewWriter(w)
+
+	for i, table := range tables {
+		if len(tables) > 1 {
+			if i > 0 {
+				if err := writer.Write([]string{}); err != nil {
+					return err
+				}
+			}
+			if err := writer.Write([]string{table.Name}); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(table.Header); err != nil {
+			return err
+		}
+
+		record := make([]string, len(table.Header))
+		for row := range table.Rows {
+			for j := range record {
+				record[j] = ""
+				if j < len(row) {
+					record[j] = spreadsheetCell(row[j])
+				}
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSONRows streams tables as a JSON object keyed by table name,
+// each holding an array of header-keyed objects
+func WriteJSONRows(w io.Writer, tables []Table) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+
+	for i, table := range tables {
+		name, _ := json.Marshal(table.Name)
+		separator := ""
+		if i > 0 {
+			separator = ","
+		}
+		if _, err := fmt.Fprintf(w, "%s%s:[", separator, name); err != nil {
+			return err
+		}
+
+		first := true
+		for row := range table.Rows {
+			object := make(map[string]interface{}, len(table.Header))
+			for j, column := range table.Header {
+				if j < len(row) {
+					object[column] = jsonCell(row[j])
+				}
+			}
+			encoded, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := w.Write(encoded); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(w, "]"); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// jsonCell keeps numbers as numbers and formats times consistently
+func jsonCell(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		return formatCell(t)
+	}
+	return value
+}
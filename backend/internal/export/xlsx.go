@@ -0,0 +1,208 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WriteXLSX writes tables as an Office Open XML workbook with one sheet per table
+// Sheets are streamed straight into the zip archive, so rows are never buffered
+func WriteXLSX(w io.Writer, tables []Table) error {
+	archive := zip.NewWriter(w)
+
+	names := sheetNames(tables)
+
+	parts := []struct {
+		path    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML(len(tables))},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbookXML(names)},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML(len(tables))},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, part := range parts {
+		file, err := archive.Create(part.path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	for i, table := range tables {
+		file, err := archive.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeSheet(file, table); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// writeSheet streams a single worksheet
+func writeSheet(w io.Writer, table Table) error {
+	buffered := bufio.NewWriter(w)
+
+	buffered.WriteString(xml.Header)
+	buffered.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(table.Header))
+	for i, column := range table.Header {
+		header[i] = column
+	}
+	writeRow(buffered, 1, header)
+
+	rowNum := 2
+	for row := range table.Rows {
+		writeRow(buffered, rowNum, row)
+		rowNum++
+	}
+
+	buffered.WriteString(`</sheetData></worksheet>`)
+	return buffered.Flush()
+}
+
+// writeRow writes a sheet row with numbers as numeric cells and everything else as inline strings
+func writeRow(w *bufio.Writer, rowNum int, cells []interface{}) {
+	fmt.Fprintf(w, `<row r="%d">`, rowNum)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(rowNum)
+		switch v := cell.(type) {
+		case int:
+			fmt.Fprintf(w, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int64:
+			fmt.Fprintf(w, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case float64:
+			fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			text := spreadsheetCell(v)
+			if text == "" {
+				continue
+			}
+			fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escapeXML(text))
+		}
+	}
+	w.WriteString(`</row>`)
+}
+
+// columnName converts a zero-based column index to a spreadsheet column name (A, B, ..., AA)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escapeXML escapes text and drops characters that are invalid in XML
+func escapeXML(text string) string {
+	var builder strings.Builder
+	xml.EscapeText(&builder, []byte(strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, text)))
+	return builder.String()
+}
+
+// maxSheetName is the longest sheet name spreadsheets accept, in characters
+const maxSheetName = 31
+
+// truncateRunes cuts text to at most n characters without splitting a multi-byte character
+func truncateRunes(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return string([]rune(text)[:n])
+}
+
+// sheetNames returns unique, spreadsheet-safe sheet names for the tables
+func sheetNames(tables []Table) []string {
+	names := make([]string, len(tables))
+	seen := make(map[string]bool)
+
+	for i, table := range tables {
+		name := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`[]:*?/\`, r) {
+				return '_'
+			}
+			return r
+		}, table.Name)
+		if name == "" {
+			name = fmt.Sprintf("Sheet%d", i+1)
+		}
+		name = truncateRunes(name, maxSheetName)
+		for base, n := name, 2; seen[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			name = truncateRunes(base, maxSheetName-len(suffix)) + suffix
+		}
+		seen[strings.ToLower(name)] = true
+		names[i] = name
+	}
+
+	return names
+}
+
+func contentTypesXML(sheets int) string {
+	var builder strings.Builder
+	builder.WriteString(xml.Header)
+	builder.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	builder.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	builder.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	builder.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	builder.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&builder, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	builder.WriteString(`</Types>`)
+	return builder.String()
+}
+
+func workbookXML(names []string) string {
+	var builder strings.Builder
+	builder.WriteString(xml.Header)
+	builder.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range names {
+		fmt.Fprintf(&builder, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(name), i+1, i+1)
+	}
+	builder.WriteString(`</sheets></workbook>`)
+	return builder.String()
+}
+
+func workbookRelsXML(sheets int) string {
+	var builder strings.Builder
+	builder.WriteString(xml.Header)
+	builder.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&builder, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&builder, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	builder.WriteString(`</Relationships>`)
+	return builder.String()
+}
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs>` +
+	`</styleSheet>`
@@ -0,0 +1,398 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Error codes reported in error extensions
+const (
+	CodeSyntax     = "GRAPHQL_PARSE_FAILED"
+	CodeValidation = "GRAPHQL_VALIDATION_FAILED"
+	CodeComplexity = "QUERY_TOO_COMPLEX"
+)
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Response is the body of a GraphQL response
+// Data is omitted when the request failed before execution
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error with the location and path it applies to
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Location is a line and column in the query text, both starting at 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Limits bound the queries a schema executes; zero disables a limit
+type Limits struct {
+	// MaxDepth is the deepest selection nesting allowed
+	MaxDepth int
+	// MaxComplexity is the highest total field cost allowed, see Field.Cost and Field.ListSize
+	MaxComplexity int
+}
+
+// Execute parses, validates and runs a query
+// Requests that fail to parse, fail validation or exceed limits are rejected before any resolver runs
+func (s *Schema) Execute(ctx context.Context, request Request, limits Limits) *Response {
+	src := source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		syntaxErr := convertError(gqlerrors.FormatError(err), CodeSyntax)
+		// graphql-go follows the message with an excerpt of the query
+		syntaxErr.Message, _, _ = strings.Cut(syntaxErr.Message, "\n")
+		return &Response{Errors: []*Error{syntaxErr}}
+	}
+
+	if result := gql.ValidateDocument(&s.schema, doc, nil); !result.IsValid {
+		errs := make([]*Error, len(result.Errors))
+		for i, err := range result.Errors {
+			errs[i] = convertError(err, CodeValidation)
+		}
+		return &Response{Errors: errs}
+	}
+
+	op, fragments, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{validationError(err.Error())}}
+	}
+	variables := variableValues(op, request.Variables)
+
+	v := &validator{schema: s, source: src, fragments: fragments, variables: variables, limits: limits, costs: make(map[fragmentUse]int)}
+	v.selectionSet(s.query, op.SelectionSet, 1, make(map[string]bool))
+	if len(v.errors) > 0 {
+		return &Response{Errors: v.errors}
+	}
+	if v.tooComplex {
+		return &Response{Errors: []*Error{{
+			Message:    fmt.Sprintf("query complexity exceeds the limit of %d; select fewer fields or pass smaller limit arguments", limits.MaxComplexity),
+			Locations:  []Location{locate(src, op.Loc)},
+			Extensions: map[string]any{"code": CodeComplexity, "limit": limits.MaxComplexity},
+		}}}
+	}
+
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+	// Without data or a path, errors come from coercing variables before any resolver ran
+	code := ""
+	if result.Data == nil && !slices.ContainsFunc(result.Errors, func(err gqlerrors.FormattedError) bool { return len(err.Path) > 0 }) {
+		code = CodeValidation
+	}
+	response := &Response{}
+	for _, err := range result.Errors {
+		response.Errors = append(response.Errors, convertError(err, code))
+	}
+	if code != "" {
+		return response
+	}
+	if data, ok := result.Data.(map[string]any); ok {
+		o := &orderer{fragments: fragments, variables: variables}
+		response.Data = o.selectionSet(op.SelectionSet, data)
+	} else {
+		// A failed non-null root field nulls the whole result, which is still reported
+		response.Data = json.RawMessage("null")
+	}
+	return response
+}
+
+// selectOperation picks the operation to run by name, or the only one, and indexes the fragments
+func selectOperation(doc *ast.Document, name string) (*ast.OperationDefinition, map[string]*ast.FragmentDefinition, error) {
+	var op *ast.OperationDefinition
+	operations := 0
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			operations++
+			if name == "" || (definition.Name != nil && definition.Name.Value == name) {
+				op = definition
+			}
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		}
+	}
+
+	switch {
+	case op == nil:
+		return nil, nil, fmt.Errorf("unknown operation %q", name)
+	case name == "" && operations > 1:
+		return nil, nil, fmt.Errorf("operationName is required when the document has several operations")
+	case op.Operation != ast.OperationTypeQuery:
+		return nil, nil, fmt.Errorf("%s operations are not supported", op.Operation)
+	}
+	return op, fragments, nil
+}
+
+// variableValues returns the provided variables with the operation's defaults filled in
+// Values are as decoded from JSON; graphql-go coerces them again when executing
+func variableValues(op *ast.OperationDefinition, provided map[string]any) map[string]any {
+	variables := make(map[string]any, len(op.VariableDefinitions))
+	for _, definition := range op.VariableDefinitions {
+		name := definition.Variable.Name.Value
+		if value, ok := provided[name]; ok {
+			variables[name] = value
+		} else if definition.DefaultValue != nil {
+			variables[name] = literalValue(definition.DefaultValue, nil)
+		}
+	}
+	return variables
+}
+
+// literalValue returns the Int or Boolean value of an argument, substituting variables
+// Other values are not needed before execution and return nil
+func literalValue(value ast.Value, variables map[string]any) any {
+	switch value := value.(type) {
+	case *ast.Variable:
+		switch v := variables[value.Name.Value].(type) {
+		case float64:
+			// JSON numbers decode as float64
+			if v == float64(int(v)) {
+				return int(v)
+			}
+		case int, bool:
+			return v
+		}
+	case *ast.IntValue:
+		var n int
+		if _, err := fmt.Sscan(value.Value, &n); err == nil {
+			return n
+		}
+	case *ast.BooleanValue:
+		return value.Value
+	}
+	return nil
+}
+
+// included evaluates @skip and @include, which validation has checked
+func included(directives []*ast.Directive, variables map[string]any) bool {
+	for _, d := range directives {
+		if d.Name.Value != "skip" && d.Name.Value != "include" {
+			continue
+		}
+		for _, argument := range d.Arguments {
+			if condition, ok := literalValue(argument.Value, variables).(bool); ok && condition == (d.Name.Value == "skip") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// convertError converts a graphql-go error, tagging it with code unless code is empty
+func convertError(err gqlerrors.FormattedError, code string) *Error {
+	converted := &Error{Message: err.Message, Path: err.Path, Extensions: err.Extensions}
+	for _, l := range err.Locations {
+		converted.Locations = append(converted.Locations, Location{Line: l.Line, Column: l.Column})
+	}
+	if code != "" {
+		if converted.Extensions == nil {
+			converted.Extensions = make(map[string]any)
+		}
+		converted.Extensions["code"] = code
+	}
+	return converted
+}
+
+// validationError builds an error reported before execution
+func validationError(message string, locations ...Location) *Error {
+	return &Error{Message: message, Locations: locations, Extensions: map[string]any{"code": CodeValidation}}
+}
+
+// locate converts a node's position in the query to a line and column
+func locate(src *source.Source, loc *ast.Location) Location {
+	if loc == nil {
+		return Location{}
+	}
+	l := location.GetLocation(src, loc.Start)
+	return Location{Line: l.Line, Column: l.Column}
+}
+
+// orderer rebuilds executed data in selection order, which graphql-go's maps lose
+type orderer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// selectionSet orders the fields of one object
+func (o *orderer) selectionSet(set *ast.SelectionSet, data map[string]any) *orderedMap {
+	result := &orderedMap{values: make(map[string]any, len(data))}
+	keys, grouped := o.collectFields(set, nil, make(map[string][]*ast.Field), make(map[string]bool))
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		// Fields selected several times under one key merge their selections
+		merged := &ast.SelectionSet{}
+		for _, f := range grouped[key] {
+			if f.SelectionSet != nil {
+				merged.Selections = append(merged.Selections, f.SelectionSet.Selections...)
+			}
+		}
+		result.set(key, o.value(merged, value))
+	}
+	return result
+}
+
+// value orders an object, or the objects in a list, and returns other values unchanged
+func (o *orderer) value(set *ast.SelectionSet, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return o.selectionSet(set, v)
+	case []any:
+		ordered := make([]any, len(v))
+		for i, item := range v {
+			ordered[i] = o.value(set, item)
+		}
+		return ordered
+	}
+	return value
+}
+
+// collectFields groups the selected fields by response key, expanding each fragment once
+func (o *orderer) collectFields(set *ast.SelectionSet, keys []string, grouped map[string][]*ast.Field, visited map[string]bool) ([]string, map[string][]*ast.Field) {
+	if set == nil {
+		return keys, grouped
+	}
+	for _, s := range set.Selections {
+		switch s := s.(type) {
+		case *ast.Field:
+			if !included(s.Directives, o.variables) {
+				continue
+			}
+			key := responseKey(s)
+			if _, seen := grouped[key]; !seen {
+				keys = append(keys, key)
+			}
+			grouped[key] = append(grouped[key], s)
+		case *ast.FragmentSpread:
+			if fragment, ok := o.fragments[s.Name.Value]; ok && !visited[s.Name.Value] && included(s.Directives, o.variables) {
+				visited[s.Name.Value] = true
+				keys, grouped = o.collectFields(fragment.SelectionSet, keys, grouped, visited)
+			}
+		case *ast.InlineFragment:
+			if included(s.Directives, o.variables) {
+				keys, grouped = o.collectFields(s.SelectionSet, keys, grouped, visited)
+			}
+		}
+	}
+	return keys, grouped
+}
+
+// responseKey is a field's alias, or its name
+func responseKey(f *ast.Field) string {
+	if f.Alias != nil && f.Alias.Value != "" {
+		return f.Alias.Value
+	}
+	return f.Name.Value
+}
+
+// defaultResolve reads a map key or struct field named like the GraphQL field
+// Struct fields match by JSON name, or by Go name ignoring case when they have none
+func defaultResolve(source any, name string) any {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		if item := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); item.IsValid() {
+			return item.Interface()
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			structField := v.Type().Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+			jsonName, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+			if jsonName == name || (jsonName == "" && strings.EqualFold(structField.Name, name)) {
+				return v.Field(i).Interface()
+			}
+			// Embedded structs without a JSON name promote their fields, as in encoding/json
+			if structField.Anonymous && jsonName == "" {
+				if promoted := defaultResolve(v.Field(i).Interface(), name); promoted != nil {
+					return promoted
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// orderedMap is a JSON object that keeps the order fields were selected in
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON writes the fields in selection order
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
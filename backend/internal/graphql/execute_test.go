@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSchema has a costly list of items, each with a costly list of children that refer back to an item
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	child := &Object{
+		Name:   "Child",
+		Fields: []*Field{{Name: "name", Type: String, Cost: 1}},
+	}
+	item := &Object{
+		Name: "Item",
+		Fields: []*Field{
+			{Name: "id", Type: &NonNull{Of: ID}},
+			{Name: "name", Type: String},
+			{Name: "children", Type: &List{Of: child}, Cost: 5, ListSize: 20},
+		},
+	}
+	child.Fields = append(child.Fields, &Field{Name: "parent", Type: item})
+	query := &Object{
+		Name: "Query",
+		Fields: []*Field{
+			{
+				Name:     "items",
+				Type:     &List{Of: item},
+				Args:     []*Argument{{Name: "limit", Type: Int}},
+				Cost:     10,
+				ListSize: 100,
+				Resolve: func(p ResolveParams) (any, error) {
+					return []map[string]any{
+						{"id": "a", "name": "Anvil", "children": []map[string]any{{"name": "Bolt"}}},
+						{"id": "b", "name": "Bellows"},
+					}, nil
+				},
+			},
+		},
+	}
+	schema, err := NewSchema(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+// fragmentChain returns a query whose fragment n spreads fragment n-1 twice, so expanding it
+// naively visits 2^n copies of selection
+func fragmentChain(n int, selection string) string {
+	var query strings.Builder
+	fmt.Fprintf(&query, "{ items { ...F%d } }\nfragment F0 on Item { %s }\n", n, selection)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&query, "fragment F%d on Item { ...F%d ...F%d }\n", i, i-1, i-1)
+	}
+	return query.String()
+}
+
+func TestFragmentChainsAreCheap(t *testing.T) {
+	schema := testSchema(t)
+	limits := Limits{MaxDepth: 10, MaxComplexity: 1000}
+
+	tests := []struct {
+		name      string
+		selection string
+		wantCode  string
+	}{
+		// Each copy of children costs, so the chain passes the limit and is rejected
+		{"costly fields", "children { name }", CodeComplexity},
+		// Scalars are free, so the chain is allowed and its fields merge into one
+		{"free fields", "name", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			response := schema.Execute(context.Background(), Request{Query: fragmentChain(60, tt.selection)}, limits)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+
+			if tt.wantCode == "" {
+				if len(response.Errors) > 0 {
+					t.Fatalf("errors: %v", response.Errors[0].Message)
+				}
+				assertJSON(t, response.Data, `{"items":[{"name":"Anvil"},{"name":"Bellows"}]}`)
+				return
+			}
+			if len(response.Errors) != 1 || response.Errors[0].Extensions["code"] != tt.wantCode {
+				t.Fatalf("errors = %+v, want one %s", response.Errors, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	schema := testSchema(t)
+	limits := Limits{MaxDepth: 3, MaxComplexity: 1000}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode string
+	}{
+		// 1 + 10 + 100 * (1 + 5 + 20 * 1)
+		{"too complex", "{ items { children { name } } }", CodeComplexity},
+		// 1 + 10 + 10 * (1 + 5 + 20 * 1)
+		{"limit lowers the cost", "{ items(limit: 10) { children { name } } }", ""},
+		{"limit from a variable", "query ($n: Int = 10) { items(limit: $n) { children { name } } }", ""},
+		{"skipped fields are free", "{ items { children @skip(if: true) { name } name } }", ""},
+		{"too deep", "{ items(limit: 1) { children { parent { name } } } }", CodeComplexity},
+		{"introspection", "{ __schema { types { name } } }", CodeValidation},
+		{"syntax", "{ items {", CodeSyntax},
+		{"unknown field", "{ items { price } }", CodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := schema.Execute(context.Background(), Request{Query: tt.query}, limits)
+			if tt.wantCode == "" {
+				if len(response.Errors) > 0 {
+					t.Fatalf("errors: %v", response.Errors[0].Message)
+				}
+				return
+			}
+			if len(response.Errors) == 0 || response.Errors[0].Extensions["code"] != tt.wantCode {
+				t.Fatalf("errors = %+v, want %s", response.Errors, tt.wantCode)
+			}
+			if response.Data != nil {
+				t.Errorf("data = %v, want none", response.Data)
+			}
+		})
+	}
+}
+
+func TestResponseKeepsSelectionOrder(t *testing.T) {
+	schema := testSchema(t)
+	query := `{ items(limit: 1) { name first: id ... on Item { children { name } } __typename } }`
+	response := schema.Execute(context.Background(), Request{Query: query}, Limits{})
+	if len(response.Errors) > 0 {
+		t.Fatalf("errors: %v", response.Errors[0].Message)
+	}
+	assertJSON(t, response.Data, `{"items":[`+
+		`{"name":"Anvil","first":"a","children":[{"name":"Bolt"}],"__typename":"Item"},`+
+		`{"name":"Bellows","first":"b","children":null,"__typename":"Item"}]}`)
+}
+
+// assertJSON compares a value's JSON encoding, including key order, with want
+func assertJSON(t *testing.T, value any, want string) {
+	t.Helper()
+	got, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("data = %s\nwant   %s", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// maxCost caps running complexity so huge queries cannot overflow it when no limit is set
+const maxCost = 1 << 31
+
+// validator checks a validated query against Limits and rejects introspection
+// Each fragment's cost is computed once per depth it is spread at, so fragments spreading
+// each other repeatedly cost linear time, and the walk stops once the limit is passed
+type validator struct {
+	schema     *Schema
+	source     *source.Source
+	fragments  map[string]*ast.FragmentDefinition
+	variables  map[string]any
+	limits     Limits
+	costs      map[fragmentUse]int
+	errors     []*Error
+	tooDeep    bool
+	tooComplex bool
+}
+
+// fragmentUse is a fragment spread at a depth
+type fragmentUse struct {
+	name  string
+	depth int
+}
+
+// over reports whether complexity passes the limit, remembering that it did
+func (v *validator) over(complexity int) bool {
+	if v.limits.MaxComplexity > 0 && complexity > v.limits.MaxComplexity {
+		v.tooComplex = true
+	}
+	return v.tooComplex
+}
+
+// selectionSet returns the complexity of selections on parent, stopping once it passes the limit
+// visiting holds the fragments being expanded; validation has already rejected cycles
+func (v *validator) selectionSet(parent *Object, set *ast.SelectionSet, depth int, visiting map[string]bool) int {
+	if v.limits.MaxDepth > 0 && depth > v.limits.MaxDepth && !v.tooDeep {
+		v.tooDeep = true
+		v.errors = append(v.errors, &Error{
+			Message:    fmt.Sprintf("query depth exceeds the limit of %d", v.limits.MaxDepth),
+			Extensions: map[string]any{"code": CodeComplexity, "limit": v.limits.MaxDepth},
+		})
+	}
+
+	complexity := 0
+	for _, s := range set.Selections {
+		switch s := s.(type) {
+		case *ast.Field:
+			if included(s.Directives, v.variables) {
+				complexity += v.field(parent, s, depth, visiting)
+			}
+		case *ast.FragmentSpread:
+			if included(s.Directives, v.variables) {
+				complexity += v.fragment(parent, s.Name.Value, depth, visiting)
+			}
+		case *ast.InlineFragment:
+			if included(s.Directives, v.variables) {
+				complexity += v.selectionSet(parent, s.SelectionSet, depth, visiting)
+			}
+		}
+		complexity = min(complexity, maxCost)
+		if v.over(complexity) {
+			break
+		}
+	}
+	return complexity
+}
+
+// fragment returns the complexity of a fragment spread at depth, computing it only once
+func (v *validator) fragment(parent *Object, name string, depth int, visiting map[string]bool) int {
+	use := fragmentUse{name: name, depth: depth}
+	if cost, ok := v.costs[use]; ok {
+		return cost
+	}
+	fragment, ok := v.fragments[name]
+	if !ok || visiting[name] {
+		return 0
+	}
+	visiting[name] = true
+	cost := v.selectionSet(parent, fragment.SelectionSet, depth, visiting)
+	delete(visiting, name)
+	v.costs[use] = cost
+	return cost
+}
+
+// field returns the complexity of one field selection
+func (v *validator) field(parent *Object, f *ast.Field, depth int, visiting map[string]bool) int {
+	name := f.Name.Value
+	if name == "__typename" {
+		return 0
+	}
+	definition := parent.field(name)
+	if definition == nil {
+		// Validation has checked every other field, so this is __schema or __type
+		v.errors = append(v.errors, validationError(fmt.Sprintf("cannot query field %q on type %s; introspection is not supported, GET /graphql/schema returns the schema", name, parent.Name), locate(v.source, f.Loc)))
+		return 0
+	}
+	if isLeaf(definition.Type) {
+		return definition.Cost
+	}
+
+	children := v.selectionSet(namedType(definition.Type).(*Object), f.SelectionSet, depth+1, visiting)
+	if isList(definition.Type) {
+		children *= v.listSize(definition, f)
+	}
+	return 1 + definition.Cost + children
+}
+
+// listSize is the most items a list field returns, lowered by its limit argument
+func (v *validator) listSize(definition *Field, f *ast.Field) int {
+	size := max(definition.ListSize, 1)
+
+	var limit any
+	for _, declared := range definition.Args {
+		if declared.Name == "limit" {
+			limit = declared.Default
+		}
+	}
+	for _, argument := range f.Arguments {
+		if argument.Name.Value == "limit" {
+			if value := literalValue(argument.Value, v.variables); value != nil {
+				limit = value
+			}
+		}
+	}
+	if limit, ok := limit.(int); ok && limit >= 0 && limit < size {
+		size = limit
+	}
+	return size
+}
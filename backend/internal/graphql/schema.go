@@ -0,0 +1,413 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Type is a GraphQL output or input type
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type serialized by a Go function
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved Go value to its JSON representation
+	Serialize func(value any) (any, error)
+	// Parse converts an argument or variable to the Go value passed to resolvers
+	Parse func(value any) (any, error)
+}
+
+// Enum is a leaf type with a fixed set of values
+type Enum struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+// Object is a type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// List wraps a type in a list
+type List struct {
+	Of Type
+}
+
+// NonNull marks a type as never null
+type NonNull struct {
+	Of Type
+}
+
+func (s *Scalar) String() string  { return s.Name }
+func (e *Enum) String() string    { return e.Name }
+func (o *Object) String() string  { return o.Name }
+func (l *List) String() string    { return "[" + l.Of.String() + "]" }
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// Field is a field of an object type
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Cost is the complexity added each time the field is resolved, on top of
+	// 1 for object fields; scalar fields are free unless they set a cost
+	Cost int
+	// ListSize is the most items a list field returns, used to weigh its selections;
+	// a "limit" argument lowers it
+	ListSize int
+	// Resolve returns the field's value; nil reads the source map or struct field of the same name
+	Resolve func(p ResolveParams) (any, error)
+}
+
+// Argument is an argument of a field
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     any
+}
+
+// ResolveParams holds the inputs of a field resolver
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value of the parent object
+	Source any
+	// Args holds every declared argument, with defaults applied and values parsed
+	Args map[string]any
+}
+
+// Built-in scalar types, which the library serializes and parses
+var (
+	Int     = &Scalar{Name: "Int"}
+	Float   = &Scalar{Name: "Float"}
+	String  = &Scalar{Name: "String"}
+	Boolean = &Scalar{Name: "Boolean"}
+	ID      = &Scalar{Name: "ID"}
+	// Time is an RFC 3339 timestamp
+	Time = &Scalar{
+		Name:        "Time",
+		Description: "An RFC 3339 timestamp",
+		Serialize:   serializeTime,
+		Parse:       parseTime,
+	}
+)
+
+// Schema is a validated set of types rooted at a query type
+// Queries are parsed, validated and executed by graphql-go; the types here add costs for Limits
+type Schema struct {
+	query  *Object
+	types  map[string]Type
+	schema gql.Schema
+}
+
+// NewSchema collects every type reachable from query, checks that type names are unique
+// and builds the executable schema
+func NewSchema(query *Object) (*Schema, error) {
+	schema := &Schema{query: query, types: make(map[string]Type)}
+	if err := schema.collect(query); err != nil {
+		return nil, err
+	}
+
+	built, err := gql.NewSchema(gql.SchemaConfig{Query: newBuilder().output(query).(*gql.Object)})
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	schema.schema = built
+	return schema, nil
+}
+
+// builder converts types to their graphql-go equivalents, building each named type once
+type builder struct {
+	named map[string]gql.Type
+}
+
+func newBuilder() *builder {
+	return &builder{named: map[string]gql.Type{
+		Int.Name:     gql.Int,
+		Float.Name:   gql.Float,
+		String.Name:  gql.String,
+		Boolean.Name: gql.Boolean,
+		ID.Name:      gql.ID,
+	}}
+}
+
+// output converts a type used by fields
+func (b *builder) output(t Type) gql.Output {
+	switch t := t.(type) {
+	case *NonNull:
+		return gql.NewNonNull(b.output(t.Of))
+	case *List:
+		return gql.NewList(b.output(t.Of))
+	}
+	if built, ok := b.named[t.String()]; ok {
+		return built
+	}
+
+	var built gql.Type
+	switch t := t.(type) {
+	case *Scalar:
+		built = newScalar(t)
+	case *Enum:
+		values := make(gql.EnumValueConfigMap, len(t.Values))
+		for _, value := range t.Values {
+			values[value] = &gql.EnumValueConfig{Value: value}
+		}
+		built = gql.NewEnum(gql.EnumConfig{Name: t.Name, Description: t.Description, Values: values})
+	case *Object:
+		object := t
+		// Fields are built lazily so objects can refer to each other
+		built = gql.NewObject(gql.ObjectConfig{
+			Name:        object.Name,
+			Description: object.Description,
+			Fields: gql.FieldsThunk(func() gql.Fields {
+				fields := make(gql.Fields, len(object.Fields))
+				for _, field := range object.Fields {
+					fields[field.Name] = b.field(field)
+				}
+				return fields
+			}),
+		})
+	}
+	b.named[t.String()] = built
+	return built
+}
+
+// input converts a type used by arguments; NewSchema has checked that it is a scalar or enum
+func (b *builder) input(t Type) gql.Input {
+	return b.output(t).(gql.Input)
+}
+
+// field converts a field, wrapping its resolver
+func (b *builder) field(field *Field) *gql.Field {
+	args := make(gql.FieldConfigArgument, len(field.Args))
+	for _, argument := range field.Args {
+		args[argument.Name] = &gql.ArgumentConfig{
+			Type:         b.input(argument.Type),
+			DefaultValue: argument.Default,
+			Description:  argument.Description,
+		}
+	}
+
+	name, resolve := field.Name, field.Resolve
+	return &gql.Field{
+		Name:        field.Name,
+		Description: field.Description,
+		Type:        b.output(field.Type),
+		Args:        args,
+		Resolve: func(p gql.ResolveParams) (any, error) {
+			if resolve == nil {
+				return defaultResolve(p.Source, name), nil
+			}
+			return resolve(ResolveParams{Context: p.Context, Source: p.Source, Args: p.Args})
+		},
+	}
+}
+
+// newScalar converts a custom scalar; values it cannot serialize or parse become null
+func newScalar(scalar *Scalar) *gql.Scalar {
+	parse := func(value any) any {
+		parsed, err := scalar.Parse(value)
+		if err != nil {
+			return nil
+		}
+		return parsed
+	}
+	return gql.NewScalar(gql.ScalarConfig{
+		Name:        scalar.Name,
+		Description: scalar.Description,
+		Serialize: func(value any) any {
+			serialized, err := scalar.Serialize(value)
+			if err != nil {
+				return nil
+			}
+			return serialized
+		},
+		ParseValue: parse,
+		ParseLiteral: func(value ast.Value) any {
+			if literal, ok := value.(*ast.StringValue); ok {
+				return parse(literal.Value)
+			}
+			return nil
+		},
+	})
+}
+
+// collect registers a type and everything it references
+func (s *Schema) collect(t Type) error {
+	t = namedType(t)
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("type %s is defined twice", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	object, ok := t.(*Object)
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, field := range object.Fields {
+		if seen[field.Name] {
+			return fmt.Errorf("field %s.%s is defined twice", object.Name, field.Name)
+		}
+		seen[field.Name] = true
+		if err := s.collect(field.Type); err != nil {
+			return err
+		}
+		for _, argument := range field.Args {
+			if _, isObject := namedType(argument.Type).(*Object); isObject {
+				return fmt.Errorf("argument %s.%s(%s) must be a scalar or enum", object.Name, field.Name, argument.Name)
+			}
+			if err := s.collect(argument.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SDL renders the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	builder.WriteString("schema {\n  query: " + s.query.Name + "\n}\n")
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if isBuiltin(t) {
+				continue
+			}
+			builder.WriteString("\n" + description(t.Description, "") + "scalar " + t.Name + "\n")
+		case *Enum:
+			builder.WriteString("\n" + description(t.Description, "") + "enum " + t.Name + " {\n")
+			for _, value := range t.Values {
+				builder.WriteString("  " + value + "\n")
+			}
+			builder.WriteString("}\n")
+		case *Object:
+			builder.WriteString("\n" + description(t.Description, "") + "type " + t.Name + " {\n")
+			for _, field := range t.Fields {
+				builder.WriteString(description(field.Description, "  ") + "  " + field.Name)
+				if len(field.Args) > 0 {
+					arguments := make([]string, len(field.Args))
+					for i, argument := range field.Args {
+						arguments[i] = argument.Name + ": " + argument.Type.String()
+						if argument.Default != nil {
+							arguments[i] += " = " + literal(argument.Type, argument.Default)
+						}
+					}
+					builder.WriteString("(" + strings.Join(arguments, ", ") + ")")
+				}
+				builder.WriteString(": " + field.Type.String() + "\n")
+			}
+			builder.WriteString("}\n")
+		}
+	}
+	return builder.String()
+}
+
+// description renders a description as a string literal line
+func description(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	return indent + literal(String, text) + "\n"
+}
+
+// literal renders a value of type t as GraphQL source
+func literal(t Type, value any) string {
+	if _, isEnum := namedType(t).(*Enum); isEnum {
+		return fmt.Sprint(value)
+	}
+	if v, ok := value.(string); ok {
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(value)
+}
+
+// isBuiltin reports whether a scalar is predefined by GraphQL
+func isBuiltin(scalar *Scalar) bool {
+	return scalar == Int || scalar == Float || scalar == String || scalar == Boolean || scalar == ID
+}
+
+// namedType strips list and non-null wrappers
+func namedType(t Type) Type {
+	for {
+		switch wrapper := t.(type) {
+		case *List:
+			t = wrapper.Of
+		case *NonNull:
+			t = wrapper.Of
+		default:
+			return t
+		}
+	}
+}
+
+// isLeaf reports whether a type is a scalar or enum
+func isLeaf(t Type) bool {
+	_, isObject := namedType(t).(*Object)
+	return !isObject
+}
+
+// isList reports whether a type is a list, possibly non-null
+func isList(t Type) bool {
+	if nonNull, ok := t.(*NonNull); ok {
+		t = nonNull.Of
+	}
+	_, ok := t.(*List)
+	return ok
+}
+
+// field returns the named field of an object
+func (o *Object) field(name string) *Field {
+	for _, field := range o.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func serializeTime(value any) (any, error) {
+	if v, ok := value.(time.Time); ok {
+		if v.IsZero() {
+			return nil, nil
+		}
+		return v.Format(time.RFC3339), nil
+	}
+	return nil, fmt.Errorf("Time cannot represent %v", value)
+}
+
+func parseTime(value any) (any, error) {
+	if v, ok := value.(time.Time); ok {
+		return v, nil
+	}
+	if v, ok := value.(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, v); err == nil {
+			return parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("Time must be an RFC 3339 timestamp, got %v", value)
+}
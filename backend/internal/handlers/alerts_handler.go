@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"usage-analytics-dashboard/internal/alerts"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertsHandler handles HTTP requests for alert rules and their state
+type AlertsHandler struct {
+	analyticsService *services.AnalyticsService
+	engine           *alerts.Engine
+}
+
+// NewAlertsHandler creates a new alerts handler
+func NewAlertsHandler(analyticsService *services.AnalyticsService, engine *alerts.Engine) *AlertsHandler {
+	return &AlertsHandler{
+		analyticsService: analyticsService,
+		engine:           engine,
+	}
+}
+
+// GetAlerts handles GET /api/alerts requests
+func (h *AlertsHandler) GetAlerts(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"alerts": h.engine.Statuses()})
+}
+
+// GetHistory handles GET /api/alerts/history requests
+func (h *AlertsHandler) GetHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100 // Default to 100 entries if invalid
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": h.engine.History(c.Query("ruleId"), limit)})
+}
+
+// EvaluateAlerts handles POST /api/alerts/evaluate requests
+func (h *AlertsHandler) EvaluateAlerts(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"alerts": h.engine.Evaluate(c.Request.Context())})
+}
+
+// ListRules handles GET /api/alerts/rules requests
+func (h *AlertsHandler) ListRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"rules": h.engine.Rules()})
+}
+
+// GetRule handles GET /api/alerts/rules/:id requests
+func (h *AlertsHandler) GetRule(c *gin.Context) {
+	rule, err := h.engine.Rule(c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule handles POST /api/alerts/rules requests
+func (h *AlertsHandler) CreateRule(c *gin.Context) {
+	rule, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.engine.CreateRule(*rule)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateRule handles PUT /api/alerts/rules/:id requests
+func (h *AlertsHandler) UpdateRule(c *gin.Context) {
+	rule, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.engine.UpdateRule(c.Param("id"), *rule)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteRule handles DELETE /api/alerts/rules/:id requests
+func (h *AlertsHandler) DeleteRule(c *gin.Context) {
+	if err := h.engine.DeleteRule(c.Param("id")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindRule decodes and validates a rule body, writing a 400 on failure
+func (h *AlertsHandler) bindRule(c *gin.Context) (*models.AlertRule, bool) {
+	var rule models.AlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	if err := alerts.ValidateRule(rule, h.analyticsService.CompanyExists); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid alert rule",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &rule, true
+}
+
+// writeError maps engine errors to HTTP responses
+func (h *AlertsHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, alerts.ErrRuleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Alert rule not found",
+			"details": c.Param("id"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Failed to save alert rule",
+		"details": err.Error(),
+	})
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
-	"strconv"
-	"strings"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/cache"
 	"usage-analytics-dashboard/internal/models"
 	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/views"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,67 +15,122 @@ import (
 // AnalyticsHandler handles HTTP requests for analytics
 type AnalyticsHandler struct {
 	analyticsService *services.AnalyticsService
+	viewStore        *views.Store
+	responses        *cache.LRU[cache.Response]
 }
 
 // NewAnalyticsHandler creates a new analytics handler
-func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *AnalyticsHandler {
+// Encoded responses are kept in responses, which the caller invalidates when the data changes
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService, viewStore *views.Store, responses *cache.LRU[cache.Response]) *AnalyticsHandler {
 	return &AnalyticsHandler{
 		analyticsService: analyticsService,
+		viewStore:        viewStore,
+		responses:        responses,
 	}
 }
 
 // GetAnalytics handles GET /api/analytics requests
 func (h *AnalyticsHandler) GetAnalytics(c *gin.Context) {
-	params, err := h.parseQueryParams(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid query parameters",
-			"details": err.Error(),
-		})
+	params, ok := h.analyticsParams(c)
+	if !ok {
 		return
 	}
 
 	// Generate analytics using filtered data
-	response := h.analyticsService.GenerateAnalytics(*params)
-
-	c.JSON(http.StatusOK, response)
+	h.respond(c, "analytics?"+queryParamsKey(*params), func() any {
+		return h.analyticsService.GenerateAnalytics(*params)
+	})
 }
 
-// HealthCheck handles GET /health requests
-func (h *AnalyticsHandler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "usage-analytics-dashboard",
+// GetAnalyticsV1 handles GET /api/v1/analytics requests
+// The response keys trends by company ID rather than camelCase company name
+func (h *AnalyticsHandler) GetAnalyticsV1(c *gin.Context) {
+	params, ok := h.analyticsParams(c)
+	if !ok {
+		return
+	}
+
+	h.respond(c, "v1/analytics?"+queryParamsKey(*params), func() any {
+		return h.analyticsService.GenerateAnalyticsV1(*params)
 	})
 }
 
-// parseQueryParams extracts and validates query parameters
-func (h *AnalyticsHandler) parseQueryParams(c *gin.Context) (*models.QueryParams, error) {
-	params := &models.QueryParams{}
+// respond writes the response cached under key, generating and caching it on a miss
+// Clients sending a matching If-None-Match get 304 Not Modified without a body
+func (h *AnalyticsHandler) respond(c *gin.Context, key string, generate func() any) {
+	response, hit := h.responses.Get(key)
+	if !hit {
+		// Read the generation first so a response racing with new data is not cached
+		generation := h.responses.Generation()
+		body, err := json.Marshal(generate())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to encode analytics",
+				"details": err.Error(),
+			})
+			return
+		}
+		response = cache.NewResponse(body)
+		h.responses.Add(key, response, generation)
+	}
+
+	// Responses depend on the caller, so only the client may keep them, and must revalidate
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", response.ETag)
+	if hit {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
 
-	// Parse dateRange parameter
-	dateRangeStr := c.DefaultQuery("dateRange", "30")
-	dateRange, err := strconv.Atoi(dateRangeStr)
-	if err != nil || dateRange <= 0 || dateRange > 365 {
-		dateRange = 30 // Default to 30 days if invalid
+	if cache.NotModified(c.GetHeader("If-None-Match"), response.ETag) {
+		c.Status(http.StatusNotModified)
+		return
 	}
-	params.DateRange = dateRange
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response.Body)
+}
 
-	// Parse companyId parameter
-	companyID := strings.TrimSpace(c.Query("companyId"))
-	params.CompanyID = companyID
+// analyticsParams parses the query and applies a requested saved view, writing an error on failure
+func (h *AnalyticsHandler) analyticsParams(c *gin.Context) (*models.QueryParams, bool) {
+	params, err := parseQueryParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
 
-	// Parse search parameter
-	search := strings.TrimSpace(c.Query("search"))
-	params.Search = search
+	// Run a saved view when requested; explicit query parameters override its filters
+	// Views of companies outside the caller's scope are not found, as in the views API
+	if viewID := c.Query("view"); viewID != "" {
+		view, err := h.viewStore.Get(viewID)
+		if err != nil || !viewInScope(params.Scope, view) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "View not found",
+				"details": viewID,
+			})
+			return nil, false
+		}
 
-	// Parse fromDate parameter
-	fromDate := strings.TrimSpace(c.Query("fromDate"))
-	params.FromDate = fromDate
+		// Hidden companies get the same answer as deleted ones, so this cannot probe for them
+		params = mergeViewParams(c, *params, view.Query)
+		if params.CompanyID != "" && !h.analyticsService.CompanyVisible(params.Scope, params.CompanyID) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "View references a company that no longer exists",
+				"details": params.CompanyID,
+			})
+			return nil, false
+		}
+	}
 
-	// Parse toDate parameter
-	toDate := strings.TrimSpace(c.Query("toDate"))
-	params.ToDate = toDate
+	if params.CompanyID != "" {
+		audit.SetCompanies(c, params.CompanyID)
+	}
+	return params, true
+}
 
-	return params, nil
+// GetCacheStats handles GET /api/cache requests
+func (h *AnalyticsHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.responses.Stats())
 }
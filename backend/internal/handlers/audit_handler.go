@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	logger *audit.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(logger *audit.Logger) *AuditHandler {
+	return &AuditHandler{
+		logger: logger,
+	}
+}
+
+// QueryLog handles GET /api/audit requests
+func (h *AuditHandler) QueryLog(c *gin.Context) {
+	query, err := h.parseQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.logger.Query(*query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to read audit log",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseQuery extracts and validates audit log filters
+func (h *AuditHandler) parseQuery(c *gin.Context) (*models.AuditQuery, error) {
+	query := &models.AuditQuery{
+		Subject:   strings.TrimSpace(c.Query("subject")),
+		CompanyID: strings.TrimSpace(c.Query("companyId")),
+		Kind:      strings.TrimSpace(c.Query("kind")),
+		Action:    strings.TrimSpace(c.Query("action")),
+	}
+
+	if query.Kind != "" && query.Kind != audit.KindRequest && query.Kind != audit.KindAdmin {
+		return nil, fmt.Errorf("kind must be %s or %s", audit.KindRequest, audit.KindAdmin)
+	}
+
+	// Parse from and to as RFC 3339 timestamps or whole days; to is exclusive
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		value := strings.TrimSpace(c.Query(param.name))
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD", param.name)
+		}
+		*param.target = parsed
+	}
+
+	// Parse pagination parameters
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100 // Default to 100 entries if invalid
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	query.Limit = limit
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	query.Offset = offset
+
+	return query, nil
+}
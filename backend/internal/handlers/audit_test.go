@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/cache"
+	"usage-analytics-dashboard/internal/companies"
+	"usage-analytics-dashboard/internal/graphql"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditRecordsCompaniesOutsideTheQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	service := services.NewAnalyticsService(tenantEvents(alpha, beta, gamma), nil, nil)
+	registry, err := companies.NewRegistry(filepath.Join(dir, "companies.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewStore, err := views.NewStore(filepath.Join(dir, "views.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := viewStore.Create(models.SavedViewRequest{
+		Name:  "Beta weekly",
+		Query: models.QueryParams{CompanyID: beta.id, FromDate: "2025-06-01", ToDate: "2025-06-30"},
+	}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := auth.NewStore(filepath.Join(dir, "auth.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := store.MintKey(models.APIKey{Name: "admin", Scopes: []string{auth.ScopeAnalyticsRead}, Role: auth.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := audit.Open(filepath.Join(dir, "audit.log"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	authenticator := auth.NewAuthenticator(store, auth.Options{SessionSecret: "test-secret", SessionTTL: time.Hour})
+	analyticsHandler := NewAnalyticsHandler(service, viewStore, cache.NewLRU[cache.Response](16, time.Minute))
+	companiesHandler := NewCompaniesHandler(service, registry)
+	graphqlHandler, err := NewGraphQLHandler(service, graphql.Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	read := router.Group("/api", audit.Middleware(logger, privacy.NewRedactor([]byte("0123456789abcdef"))),
+		authenticator.Middleware(), auth.RequireScope(auth.ScopeAnalyticsRead), audit.MarkDataAccess())
+	read.GET("/analytics", analyticsHandler.GetAnalytics)
+	read.GET("/companies/:id", companiesHandler.GetCompany)
+	read.POST("/graphql", graphqlHandler.Query)
+
+	tests := []struct {
+		name, target, body string
+		want               []string
+	}{
+		{"company path", "/api/companies/" + gamma.id, "", []string{gamma.id}},
+		{"saved view", "/api/analytics?view=" + view.ID, "", []string{beta.id}},
+		{"query parameter", "/api/analytics?fromDate=2025-06-01&toDate=2025-06-30&companyId=" + alpha.id, "", []string{alpha.id}},
+		{"unfiltered", "/api/analytics?fromDate=2025-06-01&toDate=2025-06-30", "", []string{audit.AllCompanies}},
+		{"graphql company", "/api/graphql", `{"query":"{ company(id: \"` + gamma.id + `\", dateRange: 30) { name } summary(companyId: \"` + alpha.id + `\", dateRange: 30) { totalEvents } }"}`, []string{gamma.id, alpha.id}},
+		{"graphql unfiltered", "/api/graphql", `{"query":"{ company(id: \"` + gamma.id + `\", dateRange: 30) { name } summary(dateRange: 30) { totalEvents } }"}`, []string{audit.AllCompanies}},
+	}
+	for _, tt := range tests {
+		method, reader := http.MethodGet, strings.NewReader(tt.body)
+		if tt.body != "" {
+			method = http.MethodPost
+		}
+		request := httptest.NewRequest(method, tt.target, reader)
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.name, recorder.Code, recorder.Body.String())
+		}
+
+		response, err := logger.Query(models.AuditQuery{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Entries) != 1 {
+			t.Fatalf("%s: got %d entries, want 1", tt.name, len(response.Entries))
+		}
+		if got := response.Entries[0].Companies; !slices.Equal(got, tt.want) {
+			t.Errorf("%s: companies = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
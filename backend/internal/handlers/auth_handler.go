@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles HTTP requests for dashboard login sessions
+type AuthHandler struct {
+	authenticator *auth.Authenticator
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authenticator *auth.Authenticator) *AuthHandler {
+	return &AuthHandler{
+		authenticator: authenticator,
+	}
+}
+
+// Login handles POST /api/auth/login requests
+func (h *AuthHandler) Login(c *gin.Context) {
+	var request models.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Attribute failed attempts to the username they tried
+	audit.SetSubject(c, request.Username)
+
+	response, err := h.authenticator.Login(request.Username, request.Password)
+	switch {
+	case errors.Is(err, auth.ErrSessionsDisabled):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Session login is not enabled",
+			"details": "set AUTH_SESSION_SECRET to enable dashboard login",
+		})
+		return
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Invalid credentials",
+			"details": "unknown username or wrong password",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to log in",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Browser clients use the cookie; other clients can send the token as a bearer token
+	h.setSessionCookie(c, response.Token, int(time.Until(response.ExpiresAt).Seconds()))
+	c.JSON(http.StatusOK, response)
+}
+
+// Logout handles POST /api/auth/logout requests
+func (h *AuthHandler) Logout(c *gin.Context) {
+	h.setSessionCookie(c, "", -1)
+	c.Status(http.StatusNoContent)
+}
+
+// Me handles GET /api/auth/me requests
+func (h *AuthHandler) Me(c *gin.Context) {
+	principal, _ := auth.PrincipalFrom(c)
+	c.JSON(http.StatusOK, principal)
+}
+
+// setSessionCookie writes the session cookie; a negative maxAge clears it
+func (h *AuthHandler) setSessionCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(auth.SessionCookie, token, maxAge, "/", "", c.Request.TLS != nil, true)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/companies"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompaniesHandler handles HTTP requests for the company registry
+type CompaniesHandler struct {
+	analyticsService *services.AnalyticsService
+	registry         *companies.Registry
+}
+
+// NewCompaniesHandler creates a new companies handler
+func NewCompaniesHandler(analyticsService *services.AnalyticsService, registry *companies.Registry) *CompaniesHandler {
+	return &CompaniesHandler{
+		analyticsService: analyticsService,
+		registry:         registry,
+	}
+}
+
+// ListCompanies handles GET /api/companies requests
+func (h *CompaniesHandler) ListCompanies(c *gin.Context) {
+	scope, _ := callerRestrictions(c)
+	c.JSON(http.StatusOK, gin.H{"companies": h.analyticsService.CompanyProfiles(scope)})
+}
+
+// GetCompany handles GET /api/companies/:id requests
+func (h *CompaniesHandler) GetCompany(c *gin.Context) {
+	audit.SetCompanies(c, c.Param("id"))
+	scope, _ := callerRestrictions(c)
+	profile, ok := h.analyticsService.CompanyProfile(scope, c.Param("id"))
+	if !ok {
+		h.writeError(c, companies.ErrCompanyNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateCompany handles PUT /api/companies/:id requests
+// Companies that are not registered yet are added to the registry
+func (h *CompaniesHandler) UpdateCompany(c *gin.Context) {
+	request, ok := h.bindProfile(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.registry.Update(c.Param("id"), *request)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// bindProfile decodes and validates a company profile body, writing a 400 on failure
+func (h *CompaniesHandler) bindProfile(c *gin.Context) (*models.CompanyProfileRequest, bool) {
+	var request models.CompanyProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	if err := companies.ValidateProfile(c.Param("id"), request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid company profile",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &request, true
+}
+
+// writeError maps registry errors to HTTP responses
+func (h *CompaniesHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, companies.ErrCompanyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Company not found",
+			"details": c.Param("id"),
+		})
+	case errors.Is(err, companies.ErrSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Company slug already in use",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to save company",
+			"details": err.Error(),
+		})
+	}
+}
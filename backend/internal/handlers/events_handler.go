@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"usage-analytics-dashboard/internal/ingest"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventsHandler handles HTTP requests for raw usage events
+type EventsHandler struct {
+	analyticsService *services.AnalyticsService
+	journal          *ingest.Journal
+}
+
+// NewEventsHandler creates a new events handler
+// Ingested events are flushed to journal before the request is answered; a nil journal keeps them in memory only
+func NewEventsHandler(analyticsService *services.AnalyticsService, journal *ingest.Journal) *EventsHandler {
+	return &EventsHandler{
+		analyticsService: analyticsService,
+		journal:          journal,
+	}
+}
+
+// ListEvents handles GET /api/events requests
+func (h *EventsHandler) ListEvents(c *gin.Context) {
+	params, err := h.parseListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.analyticsService.ListEvents(*params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchEvents handles GET /api/events/search requests
+func (h *EventsHandler) SearchEvents(c *gin.Context) {
+	params, err := h.parseSearchParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.analyticsService.SearchEvents(*params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid search query",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// maxIngestBatch bounds the number of events accepted in one ingest request
+const maxIngestBatch = 10000
+
+// IngestEvents handles POST /api/events/ingest requests
+func (h *EventsHandler) IngestEvents(c *gin.Context) {
+	var request models.IngestRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Request body too large",
+				"details": fmt.Sprintf("bodies are limited to %d bytes, split the batch", tooLarge.Limit),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(request.Events) == 0 || len(request.Events) > maxIngestBatch {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": fmt.Sprintf("events must contain between 1 and %d items", maxIngestBatch),
+		})
+		return
+	}
+
+	// Valid events are kept even when others in the batch are rejected
+	response := h.analyticsService.Ingest(request.Events)
+
+	// Only acknowledge events once they would survive a restart
+	if h.journal != nil && response.Accepted > 0 {
+		if err := h.journal.Flush(); err != nil {
+			log.Printf("Failed to flush ingest journal: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Events not saved",
+				"details": "the events are in analytics but could not be journaled; saving is retried in the background, and resending the batch is safe",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseSearchParams extracts and validates event search parameters
+func (h *EventsHandler) parseSearchParams(c *gin.Context) (*models.EventSearchParams, error) {
+	params := &models.EventSearchParams{}
+
+	// Parse q parameter (required)
+	params.Query = strings.TrimSpace(c.Query("q"))
+	if params.Query == "" {
+		return nil, fmt.Errorf("q is required")
+	}
+
+	// Parse companyId parameter
+	params.CompanyID = strings.TrimSpace(c.Query("companyId"))
+
+	// Parse optional date parameters - search is not date limited by default
+	if dateRangeStr := c.Query("dateRange"); dateRangeStr != "" {
+		dateRange, err := strconv.Atoi(dateRangeStr)
+		if err != nil || dateRange <= 0 || dateRange > 365 {
+			return nil, fmt.Errorf("dateRange must be between 1 and 365")
+		}
+		params.DateRange = dateRange
+	}
+	params.FromDate = strings.TrimSpace(c.Query("fromDate"))
+	params.ToDate = strings.TrimSpace(c.Query("toDate"))
+
+	// Parse pagination parameters
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20 // Default to 20 results if invalid
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	params.Limit = limit
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	params.Offset = offset
+
+	// Limit results to the caller's companies and apply their privacy mode
+	params.Scope, params.Privacy = callerRestrictions(c)
+
+	return params, nil
+}
+
+// parseListParams extracts and validates event listing parameters
+func (h *EventsHandler) parseListParams(c *gin.Context) (*models.EventListParams, error) {
+	// Use the same filters as /api/analytics
+	filters, err := parseQueryParams(c)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &models.EventListParams{
+		Filters: *filters,
+		Sort:    strings.ToLower(c.DefaultQuery("sort", services.SortAscending)),
+		Cursor:  strings.TrimSpace(c.Query("cursor")),
+	}
+
+	// Parse limit parameter
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50 // Default to 50 events if invalid
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	params.Limit = limit
+
+	// Parse fields parameter as a comma-separated projection
+	for _, field := range strings.Split(c.Query("fields"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			params.Fields = append(params.Fields, field)
+		}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"usage-analytics-dashboard/internal/ingest"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ingestServer serves the ingest route with the journal subscribed as the server wires it
+func ingestServer(t *testing.T, journalPath string) (*gin.Engine, *ingest.Journal) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	journal, _, err := ingest.OpenJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := services.NewAnalyticsService(tenantEvents(alpha), nil, nil)
+	service.Subscribe(journal.HandleIngest)
+
+	router := gin.New()
+	router.POST("/api/events/ingest", NewEventsHandler(service, journal).IngestEvents)
+	return router, journal
+}
+
+func ingestBatch(router *gin.Engine, ids ...string) *httptest.ResponseRecorder {
+	var events []string
+	for _, id := range ids {
+		events = append(events, `{"id":"`+id+`","created_at":"2025-07-23T09:00:00Z","company_id":"company-alpha","type":"Action","content":"Login - Company Alpha ann@alpha.io /home"}`)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/api/events/ingest", strings.NewReader(`{"events":[`+strings.Join(events, ",")+`]}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIngestJournalsBeforeResponding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ingested.ndjson")
+	router, _ := ingestServer(t, path)
+
+	if response := ingestBatch(router, "new-1", "new-2"); response.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", response.Code, response.Body)
+	}
+	// No background flush runs, so the events are on disk only if the request wrote them
+	_, journaled, err := ingest.OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journaled) != 2 || journaled[0].ID != "new-1" || journaled[1].ID != "new-2" {
+		t.Errorf("journal holds %v, want new-1 and new-2", journaled)
+	}
+
+	// A batch of duplicates accepts nothing, so there is nothing to write
+	if response := ingestBatch(router, "new-1"); response.Code != http.StatusOK {
+		t.Fatalf("duplicate batch: status %d: %s", response.Code, response.Body)
+	}
+}
+
+func TestIngestFailsWhenJournalCannotBeWritten(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "journal")
+	router, journal := ingestServer(t, filepath.Join(blocker, "ingested.ndjson"))
+	// A file where the journal's directory belongs stops it from being created
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	response := ingestBatch(router, "new-1")
+	if response.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503: %s", response.Code, response.Body)
+	}
+
+	// The events stay buffered, and a retry of the batch is rejected as duplicates
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	if response := ingestBatch(router, "new-1"); response.Code != http.StatusOK || !strings.Contains(response.Body.String(), "duplicate event id") {
+		t.Fatalf("retry: status %d: %s", response.Code, response.Body)
+	}
+	if err := journal.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	_, journaled, err := ingest.OpenJournal(filepath.Join(blocker, "ingested.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journaled) != 1 || journaled[0].ID != "new-1" {
+		t.Errorf("journal holds %v, want new-1", journaled)
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/export"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export datasets
+const (
+	datasetAnalytics = "analytics"
+	datasetEvents    = "events"
+)
+
+// exportSections maps section names to their table position in export.AnalyticsTables
+var exportSections = map[string]int{
+	"summary":   0,
+	"trends":    1,
+	"companies": 2,
+	"topUsers":  3,
+}
+
+// ExportHandler handles HTTP requests for downloadable exports
+type ExportHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(analyticsService *services.AnalyticsService) *ExportHandler {
+	return &ExportHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// ExportAnalytics handles GET /api/analytics/export requests
+func (h *ExportHandler) ExportAnalytics(c *gin.Context) {
+	params, err := parseQueryParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatCSV))
+	if !export.IsFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": fmt.Sprintf("format must be one of %s, %s, %s", export.FormatCSV, export.FormatXLSX, export.FormatJSON),
+		})
+		return
+	}
+
+	dataset := c.DefaultQuery("dataset", datasetAnalytics)
+	section := c.Query("section")
+
+	switch {
+	case dataset == datasetEvents:
+		h.writeAttachment(c, "events", format, func(w io.Writer) error {
+			return h.writeEvents(w, format, *params)
+		})
+	case dataset != datasetAnalytics:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": fmt.Sprintf("dataset must be %q or %q", datasetAnalytics, datasetEvents),
+		})
+	case section != "" && !isExportSection(section):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": "section must be one of summary, trends, companies, topUsers",
+		})
+	default:
+		response := h.analyticsService.GenerateAnalytics(*params)
+		name := "analytics"
+		if section != "" {
+			name += "-" + section
+		}
+		h.writeAttachment(c, name, format, func(w io.Writer) error {
+			return writeAnalytics(w, format, section, response)
+		})
+	}
+}
+
+// writeAttachment sets download headers and streams the export body
+func (h *ExportHandler) writeAttachment(c *gin.Context, name, format string, write func(io.Writer) error) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102-150405"), format)
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := write(c.Writer); err != nil {
+		// Headers are already sent, so the best we can do is log and abort
+		log.Printf("Failed to write %s export: %v", format, err)
+		c.Abort()
+	}
+}
+
+// writeEvents streams the raw events matching params
+func (h *ExportHandler) writeEvents(w io.Writer, format string, params models.QueryParams) error {
+	events := h.analyticsService.FilteredEvents(params)
+
+	if format == export.FormatJSON {
+		// Stream a JSON array one event at a time
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		for i, event := range events {
+			if i > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, "]")
+		return err
+	}
+
+	tables := []export.Table{export.EventsTable(slices.Values(events))}
+	if format == export.FormatXLSX {
+		return export.WriteXLSX(w, tables)
+	}
+	return export.WriteCSV(w, tables)
+}
+
+// writeAnalytics renders an analytics response, optionally limited to one section
+func writeAnalytics(w io.Writer, format, section string, response models.AnalyticsResponse) error {
+	tables := export.AnalyticsTables(response)
+	if section != "" {
+		tables = tables[exportSections[section] : exportSections[section]+1]
+	}
+
+	switch format {
+	case export.FormatJSON:
+		if section != "" {
+			return export.WriteJSONRows(w, tables)
+		}
+		return json.NewEncoder(w).Encode(response)
+	case export.FormatXLSX:
+		return export.WriteXLSX(w, tables)
+	default:
+		return export.WriteCSV(w, tables)
+	}
+}
+
+// isExportSection reports whether name is a known analytics section
+func isExportSection(name string) bool {
+	_, ok := exportSections[name]
+	return ok
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"usage-analytics-dashboard/internal/audit"
+	"usage-analytics-dashboard/internal/graphql"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler handles GraphQL queries over the analytics service
+type GraphQLHandler struct {
+	schema *graphql.Schema
+	limits graphql.Limits
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+// Queries nested deeper than limits.MaxDepth or costing more than limits.MaxComplexity are rejected
+func NewGraphQLHandler(analyticsService *services.AnalyticsService, limits graphql.Limits) (*GraphQLHandler, error) {
+	schema, err := newAnalyticsSchema(analyticsService)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLHandler{schema: schema, limits: limits}, nil
+}
+
+// Query handles GET and POST /graphql requests
+// POST takes a JSON body; GET takes query, operationName and JSON-encoded variables parameters
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var request graphql.Request
+	if c.Request.Method == http.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid variables",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if request.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "query is required",
+		})
+		return
+	}
+
+	scope, privacy := callerRestrictions(c)
+	caller := &graphqlCaller{
+		scope:     scope,
+		privacy:   privacy,
+		analytics: make(map[string]models.AnalyticsResponseV1),
+	}
+	ctx := context.WithValue(c.Request.Context(), graphqlCallerKey{}, caller)
+
+	// GraphQL reports query errors in the body, so every executed query is a 200
+	result := h.schema.Execute(ctx, request, h.limits)
+	if !caller.unscoped && len(caller.companies) > 0 {
+		audit.SetCompanies(c, caller.companies...)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Schema handles GET /graphql/schema requests
+func (h *GraphQLHandler) Schema(c *gin.Context) {
+	c.String(http.StatusOK, h.schema.SDL())
+}
@@ -0,0 +1,446 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/graphql"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// graphqlCallerKey is the context key of the caller's restrictions
+type graphqlCallerKey struct{}
+
+// graphqlCaller holds the caller's restrictions, the analytics computed for one request
+// and the companies its fields were filtered to, for the audit log
+// Resolvers run sequentially, so the cache needs no lock
+type graphqlCaller struct {
+	scope     *models.CompanyScope
+	privacy   string
+	analytics map[string]models.AnalyticsResponseV1
+	companies []string
+	unscoped  bool
+}
+
+// read records the company a field was filtered to, or that it read every company in scope
+func (c *graphqlCaller) read(companyID string) {
+	if companyID == "" {
+		c.unscoped = true
+	} else if !slices.Contains(c.companies, companyID) {
+		c.companies = append(c.companies, companyID)
+	}
+}
+
+// companyNode is a company together with the filters it was selected with,
+// so its nested trend and top users use the same filters
+type companyNode struct {
+	models.Company
+	params models.QueryParams
+}
+
+// newAnalyticsSchema builds the GraphQL schema over the analytics service
+func newAnalyticsSchema(analyticsService *services.AnalyticsService) (*graphql.Schema, error) {
+	r := &graphqlResolvers{analyticsService: analyticsService}
+
+	trendPoint := &graphql.Object{
+		Name: "TrendPoint",
+		Fields: []*graphql.Field{
+			{Name: "date", Type: nonNull(graphql.String)},
+			{Name: "events", Type: nonNull(graphql.Int)},
+		},
+	}
+	trendSeries := &graphql.Object{
+		Name:        "TrendSeries",
+		Description: "One company's daily event counts",
+		Fields: []*graphql.Field{
+			{Name: "companyId", Type: nonNull(graphql.ID)},
+			{Name: "label", Type: nonNull(graphql.String)},
+			{Name: "total", Type: nonNull(graphql.Int)},
+			{Name: "points", Type: nonNullList(trendPoint), ListSize: 366},
+		},
+	}
+	trends := &graphql.Object{
+		Name: "Trends",
+		Fields: []*graphql.Field{
+			{Name: "days", Type: nonNullList(graphql.String)},
+			{Name: "series", Description: "Series ordered by total events, highest first", Type: nonNullList(trendSeries), ListSize: 100, Resolve: r.trendSeries},
+			{Name: "totals", Type: nonNullList(trendPoint), ListSize: 366},
+		},
+	}
+	user := &graphql.Object{
+		Name: "User",
+		Fields: []*graphql.Field{
+			{Name: "email", Description: "Email, masked according to the caller's privacy mode", Type: nonNull(graphql.String)},
+			{Name: "userId", Description: "Pseudonymous ID, set when emails are masked", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(models.UserActivity).UserID })},
+			{Name: "eventCount", Type: nonNull(graphql.Int)},
+			{Name: "companyName", Type: nonNull(graphql.String)},
+		},
+	}
+	company := &graphql.Object{
+		Name: "Company",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: nonNull(graphql.ID)},
+			{Name: "name", Type: nonNull(graphql.String)},
+			{Name: "slug", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(companyNode).Slug })},
+			{Name: "plan", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(companyNode).Plan })},
+			{Name: "region", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(companyNode).Region })},
+			{Name: "csmOwner", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(companyNode).CSMOwner })},
+			{Name: "arr", Type: graphql.Float},
+			{Name: "tags", Type: nonNullList(graphql.String)},
+			{Name: "eventCount", Type: nonNull(graphql.Int)},
+			{Name: "activeUsers", Type: nonNull(graphql.Int)},
+			{Name: "lastActivity", Type: graphql.Time},
+			{Name: "trend", Description: "Daily events over the selected range", Type: trendSeries, Cost: 10, Resolve: r.companyTrend},
+			{
+				Name:     "topUsers",
+				Type:     nonNullList(user),
+				Args:     []*graphql.Argument{{Name: "limit", Type: graphql.Int, Default: 10}},
+				Cost:     10,
+				ListSize: 10,
+				Resolve:  r.companyTopUsers,
+			},
+		},
+	}
+	group := &graphql.Object{
+		Name: "CompanyGroup",
+		Fields: []*graphql.Field{
+			{Name: "key", Type: nonNull(graphql.String)},
+			{Name: "companies", Type: nonNull(graphql.Int)},
+			{Name: "eventCount", Type: nonNull(graphql.Int)},
+			{Name: "activeUsers", Type: nonNull(graphql.Int)},
+		},
+	}
+	event := &graphql.Object{
+		Name: "Event",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: nonNull(graphql.ID)},
+			{Name: "createdAt", Type: graphql.Time, Resolve: eventField("created_at")},
+			{Name: "companyId", Type: nonNull(graphql.ID), Resolve: eventField("company_id")},
+			{Name: "type", Type: graphql.String},
+			{Name: "content", Type: graphql.String},
+			{Name: "attribute", Type: graphql.String},
+			{Name: "value", Type: graphql.String},
+			{Name: "updatedAt", Type: graphql.Time, Resolve: eventField("updated_at")},
+			{Name: "originalTimestamp", Type: graphql.Time, Resolve: eventField("original_timestamp")},
+		},
+	}
+	eventPage := &graphql.Object{
+		Name: "EventPage",
+		Fields: []*graphql.Field{
+			{Name: "events", Type: nonNullList(event), ListSize: 500},
+			{Name: "total", Type: nonNull(graphql.Int)},
+			{Name: "nextCursor", Description: "Cursor of the next page, null on the last page", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(models.EventListResponse).NextCursor })},
+			{Name: "hasMore", Type: nonNull(graphql.Boolean)},
+		},
+	}
+	summary := &graphql.Object{
+		Name: "Summary",
+		Fields: []*graphql.Field{
+			{Name: "totalEvents", Type: nonNull(graphql.Int)},
+			{Name: "totalCompanies", Type: nonNull(graphql.Int)},
+			{Name: "peakUsageDay", Type: graphql.String, Resolve: optionalString(func(source any) string { return source.(models.DashboardSummary).PeakUsageDay })},
+		},
+	}
+
+	sortOrder := &graphql.Enum{Name: "SortOrder", Values: []string{"ASC", "DESC"}}
+	groupDimension := &graphql.Enum{Name: "GroupDimension", Values: services.GroupDimensions}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{Name: "summary", Type: nonNull(summary), Args: filterArguments(), Cost: 10, Resolve: r.summary},
+			{Name: "trends", Type: nonNull(trends), Args: filterArguments(), Cost: 10, Resolve: r.trends},
+			{
+				Name:     "companies",
+				Type:     nonNullList(company),
+				Args:     append(filterArguments(), &graphql.Argument{Name: "limit", Type: graphql.Int}),
+				Cost:     10,
+				ListSize: 100,
+				Resolve:  r.companies,
+			},
+			{
+				Name: "company",
+				Type: company,
+				Args: append([]*graphql.Argument{{Name: "id", Type: nonNull(graphql.ID)}},
+					withoutArgument(filterArguments(), "companyId")...),
+				Cost:    10,
+				Resolve: r.company,
+			},
+			{
+				Name:     "topUsers",
+				Type:     nonNullList(user),
+				Args:     append(filterArguments(), &graphql.Argument{Name: "limit", Type: graphql.Int, Default: 10}),
+				Cost:     10,
+				ListSize: 10,
+				Resolve:  r.topUsers,
+			},
+			{
+				Name:     "groups",
+				Type:     nonNullList(group),
+				Args:     append(filterArguments(), &graphql.Argument{Name: "by", Type: &graphql.NonNull{Of: groupDimension}}),
+				Cost:     10,
+				ListSize: 50,
+				Resolve:  r.groups,
+			},
+			{
+				Name: "events",
+				Type: nonNull(eventPage),
+				Args: append(filterArguments(),
+					&graphql.Argument{Name: "sort", Type: sortOrder, Default: "ASC"},
+					&graphql.Argument{Name: "limit", Type: graphql.Int, Default: 50},
+					&graphql.Argument{Name: "cursor", Type: graphql.String},
+				),
+				Cost:    10,
+				Resolve: r.events,
+			},
+		},
+	}
+
+	return graphql.NewSchema(query)
+}
+
+// filterArguments returns the arguments mirroring the /api/analytics query parameters
+func filterArguments() []*graphql.Argument {
+	return []*graphql.Argument{
+		{Name: "search", Type: graphql.String},
+		{Name: "companyId", Type: graphql.ID},
+		{Name: "dateRange", Description: "Days ending today, with the year taken as 2025, 1 to 365", Type: graphql.Int, Default: DefaultDateRange},
+		{Name: "fromDate", Description: "YYYY-MM-DD", Type: graphql.String},
+		{Name: "toDate", Description: "YYYY-MM-DD, inclusive", Type: graphql.String},
+		{Name: "plan", Type: graphql.String},
+		{Name: "region", Type: graphql.String},
+		{Name: "csmOwner", Type: graphql.String},
+		{Name: "tag", Type: graphql.String},
+	}
+}
+
+// withoutArgument removes an argument by name
+func withoutArgument(arguments []*graphql.Argument, name string) []*graphql.Argument {
+	kept := arguments[:0]
+	for _, argument := range arguments {
+		if argument.Name != name {
+			kept = append(kept, argument)
+		}
+	}
+	return kept
+}
+
+func nonNull(t graphql.Type) graphql.Type {
+	return &graphql.NonNull{Of: t}
+}
+
+func nonNullList(t graphql.Type) graphql.Type {
+	return &graphql.NonNull{Of: &graphql.List{Of: &graphql.NonNull{Of: t}}}
+}
+
+// optionalString resolves a string field, returning null when it is empty
+func optionalString(get func(source any) string) func(graphql.ResolveParams) (any, error) {
+	return func(p graphql.ResolveParams) (any, error) {
+		if value := get(p.Source); value != "" {
+			return value, nil
+		}
+		return nil, nil
+	}
+}
+
+// eventField resolves an event field by its JSON name
+func eventField(key string) func(graphql.ResolveParams) (any, error) {
+	return func(p graphql.ResolveParams) (any, error) {
+		return p.Source.(map[string]interface{})[key], nil
+	}
+}
+
+// graphqlResolvers resolves the analytics schema
+type graphqlResolvers struct {
+	analyticsService *services.AnalyticsService
+}
+
+func (r *graphqlResolvers) summary(p graphql.ResolveParams) (any, error) {
+	response, err := r.analytics(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return response.Summary, nil
+}
+
+func (r *graphqlResolvers) trends(p graphql.ResolveParams) (any, error) {
+	response, err := r.analytics(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return response.Trends, nil
+}
+
+// trendSeries lists a trends object's series in its explicit order
+func (r *graphqlResolvers) trendSeries(p graphql.ResolveParams) (any, error) {
+	trends := p.Source.(models.TrendsV1)
+	series := make([]models.TrendSeries, 0, len(trends.Order))
+	for _, companyID := range trends.Order {
+		series = append(series, trends.Series[companyID])
+	}
+	return series, nil
+}
+
+func (r *graphqlResolvers) companies(p graphql.ResolveParams) (any, error) {
+	params, err := analyticsParamsFrom(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	response := r.cachedAnalytics(p.Context, params)
+
+	companies := response.Companies
+	if limit, ok := p.Args["limit"].(int); ok && limit >= 0 && limit < len(companies) {
+		companies = companies[:limit]
+	}
+	nodes := make([]companyNode, len(companies))
+	for i, company := range companies {
+		nodes[i] = companyNode{Company: company, params: params}
+	}
+	return nodes, nil
+}
+
+func (r *graphqlResolvers) company(p graphql.ResolveParams) (any, error) {
+	p.Args["companyId"] = p.Args["id"]
+	params, err := analyticsParamsFrom(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, company := range r.cachedAnalytics(p.Context, params).Companies {
+		if company.ID == params.CompanyID {
+			return companyNode{Company: company, params: params}, nil
+		}
+	}
+	return nil, nil
+}
+
+// companyTrend resolves a company's series using the filters it was selected with
+func (r *graphqlResolvers) companyTrend(p graphql.ResolveParams) (any, error) {
+	node := p.Source.(companyNode)
+	params := node.params
+	params.CompanyID = node.ID
+
+	series, ok := r.cachedAnalytics(p.Context, params).Trends.Series[node.ID]
+	if !ok {
+		return nil, nil
+	}
+	return series, nil
+}
+
+// companyTopUsers resolves a company's top users using the filters it was selected with
+func (r *graphqlResolvers) companyTopUsers(p graphql.ResolveParams) (any, error) {
+	node := p.Source.(companyNode)
+	params := node.params
+	params.CompanyID = node.ID
+	return limitUsers(r.cachedAnalytics(p.Context, params).TopUsers, p.Args), nil
+}
+
+func (r *graphqlResolvers) topUsers(p graphql.ResolveParams) (any, error) {
+	response, err := r.analytics(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return limitUsers(response.TopUsers, p.Args), nil
+}
+
+func (r *graphqlResolvers) groups(p graphql.ResolveParams) (any, error) {
+	params, err := analyticsParamsFrom(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	params.GroupBy = p.Args["by"].(string)
+	return r.cachedAnalytics(p.Context, params).Groups, nil
+}
+
+func (r *graphqlResolvers) events(p graphql.ResolveParams) (any, error) {
+	filters, err := analyticsParamsFrom(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := p.Args["limit"].(int)
+	if limit <= 0 || limit > 500 {
+		return nil, fmt.Errorf("limit must be between 1 and 500")
+	}
+	params := models.EventListParams{
+		Filters: filters,
+		Sort:    strings.ToLower(p.Args["sort"].(string)),
+		Limit:   limit,
+	}
+	if cursor, ok := p.Args["cursor"].(string); ok {
+		params.Cursor = cursor
+	}
+	return r.analyticsService.ListEvents(params)
+}
+
+// analytics returns the analytics for a field's filter arguments
+func (r *graphqlResolvers) analytics(ctx context.Context, args map[string]any) (models.AnalyticsResponseV1, error) {
+	params, err := analyticsParamsFrom(ctx, args)
+	if err != nil {
+		return models.AnalyticsResponseV1{}, err
+	}
+	return r.cachedAnalytics(ctx, params), nil
+}
+
+// cachedAnalytics computes analytics once per distinct set of filters in a request
+func (r *graphqlResolvers) cachedAnalytics(ctx context.Context, params models.QueryParams) models.AnalyticsResponseV1 {
+	caller := ctx.Value(graphqlCallerKey{}).(*graphqlCaller)
+	key := fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s|%s|%s|%s", params.DateRange, params.CompanyID, params.Search,
+		params.FromDate, params.ToDate, params.Plan, params.Region, params.CSMOwner, params.Tag, params.GroupBy)
+	if response, ok := caller.analytics[key]; ok {
+		return response
+	}
+	response := r.analyticsService.GenerateAnalyticsV1(params)
+	caller.analytics[key] = response
+	return response
+}
+
+// analyticsParamsFrom converts filter arguments to query parameters scoped to the caller
+// Unlike the unversioned REST routes, invalid values are errors rather than defaults
+func analyticsParamsFrom(ctx context.Context, args map[string]any) (models.QueryParams, error) {
+	caller := ctx.Value(graphqlCallerKey{}).(*graphqlCaller)
+	params := models.QueryParams{Scope: caller.scope, Privacy: caller.privacy}
+
+	dateRange, _ := args["dateRange"].(int)
+	if dateRange < 1 || dateRange > 365 {
+		return params, fmt.Errorf("dateRange must be between 1 and 365")
+	}
+	params.DateRange = dateRange
+
+	for name, target := range map[string]*string{
+		"search":    &params.Search,
+		"companyId": &params.CompanyID,
+		"fromDate":  &params.FromDate,
+		"toDate":    &params.ToDate,
+		"plan":      &params.Plan,
+		"region":    &params.Region,
+		"csmOwner":  &params.CSMOwner,
+		"tag":       &params.Tag,
+	} {
+		if value, ok := args[name].(string); ok {
+			*target = strings.TrimSpace(value)
+		}
+	}
+	caller.read(params.CompanyID)
+
+	for name, value := range map[string]string{"fromDate": params.FromDate, "toDate": params.ToDate} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return params, fmt.Errorf("%s must be in YYYY-MM-DD format", name)
+		}
+	}
+	return params, nil
+}
+
+// limitUsers applies a limit argument to a list of users
+func limitUsers(users []models.UserActivity, args map[string]any) []models.UserActivity {
+	if limit, ok := args["limit"].(int); ok && limit >= 0 && limit < len(users) {
+		return users[:limit]
+	}
+	return users
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"usage-analytics-dashboard/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	readiness *health.Readiness
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(readiness *health.Readiness) *HealthHandler {
+	return &HealthHandler{
+		readiness: readiness,
+	}
+}
+
+// HealthCheck handles GET /health requests
+// It only reports that the process is serving; see Ready for whether it can answer queries
+func (h *HealthHandler) HealthCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "healthy",
+		"service": "usage-analytics-dashboard",
+	})
+}
+
+// Ready handles GET /ready requests, answering 503 while any readiness check fails
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.readiness.Check()
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Starting answers requests received before the API routes are in place
+func (h *HealthHandler) Starting(c *gin.Context) {
+	c.Header("Retry-After", "5")
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":   "Service unavailable",
+		"details": "the server is still starting",
+	})
+}
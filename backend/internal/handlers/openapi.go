@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/openapi"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// Operations of the /api/v1 routes, documented in /api/v1/openapi.json
+// Their parameters are validated before the handlers run, so v1 requests never
+// fall back to the defaults the unversioned routes apply to invalid values
+var (
+	// AnalyticsV1Operation documents GET /api/v1/analytics
+	AnalyticsV1Operation = openapi.Operation{
+		OperationID: "getAnalytics",
+		Summary:     "Summary, trends keyed by company ID, companies and top users",
+		Tags:        []string{"analytics"},
+		Parameters: append(analyticsParameters(),
+			openapi.Query("view", "ID of a saved view whose query is applied; explicit parameters override it", openapi.String()),
+		),
+		Responses: map[string]openapi.Response{
+			"304": {Description: "Unchanged since the ETag sent in If-None-Match"},
+			"404": {Description: "Saved view not found"},
+			"422": {Description: "Saved view references a company that no longer exists"},
+		},
+		Result: models.AnalyticsResponseV1{},
+	}
+
+	// EventsV1Operation documents GET /api/v1/events
+	EventsV1Operation = openapi.Operation{
+		OperationID: "listEvents",
+		Summary:     "Page through raw events with cursor pagination",
+		Tags:        []string{"events"},
+		Parameters: append(analyticsParameters(),
+			openapi.Query("sort", "Order by creation time", openapi.Enum(services.SortAscending, services.SortDescending)),
+			openapi.Query("cursor", "nextCursor from the previous page", openapi.String()),
+			openapi.Query("limit", "Events per page, default 50", openapi.Integer(1, 500)),
+			openapi.Query("fields", "Comma-separated event fields to return, default all", openapi.String()),
+		),
+		Result: models.EventListResponse{},
+	}
+
+	// SearchEventsV1Operation documents GET /api/v1/events/search
+	SearchEventsV1Operation = openapi.Operation{
+		OperationID: "searchEvents",
+		Summary:     "Full-text search over raw events ranked by relevance",
+		Tags:        []string{"events"},
+		Parameters: []openapi.Parameter{
+			{Name: "q", In: openapi.InQuery, Description: "Search terms", Required: true, Schema: openapi.Schema{Type: "string", MinLength: 1}},
+			openapi.Query("companyId", "Only match events of this company", openapi.String()),
+			openapi.Query("dateRange", "Only match events from the N days ending today, with the year taken as 2025", openapi.Integer(1, 365)),
+			openapi.Query("fromDate", "First day to match", openapi.Date()),
+			openapi.Query("toDate", "Last day to match, inclusive", openapi.Date()),
+			openapi.Query("limit", "Results per page, default 20", openapi.Integer(1, 100)),
+			openapi.Query("offset", "Results to skip", openapi.AtLeast(0)),
+		},
+		Result: models.EventSearchResponse{},
+	}
+
+	// ListCompaniesV1Operation documents GET /api/v1/companies
+	ListCompaniesV1Operation = openapi.Operation{
+		OperationID: "listCompanies",
+		Summary:     "Registered and observed companies ordered by name",
+		Tags:        []string{"companies"},
+		Result: struct {
+			Companies []models.CompanyProfile `json:"companies"`
+		}{},
+	}
+
+	// GetCompanyV1Operation documents GET /api/v1/companies/:id
+	GetCompanyV1Operation = openapi.Operation{
+		OperationID: "getCompany",
+		Summary:     "One company's profile",
+		Tags:        []string{"companies"},
+		Parameters:  []openapi.Parameter{openapi.Path("id", "Company ID")},
+		Responses: map[string]openapi.Response{
+			"404": {Description: "Company not found"},
+		},
+		Result: models.CompanyProfile{},
+	}
+
+	// UpdateCompanyV1Operation documents PUT /api/v1/companies/:id
+	UpdateCompanyV1Operation = openapi.Operation{
+		OperationID: "updateCompany",
+		Summary:     "Create or replace a company's profile (admin)",
+		Tags:        []string{"companies"},
+		Parameters:  []openapi.Parameter{openapi.Path("id", "Company ID")},
+		Responses: map[string]openapi.Response{
+			"409": {Description: "Slug already used by another company"},
+		},
+		Body:   models.CompanyProfileRequest{},
+		Result: models.CompanyProfile{},
+	}
+
+	// DailyAggregatesV1Operation documents GET /api/v1/aggregates/daily
+	DailyAggregatesV1Operation = openapi.Operation{
+		OperationID: "getDailyAggregates",
+		Summary:     "Events and active users per company and day, including archived days",
+		Tags:        []string{"analytics"},
+		Parameters: []openapi.Parameter{
+			openapi.Query("companyId", "Only return this company", openapi.String()),
+			openapi.Query("fromDate", "First day to return", openapi.Date()),
+			openapi.Query("toDate", "Last day to return, inclusive", openapi.Date()),
+		},
+		Result: struct {
+			Aggregates []models.DailyAggregate `json:"aggregates"`
+		}{},
+	}
+)
+
+// analyticsParameters returns the filters shared by analytics and event listing
+func analyticsParameters() []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("search", "Search term for users, companies or content", openapi.String()),
+		openapi.Query("companyId", "Only include this company", openapi.String()),
+		openapi.Query("dateRange", "Number of days ending today, with the year taken as 2025 to match the bundled data; default analytics.default_date_range (30)", openapi.Integer(1, 365)),
+		openapi.Query("fromDate", "First day to include; overrides dateRange", openapi.Date()),
+		openapi.Query("toDate", "Last day to include, inclusive; overrides dateRange", openapi.Date()),
+		openapi.Query("plan", "Only include registered companies on this plan", openapi.String()),
+		openapi.Query("region", "Only include registered companies in this region", openapi.String()),
+		openapi.Query("csmOwner", "Only include registered companies with this CSM owner", openapi.String()),
+		openapi.Query("tag", "Only include registered companies with this tag", openapi.String()),
+		openapi.Query("groupBy", "Add totals per value of a company profile field", openapi.Enum(services.GroupDimensions...)),
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultDateRange is the number of days covered when a request gives no range, set at startup
+var DefaultDateRange = 30
+
+// parseQueryParams extracts and validates query parameters
+func parseQueryParams(c *gin.Context) (*models.QueryParams, error) {
+	params := &models.QueryParams{}
+
+	// Parse dateRange parameter
+	dateRangeStr := c.DefaultQuery("dateRange", strconv.Itoa(DefaultDateRange))
+	dateRange, err := strconv.Atoi(dateRangeStr)
+	if err != nil || dateRange <= 0 || dateRange > 365 {
+		dateRange = DefaultDateRange // Fall back to the default range if invalid
+	}
+	params.DateRange = dateRange
+
+	// Parse companyId parameter
+	companyID := strings.TrimSpace(c.Query("companyId"))
+	params.CompanyID = companyID
+
+	// Parse search parameter
+	search := strings.TrimSpace(c.Query("search"))
+	params.Search = search
+
+	// Parse fromDate parameter
+	fromDate := strings.TrimSpace(c.Query("fromDate"))
+	params.FromDate = fromDate
+
+	// Parse toDate parameter
+	toDate := strings.TrimSpace(c.Query("toDate"))
+	params.ToDate = toDate
+
+	// Parse company profile filters
+	params.Plan = strings.TrimSpace(c.Query("plan"))
+	params.Region = strings.TrimSpace(c.Query("region"))
+	params.CSMOwner = strings.TrimSpace(c.Query("csmOwner"))
+	params.Tag = strings.TrimSpace(c.Query("tag"))
+
+	// Parse groupBy parameter
+	groupBy := strings.TrimSpace(c.Query("groupBy"))
+	if groupBy != "" && !slices.Contains(services.GroupDimensions, groupBy) {
+		return nil, fmt.Errorf("groupBy must be one of %s", strings.Join(services.GroupDimensions, ", "))
+	}
+	params.GroupBy = groupBy
+
+	// Limit results to the caller's companies and apply their privacy mode
+	params.Scope, params.Privacy = callerRestrictions(c)
+
+	return params, nil
+}
+
+// queryParamsKey normalizes params into a cache key
+// Requests answered identically share a key: the caller's scope and privacy mode are
+// part of it, and profile filters, which match case-insensitively, are lowercased
+func queryParamsKey(params models.QueryParams) string {
+	values := url.Values{}
+	values.Set("dateRange", strconv.Itoa(params.DateRange))
+	values.Set("companyId", params.CompanyID)
+	values.Set("search", params.Search)
+	values.Set("fromDate", params.FromDate)
+	values.Set("toDate", params.ToDate)
+	values.Set("plan", strings.ToLower(params.Plan))
+	values.Set("region", strings.ToLower(params.Region))
+	values.Set("csmOwner", strings.ToLower(params.CSMOwner))
+	values.Set("tag", strings.ToLower(params.Tag))
+	values.Set("groupBy", params.GroupBy)
+	values.Set("privacy", params.Privacy)
+	// A nil scope sees every company, an empty one none, so they must not share a key
+	if params.Scope == nil {
+		values.Set("scope", "*")
+	} else {
+		companies := slices.Clone(params.Scope.CompanyIDs)
+		slices.Sort(companies)
+		values.Set("scope", strings.Join(companies, ","))
+	}
+	// Encode sorts by parameter name
+	return values.Encode()
+}
+
+// callerRestrictions returns the company scope and privacy mode of the authenticated caller
+// Requests without a principal see nothing, so a route wired without auth fails closed
+func callerRestrictions(c *gin.Context) (*models.CompanyScope, string) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		return &models.CompanyScope{}, privacy.ModeHash
+	}
+	return auth.CompanyScope(principal), principal.Privacy
+}
+
+// mergeViewParams applies a saved view's query, letting parameters present on the request override it
+func mergeViewParams(c *gin.Context, requested models.QueryParams, view models.QueryParams) *models.QueryParams {
+	merged := view
+	merged.Scope = requested.Scope
+	merged.Privacy = requested.Privacy
+
+	if _, ok := c.GetQuery("dateRange"); ok {
+		merged.DateRange = requested.DateRange
+	}
+	if _, ok := c.GetQuery("companyId"); ok {
+		merged.CompanyID = requested.CompanyID
+	}
+	if _, ok := c.GetQuery("search"); ok {
+		merged.Search = requested.Search
+	}
+	if _, ok := c.GetQuery("fromDate"); ok {
+		merged.FromDate = requested.FromDate
+	}
+	if _, ok := c.GetQuery("toDate"); ok {
+		merged.ToDate = requested.ToDate
+	}
+	if _, ok := c.GetQuery("plan"); ok {
+		merged.Plan = requested.Plan
+	}
+	if _, ok := c.GetQuery("region"); ok {
+		merged.Region = requested.Region
+	}
+	if _, ok := c.GetQuery("csmOwner"); ok {
+		merged.CSMOwner = requested.CSMOwner
+	}
+	if _, ok := c.GetQuery("tag"); ok {
+		merged.Tag = requested.Tag
+	}
+	if _, ok := c.GetQuery("groupBy"); ok {
+		merged.GroupBy = requested.GroupBy
+	}
+
+	// Views saved without a range fall back to the same default as requests
+	if merged.DateRange <= 0 {
+		merged.DateRange = DefaultDateRange
+	}
+
+	return &merged
+}
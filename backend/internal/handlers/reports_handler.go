@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"usage-analytics-dashboard/internal/reports"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportsHandler handles HTTP requests for generated reports
+type ReportsHandler struct {
+	scheduler *reports.Scheduler
+}
+
+// NewReportsHandler creates a new reports handler
+func NewReportsHandler(scheduler *reports.Scheduler) *ReportsHandler {
+	return &ReportsHandler{
+		scheduler: scheduler,
+	}
+}
+
+// ListReports handles GET /api/reports requests
+func (h *ReportsHandler) ListReports(c *gin.Context) {
+	files, err := h.scheduler.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list reports",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reports": files})
+}
+
+// DownloadReport handles GET /api/reports/:name requests
+func (h *ReportsHandler) DownloadReport(c *gin.Context) {
+	name := c.Param("name")
+
+	path, err := h.scheduler.Path(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Report not found",
+			"details": name,
+		})
+		return
+	}
+
+	c.FileAttachment(path, name)
+}
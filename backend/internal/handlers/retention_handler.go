@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/retention"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionHandler handles HTTP requests for data retention, erasure and daily aggregates
+type RetentionHandler struct {
+	manager *retention.Manager
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(manager *retention.Manager) *RetentionHandler {
+	return &RetentionHandler{
+		manager: manager,
+	}
+}
+
+// GetStatus handles GET /api/retention requests
+func (h *RetentionHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.Status())
+}
+
+// RunRetention handles POST /api/retention/run requests
+// dryRun=true returns what would be purged; confirm=true allows a purge above the policy's limit
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	if c.Query("dryRun") == "true" {
+		c.JSON(http.StatusOK, gin.H{"purge": h.manager.DryRun(), "dryRun": true})
+		return
+	}
+
+	record, err := h.manager.Run(c.Query("confirm") == "true")
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purge": record})
+}
+
+// ListPurges handles GET /api/retention/purges requests
+func (h *RetentionHandler) ListPurges(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100 // Default to 100 records if invalid
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purges": h.manager.Purges(limit)})
+}
+
+// EraseUser handles DELETE /api/users/:email/data requests
+func (h *RetentionHandler) EraseUser(c *gin.Context) {
+	requestedBy := "anonymous"
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		requestedBy = principal.Subject
+	}
+
+	record, err := h.manager.Erase(c.Param("email"), requestedBy)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// GetDailyAggregates handles GET /api/aggregates/daily requests
+func (h *RetentionHandler) GetDailyAggregates(c *gin.Context) {
+	var fromDate, toDate time.Time
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"fromDate", &fromDate}, {"toDate", &toDate}} {
+		value := strings.TrimSpace(c.Query(param.name))
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid query parameters",
+				"details": fmt.Sprintf("%s must be in YYYY-MM-DD format", param.name),
+			})
+			return
+		}
+		*param.target = parsed
+	}
+
+	scope, _ := callerRestrictions(c)
+	aggregates := h.manager.DailyAggregates(scope, strings.TrimSpace(c.Query("companyId")), fromDate, toDate)
+	c.JSON(http.StatusOK, gin.H{"aggregates": aggregates})
+}
+
+// writeError maps retention errors to HTTP responses
+func (h *RetentionHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, retention.ErrInvalidUser) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user",
+			"details": err.Error(),
+		})
+		return
+	}
+	var tooLarge *retention.PurgeTooLargeError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Purge needs confirmation",
+			"details": err.Error() + "; review it with ?dryRun=true, then rerun with ?confirm=true",
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Failed to save retention state",
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/cache"
+	"usage-analytics-dashboard/internal/companies"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/retention"
+	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tenant is a test company; every value it leaves in a response is one of its markers
+type tenant struct {
+	id, name, domain string
+}
+
+var (
+	alpha = tenant{"company-alpha", "Alpha", "alpha.io"}
+	beta  = tenant{"company-beta", "Beta", "beta.io"}
+	gamma = tenant{"company-gamma", "Gamma", "gamma.io"}
+)
+
+// markers are strings that only appear in a tenant's data: its ID, event IDs, company name and user domain
+func (t tenant) markers() []string {
+	return []string{t.id, "evt-" + t.name, "Company " + t.name, "@" + t.domain}
+}
+
+// tenantEvents gives each tenant events from two users over June 2025
+func tenantEvents(tenants ...tenant) []models.UsageEvent {
+	var events []models.UsageEvent
+	for _, t := range tenants {
+		for day := 1; day <= 5; day++ {
+			for _, user := range []string{"ann", "bob"} {
+				created := time.Date(2025, 6, day, 9, 0, 0, 0, time.UTC)
+				events = append(events, models.UsageEvent{
+					ID:                fmt.Sprintf("evt-%s-%s-%d", t.name, user, day),
+					CreatedAt:         created,
+					CompanyID:         t.id,
+					Type:              "Action",
+					Content:           fmt.Sprintf("Login - Company %s %s@%s /home", t.name, user, t.domain),
+					Attribute:         "Login",
+					UpdatedAt:         created,
+					OriginalTimestamp: created,
+				})
+			}
+		}
+	}
+	return events
+}
+
+// scopedServer serves the company-scoped read routes the way the server wires them
+type scopedServer struct {
+	t      *testing.T
+	router *gin.Engine
+	store  *auth.Store
+	auth   *auth.Authenticator
+	// viewIDs holds a saved view of each tenant, by company ID
+	viewIDs map[string]string
+}
+
+func newScopedServer(t *testing.T) *scopedServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	service := services.NewAnalyticsService(tenantEvents(alpha, beta, gamma), nil, nil)
+	registry, err := companies.NewRegistry(filepath.Join(dir, "companies.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewStore, err := views.NewStore(filepath.Join(dir, "views.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewIDs := make(map[string]string)
+	for _, tenant := range []tenant{alpha, beta, gamma} {
+		view, err := viewStore.Create(models.SavedViewRequest{
+			Name:  "Company " + tenant.name + " weekly",
+			Query: models.QueryParams{CompanyID: tenant.id, FromDate: "2025-06-01", ToDate: "2025-06-30"},
+		}, "admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		viewIDs[tenant.id] = view.ID
+	}
+	manager, err := retention.NewManager(service, filepath.Join(dir, "retention.json"), models.RetentionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := auth.NewStore(filepath.Join(dir, "auth.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := auth.NewAuthenticator(store, auth.Options{SessionSecret: "test-secret", SessionTTL: time.Hour})
+
+	analyticsHandler := NewAnalyticsHandler(service, viewStore, cache.NewLRU[cache.Response](16, time.Minute))
+	eventsHandler := NewEventsHandler(service, nil)
+	exportHandler := NewExportHandler(service)
+	retentionHandler := NewRetentionHandler(manager)
+	companiesHandler := NewCompaniesHandler(service, registry)
+	viewsHandler := NewViewsHandler(service, viewStore)
+
+	router := gin.New()
+	read := router.Group("/api", authenticator.Middleware(), auth.RequireScope(auth.ScopeAnalyticsRead))
+	read.GET("/analytics", analyticsHandler.GetAnalytics)
+	read.GET("/analytics/export", exportHandler.ExportAnalytics)
+	read.GET("/events", eventsHandler.ListEvents)
+	read.GET("/events/search", eventsHandler.SearchEvents)
+	read.GET("/aggregates/daily", retentionHandler.GetDailyAggregates)
+	read.GET("/companies", companiesHandler.ListCompanies)
+	read.GET("/companies/:id", companiesHandler.GetCompany)
+	read.GET("/views", viewsHandler.ListViews)
+	read.POST("/views", viewsHandler.CreateView)
+	read.GET("/views/:id", viewsHandler.GetView)
+	read.PUT("/views/:id", viewsHandler.UpdateView)
+	read.DELETE("/views/:id", viewsHandler.DeleteView)
+
+	return &scopedServer{t: t, router: router, store: store, auth: authenticator, viewIDs: viewIDs}
+}
+
+// key mints an API key with a role and company assignment and returns its token
+func (s *scopedServer) key(role string, companyIDs ...string) string {
+	s.t.Helper()
+	_, token, err := s.store.MintKey(models.APIKey{
+		Name:      role,
+		Scopes:    []string{auth.ScopeAnalyticsRead},
+		Role:      role,
+		Companies: companyIDs,
+	})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return token
+}
+
+// session adds a dashboard user and returns a session token for them
+func (s *scopedServer) session(user models.DashboardUser) string {
+	s.t.Helper()
+	user.Scopes = []string{auth.ScopeAnalyticsRead}
+	if _, err := s.store.AddUser(user, "correct horse battery"); err != nil {
+		s.t.Fatal(err)
+	}
+	login, err := s.auth.Login(user.Username, "correct horse battery")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return login.Token
+}
+
+// do sends a request with token and returns the status and body
+func (s *scopedServer) do(token, method, target, body string) (int, string) {
+	s.t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, target, reader)
+	request.Header.Set("Authorization", "Bearer "+token)
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+// scopedRequests read every company-scoped dataset, including attempts to name a hidden company
+var scopedRequests = []string{
+	"/api/analytics?fromDate=2025-06-01&toDate=2025-06-30",
+	"/api/analytics?fromDate=2025-06-01&toDate=2025-06-30&search=login",
+	"/api/analytics?fromDate=2025-06-01&toDate=2025-06-30&companyId=company-gamma",
+	"/api/analytics/export?fromDate=2025-06-01&toDate=2025-06-30&format=csv",
+	"/api/analytics/export?fromDate=2025-06-01&toDate=2025-06-30&format=json&dataset=events",
+	"/api/analytics/export?fromDate=2025-06-01&toDate=2025-06-30&format=json&dataset=events&companyId=company-gamma",
+	"/api/events?fromDate=2025-06-01&toDate=2025-06-30&limit=500",
+	"/api/events?fromDate=2025-06-01&toDate=2025-06-30&companyId=company-gamma",
+	"/api/events/search?q=login&fromDate=2025-06-01&toDate=2025-06-30&limit=100",
+	"/api/events/search?q=gamma&fromDate=2025-06-01&toDate=2025-06-30",
+	"/api/events/search?q=ann&companyId=company-gamma&fromDate=2025-06-01&toDate=2025-06-30",
+	"/api/aggregates/daily?fromDate=2025-06-01&toDate=2025-06-30",
+	"/api/aggregates/daily?fromDate=2025-06-01&toDate=2025-06-30&companyId=company-gamma",
+	"/api/companies",
+	"/api/companies/company-gamma",
+	"/api/views",
+}
+
+// assertIsolated checks that token sees the visible tenants' data and nothing of the hidden ones
+func (s *scopedServer) assertIsolated(token string, visible, hidden []tenant) {
+	s.t.Helper()
+	seen := make(map[string]bool)
+	for _, target := range scopedRequests {
+		status, body := s.do(token, http.MethodGet, target, "")
+		if status >= http.StatusInternalServerError {
+			s.t.Errorf("GET %s: status %d: %s", target, status, body)
+		}
+		for _, t := range hidden {
+			for _, marker := range t.markers() {
+				// Errors may echo the company ID the request named
+				if strings.Contains(body, marker) && !strings.Contains(target, marker) {
+					s.t.Errorf("GET %s leaks %q of %s", target, marker, t.id)
+				}
+			}
+		}
+		for _, t := range visible {
+			for _, marker := range t.markers() {
+				if strings.Contains(body, marker) {
+					seen[t.id+marker] = true
+				}
+			}
+		}
+	}
+	// Without this, a scope that hides everything would pass
+	for _, t := range visible {
+		for _, marker := range t.markers() {
+			if !seen[t.id+marker] {
+				s.t.Errorf("no response shows %q of visible %s", marker, t.id)
+			}
+		}
+	}
+}
+
+func TestRepKeySeesOnlyAssignedCompanies(t *testing.T) {
+	server := newScopedServer(t)
+	token := server.key(auth.RoleRep, alpha.id)
+	server.assertIsolated(token, []tenant{alpha}, []tenant{beta, gamma})
+}
+
+func TestManagerKeySeesOnlyAssignedCompanies(t *testing.T) {
+	server := newScopedServer(t)
+	token := server.key(auth.RoleManager, alpha.id, beta.id)
+	server.assertIsolated(token, []tenant{alpha, beta}, []tenant{gamma})
+}
+
+func TestRepSessionSeesOnlyAssignedCompanies(t *testing.T) {
+	server := newScopedServer(t)
+	token := server.session(models.DashboardUser{Username: "ray", Role: auth.RoleRep, Companies: []string{beta.id}})
+	server.assertIsolated(token, []tenant{beta}, []tenant{alpha, gamma})
+}
+
+func TestManagerSessionSeesTheirRepsCompanies(t *testing.T) {
+	server := newScopedServer(t)
+	if _, err := server.store.AddUser(models.DashboardUser{
+		Username:  "ray",
+		Scopes:    []string{auth.ScopeAnalyticsRead},
+		Role:      auth.RoleRep,
+		Companies: []string{alpha.id},
+		Manager:   "mia",
+	}, "correct horse battery"); err != nil {
+		t.Fatal(err)
+	}
+	token := server.session(models.DashboardUser{Username: "mia", Role: auth.RoleManager, Companies: []string{beta.id}})
+	server.assertIsolated(token, []tenant{alpha, beta}, []tenant{gamma})
+}
+
+func TestRepCannotUseOtherCompaniesViews(t *testing.T) {
+	server := newScopedServer(t)
+	token := server.key(auth.RoleRep, alpha.id)
+	hidden := server.viewIDs[gamma.id]
+
+	requests := []struct{ method, target, body string }{
+		{http.MethodGet, "/api/views/" + hidden, ""},
+		{http.MethodPut, "/api/views/" + hidden, `{"name":"Mine now","query":{"companyId":"company-alpha"}}`},
+		{http.MethodDelete, "/api/views/" + hidden, ""},
+		{http.MethodGet, "/api/analytics?view=" + hidden, ""},
+	}
+	for _, request := range requests {
+		status, body := server.do(token, request.method, request.target, request.body)
+		if status != http.StatusNotFound {
+			t.Errorf("%s %s: status %d, want 404: %s", request.method, request.target, status, body)
+		}
+	}
+
+	// The view is untouched and still visible to an admin
+	admin := server.key(auth.RoleAdmin)
+	status, body := server.do(admin, http.MethodGet, "/api/views/"+hidden, "")
+	if status != http.StatusOK || !strings.Contains(body, "Company Gamma weekly") {
+		t.Errorf("admin GET view: status %d: %s", status, body)
+	}
+}
+
+func TestHiddenCompaniesLookUnknown(t *testing.T) {
+	server := newScopedServer(t)
+	token := server.key(auth.RoleRep, alpha.id)
+
+	// Saving a view of a hidden company fails exactly like one of a company that does not exist
+	create := func(companyID string) (int, string) {
+		body := fmt.Sprintf(`{"name":"Probe","query":{"companyId":%q}}`, companyID)
+		status, response := server.do(token, http.MethodPost, "/api/views", body)
+		return status, strings.ReplaceAll(response, companyID, "<id>")
+	}
+	hiddenStatus, hiddenBody := create(gamma.id)
+	unknownStatus, unknownBody := create("company-missing")
+	if hiddenStatus != http.StatusBadRequest || hiddenStatus != unknownStatus || hiddenBody != unknownBody {
+		t.Errorf("create view: hidden company got %d %s, unknown company got %d %s", hiddenStatus, hiddenBody, unknownStatus, unknownBody)
+	}
+
+	// Overriding a view's company answers the same for hidden and unknown companies
+	run := func(companyID string) int {
+		status, _ := server.do(token, http.MethodGet, "/api/analytics?view="+server.viewIDs[alpha.id]+"&companyId="+companyID, "")
+		return status
+	}
+	if hidden, unknown := run(gamma.id), run("company-missing"); hidden != unknown {
+		t.Errorf("view with companyId: hidden company got %d, unknown company got %d", hidden, unknown)
+	}
+	if status := run(alpha.id); status != http.StatusOK {
+		t.Errorf("view with own companyId: status %d, want 200", status)
+	}
+}
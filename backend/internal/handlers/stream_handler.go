@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/stream"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Stream event names
+const (
+	streamEventSnapshot = "snapshot"
+	streamEventUpdate   = "update"
+)
+
+// streamRetry is how long browsers wait before reconnecting a dropped stream
+const streamRetry = 3 * time.Second
+
+// StreamHandler handles live analytics streams
+type StreamHandler struct {
+	analytics *AnalyticsHandler
+	hub       *stream.Hub
+	heartbeat time.Duration
+	// closed ends every open stream when the server shuts down
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStreamHandler creates a new stream handler
+// Filters and saved views are parsed as for GET /api/analytics; heartbeat is the idle comment interval
+func NewStreamHandler(analytics *AnalyticsHandler, hub *stream.Hub, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{
+		analytics: analytics,
+		hub:       hub,
+		heartbeat: heartbeat,
+		closed:    make(chan struct{}),
+	}
+}
+
+// Close ends open streams so a graceful shutdown does not wait on them; clients reconnect elsewhere
+func (h *StreamHandler) Close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+// StreamAnalytics handles GET /api/analytics/stream requests
+// It sends a snapshot of the summary and trends, then an update whenever ingested events change them
+// A client reconnecting with Last-Event-ID only receives what changed since that event
+func (h *StreamHandler) StreamAnalytics(c *gin.Context) {
+	params, ok := h.analytics.analyticsParams(c)
+	if !ok {
+		return
+	}
+
+	// Listen before reading the position so no change slips between them
+	notify, stop := h.hub.Listen()
+	defer stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keep reverse proxies from buffering events
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	// Streams outlive the server's write timeout, which is meant for ordinary responses
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.Error(err)
+	}
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds())
+	c.Writer.Flush()
+
+	var cursor stream.Cursor
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		if previous, err := stream.ParseCursor(lastEventID); err == nil {
+			cursor = previous
+		}
+	}
+	if !h.send(c, *params, &cursor) {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.closed:
+			return
+		case <-notify:
+			if !h.send(c, *params, &cursor) {
+				return
+			}
+			heartbeat.Reset(h.heartbeat)
+		case <-heartbeat.C:
+			// Comments keep idle connections open through proxies
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// send writes what changed since cursor and advances it, returning false once the client is gone
+// The snapshot is taken after reading the hub position, so a change racing with it is sent twice rather than lost
+func (h *StreamHandler) send(c *gin.Context, params models.QueryParams, cursor *stream.Cursor) bool {
+	touched, seq, replayable := h.hub.Since(cursor.Epoch, cursor.Seq)
+	if replayable && seq == cursor.Seq {
+		return true
+	}
+	snapshot := h.analytics.analyticsService.GenerateSnapshot(params)
+	next := stream.NewCursor(h.hub.Epoch(), seq, snapshot)
+
+	event := sse.Event{Id: next.String()}
+	if replayable && cursor.SameWindow(next) {
+		update, changed := stream.NewUpdate(snapshot, touched)
+		if !changed {
+			// Nothing visible changed; the cursor stays so a reconnect replays from there
+			return true
+		}
+		event.Event, event.Data = streamEventUpdate, update
+	} else {
+		event.Event, event.Data = streamEventSnapshot, snapshot
+	}
+
+	c.Render(-1, event)
+	if c.Writer.Flush(); c.Request.Context().Err() != nil {
+		return false
+	}
+	*cursor = next
+	return true
+}
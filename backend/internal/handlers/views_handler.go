@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ViewsHandler handles HTTP requests for saved views
+type ViewsHandler struct {
+	analyticsService *services.AnalyticsService
+	viewStore        *views.Store
+}
+
+// NewViewsHandler creates a new saved views handler
+func NewViewsHandler(analyticsService *services.AnalyticsService, viewStore *views.Store) *ViewsHandler {
+	return &ViewsHandler{
+		analyticsService: analyticsService,
+		viewStore:        viewStore,
+	}
+}
+
+// ListViews handles GET /api/views requests
+// Views of companies outside the caller's scope are left out
+func (h *ViewsHandler) ListViews(c *gin.Context) {
+	scope, _ := callerRestrictions(c)
+	visible := []models.SavedView{}
+	for _, view := range h.viewStore.List(c.Query("owner")) {
+		if viewInScope(scope, view) {
+			visible = append(visible, view)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"views": visible})
+}
+
+// GetView handles GET /api/views/:id requests
+func (h *ViewsHandler) GetView(c *gin.Context) {
+	view, err := h.visibleView(c)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// CreateView handles POST /api/views requests
+// The view is owned by the caller
+func (h *ViewsHandler) CreateView(c *gin.Context) {
+	request, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	owner := "anonymous"
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		owner = principal.Subject
+	}
+	view, err := h.viewStore.Create(*request, owner)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+// UpdateView handles PUT /api/views/:id requests
+// Only the view's owner or an admin may replace it
+func (h *ViewsHandler) UpdateView(c *gin.Context) {
+	request, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	if !h.editableView(c) {
+		return
+	}
+	view, err := h.viewStore.Update(c.Param("id"), *request)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView handles DELETE /api/views/:id requests
+// Only the view's owner or an admin may delete it
+func (h *ViewsHandler) DeleteView(c *gin.Context) {
+	if !h.editableView(c) {
+		return
+	}
+	if err := h.viewStore.Delete(c.Param("id")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindRequest decodes and validates a view request body, writing a 400 on failure
+func (h *ViewsHandler) bindRequest(c *gin.Context) (*models.SavedViewRequest, bool) {
+	var request models.SavedViewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	// Companies outside the caller's scope are reported as unknown
+	scope, _ := callerRestrictions(c)
+	companyVisible := func(companyID string) bool {
+		return h.analyticsService.CompanyVisible(scope, companyID)
+	}
+	if err := views.Validate(request, companyVisible); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid view",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &request, true
+}
+
+// visibleView returns the view named in the path, or ErrViewNotFound when its company is outside the caller's scope
+func (h *ViewsHandler) visibleView(c *gin.Context) (models.SavedView, error) {
+	view, err := h.viewStore.Get(c.Param("id"))
+	if err != nil {
+		return models.SavedView{}, err
+	}
+	scope, _ := callerRestrictions(c)
+	if !viewInScope(scope, view) {
+		return models.SavedView{}, views.ErrViewNotFound
+	}
+	return view, nil
+}
+
+// editableView checks that the caller owns the view named in the path or is an admin,
+// writing a 404 or 403 response when not
+func (h *ViewsHandler) editableView(c *gin.Context) bool {
+	view, err := h.visibleView(c)
+	if err != nil {
+		h.writeError(c, err)
+		return false
+	}
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok || (principal.Role != auth.RoleAdmin && principal.Subject != view.Owner) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Not the view's owner",
+			"details": "only " + view.Owner + " or an admin can change this view",
+		})
+		return false
+	}
+	return true
+}
+
+// viewInScope reports whether a caller with scope may see a view
+// Views without a company only filter by profile, and their results are scoped when run
+func viewInScope(scope *models.CompanyScope, view models.SavedView) bool {
+	return scope == nil || view.Query.CompanyID == "" || slices.Contains(scope.CompanyIDs, view.Query.CompanyID)
+}
+
+// writeError maps store errors to HTTP responses
+func (h *ViewsHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, views.ErrViewNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "View not found",
+			"details": c.Param("id"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Failed to save view",
+		"details": err.Error(),
+	})
+}
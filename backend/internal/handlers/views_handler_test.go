@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"usage-analytics-dashboard/internal/auth"
+	"usage-analytics-dashboard/internal/models"
+)
+
+func TestOnlyOwnersChangeViews(t *testing.T) {
+	server := newScopedServer(t)
+	ann := server.session(models.DashboardUser{Username: "ann", Role: auth.RoleRep, Companies: []string{alpha.id}})
+	bob := server.session(models.DashboardUser{Username: "bob", Role: auth.RoleRep, Companies: []string{alpha.id}})
+	admin := server.key(auth.RoleAdmin)
+
+	// An owner in the body is ignored; the view belongs to its creator
+	status, body := server.do(ann, http.MethodPost, "/api/views", `{"name":"Alpha daily","owner":"bob","query":{"companyId":"company-alpha"}}`)
+	if status != http.StatusCreated {
+		t.Fatalf("create view: status %d: %s", status, body)
+	}
+	var view models.SavedView
+	if err := json.Unmarshal([]byte(body), &view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Owner != "ann" {
+		t.Errorf("owner = %q, want ann", view.Owner)
+	}
+
+	target := "/api/views/" + view.ID
+	update := `{"name":"Alpha weekly","query":{"companyId":"company-alpha"}}`
+	tests := []struct {
+		name         string
+		token        string
+		method, body string
+		want         int
+	}{
+		{"other user reads", bob, http.MethodGet, "", http.StatusOK},
+		{"other user replaces", bob, http.MethodPut, update, http.StatusForbidden},
+		{"other user deletes", bob, http.MethodDelete, "", http.StatusForbidden},
+		{"owner replaces", ann, http.MethodPut, update, http.StatusOK},
+		{"admin replaces", admin, http.MethodPut, update, http.StatusOK},
+		{"admin deletes", admin, http.MethodDelete, "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := server.do(tt.token, tt.method, target, tt.body); status != tt.want {
+				t.Errorf("%s %s: status %d, want %d: %s", tt.method, target, status, tt.want, body)
+			}
+		})
+	}
+}
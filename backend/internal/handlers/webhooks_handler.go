@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhooksHandler handles HTTP requests for outbound webhook endpoints
+type WebhooksHandler struct {
+	manager *webhooks.Manager
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(manager *webhooks.Manager) *WebhooksHandler {
+	return &WebhooksHandler{
+		manager: manager,
+	}
+}
+
+// ListEndpoints handles GET /api/webhooks requests
+func (h *WebhooksHandler) ListEndpoints(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"webhooks": h.manager.Endpoints()})
+}
+
+// GetEndpoint handles GET /api/webhooks/:id requests
+func (h *WebhooksHandler) GetEndpoint(c *gin.Context) {
+	endpoint, err := h.manager.Endpoint(c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// CreateEndpoint handles POST /api/webhooks requests
+func (h *WebhooksHandler) CreateEndpoint(c *gin.Context) {
+	endpoint, ok := h.bindEndpoint(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.manager.CreateEndpoint(*endpoint)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateEndpoint handles PUT /api/webhooks/:id requests
+func (h *WebhooksHandler) UpdateEndpoint(c *gin.Context) {
+	endpoint, ok := h.bindEndpoint(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.manager.UpdateEndpoint(c.Param("id"), *endpoint)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteEndpoint handles DELETE /api/webhooks/:id requests
+func (h *WebhooksHandler) DeleteEndpoint(c *gin.Context) {
+	if err := h.manager.DeleteEndpoint(c.Param("id")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries handles GET /api/webhooks/:id/deliveries requests
+func (h *WebhooksHandler) GetDeliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100 // Default to 100 entries if invalid
+	}
+
+	deliveries, err := h.manager.Deliveries(c.Param("id"), limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// SendTest handles POST /api/webhooks/:id/test requests
+func (h *WebhooksHandler) SendTest(c *gin.Context) {
+	payload, err := h.manager.SendTest(c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, payload)
+}
+
+// bindEndpoint decodes and validates an endpoint body, writing a 400 on failure
+func (h *WebhooksHandler) bindEndpoint(c *gin.Context) (*models.WebhookEndpoint, bool) {
+	var endpoint models.WebhookEndpoint
+	if err := c.ShouldBindJSON(&endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	if err := webhooks.ValidateEndpoint(endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid webhook endpoint",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &endpoint, true
+}
+
+// writeError maps manager errors to HTTP responses
+func (h *WebhooksHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, webhooks.ErrEndpointNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Webhook endpoint not found",
+			"details": c.Param("id"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Failed to save webhook endpoint",
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,90 @@
+package health
+
+import "sync"
+
+// Check states
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of every readiness check
+type Report struct {
+	Ready  bool          `json:"ready"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// check is a named readiness condition
+type check struct {
+	name string
+	fn   func() error
+}
+
+// Readiness aggregates the conditions the server needs to serve traffic
+type Readiness struct {
+	mu     sync.Mutex
+	checks []check
+}
+
+// NewReadiness creates a readiness with no checks
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// Add registers a check; the server is not ready while it returns an error
+func (r *Readiness) Add(name string, fn func() error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, fn: fn})
+}
+
+// Check runs every check in registration order
+func (r *Readiness) Check() Report {
+	r.mu.Lock()
+	checks := r.checks
+	r.mu.Unlock()
+
+	report := Report{Ready: true, Checks: make([]CheckResult, 0, len(checks))}
+	for _, c := range checks {
+		result := CheckResult{Name: c.name, Status: StatusOK}
+		if err := c.fn(); err != nil {
+			result.Status = StatusFailing
+			result.Error = err.Error()
+			report.Ready = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// Status holds the outcome of a step that readiness depends on, such as loading the dataset
+type Status struct {
+	mu  sync.RWMutex
+	err error
+}
+
+// NewStatus creates a status holding err; a pending step starts with an error describing it
+func NewStatus(err error) *Status {
+	return &Status{err: err}
+}
+
+// Set records the outcome of the step
+func (s *Status) Set(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Err returns the recorded error, nil once the step succeeded
+func (s *Status) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
@@ -0,0 +1,207 @@
+// Package ingest keeps events ingested through the API across restarts.
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Journal appends ingested events to an NDJSON file, one event per line
+// Batches are buffered in memory and written on Flush, which the ingest route calls before
+// responding; an interval and shutdown flush retry batches whose write failed
+type Journal struct {
+	path string
+
+	mu      sync.Mutex
+	pending []models.UsageEvent
+}
+
+// OpenJournal reads the events journaled by earlier runs from path, starting empty if the file does not exist
+func OpenJournal(path string) (*Journal, []models.UsageEvent, error) {
+	events, err := readEvents(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Journal{path: path}, events, nil
+}
+
+// Merge returns events followed by the journaled events it does not already hold,
+// so a source export that caught up with the journal does not double count
+func Merge(events, journaled []models.UsageEvent) []models.UsageEvent {
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		seen[event.ID] = true
+	}
+	for _, event := range journaled {
+		if !seen[event.ID] {
+			seen[event.ID] = true
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// HandleIngest buffers a batch of newly ingested events; it is registered as an ingest subscriber
+func (j *Journal) HandleIngest(events []models.UsageEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.pending = append(j.pending, events...)
+}
+
+// HandlePurge removes purged events from the buffer and the file, so erased data
+// does not come back after a restart; it is registered as a purge subscriber
+func (j *Journal) HandlePurge(removed []models.UsageEvent) {
+	if len(removed) == 0 {
+		return
+	}
+	ids := make(map[string]bool, len(removed))
+	for _, event := range removed {
+		ids[event.ID] = true
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.pending = keep(j.pending, ids)
+	events, err := readEvents(j.path)
+	if err != nil {
+		log.Printf("Failed to read ingest journal: %v", err)
+		return
+	}
+	kept := keep(events, ids)
+	if len(kept) == len(events) {
+		return
+	}
+	if err := j.rewriteLocked(kept); err != nil {
+		log.Printf("Failed to rewrite ingest journal: %v", err)
+	}
+}
+
+// Flush appends buffered events to the file
+// Events stay buffered when the write fails, so the next flush retries them
+func (j *Journal) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.pending) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create ingest journal directory: %w", err)
+	}
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open ingest journal: %w", err)
+	}
+	defer file.Close()
+
+	if err := writeEvents(file, j.pending); err != nil {
+		return fmt.Errorf("failed to write ingest journal: %w", err)
+	}
+	j.pending = nil
+	return nil
+}
+
+// Start flushes on the given interval until ctx is cancelled
+func (j *Journal) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := j.Flush(); err != nil {
+					log.Printf("Failed to flush ingest journal: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// rewriteLocked replaces the file with events atomically; caller must hold mu
+func (j *Journal) rewriteLocked(events []models.UsageEvent) error {
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmp := j.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := writeEvents(file, events); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// writeEvents writes one JSON event per line and syncs the file
+func writeEvents(file *os.File, events []models.UsageEvent) error {
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// readEvents reads a journal, returning no events if the file does not exist
+func readEvents(path string) ([]models.UsageEvent, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ingest journal: %w", err)
+	}
+	defer file.Close()
+
+	var events []models.UsageEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event models.UsageEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// A crash mid-append can leave a partial last line; everything before it is intact
+			log.Printf("Skipping unreadable ingest journal line %d: %v", line, err)
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ingest journal: %w", err)
+	}
+	return events, nil
+}
+
+// keep returns the events whose IDs are not in ids
+func keep(events []models.UsageEvent, ids map[string]bool) []models.UsageEvent {
+	kept := events[:0:0]
+	for _, event := range events {
+		if !ids[event.ID] {
+			kept = append(kept, event)
+		}
+	}
+	return kept
+}
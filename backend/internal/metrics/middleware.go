@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners cannot create unbounded series
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics records request counts and latency per route
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+	inFlight *Gauge
+}
+
+// NewHTTPMetrics registers the request metrics
+func NewHTTPMetrics(registry *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.NewCounter("http_requests_total", "HTTP requests by method, route and status code", "method", "route", "status"),
+		duration: registry.NewHistogram("http_request_duration_seconds", "HTTP request latency by method and route", DefaultBuckets, "method", "route"),
+		inFlight: registry.NewGauge("http_requests_in_flight", "HTTP requests being served, including open streams"),
+	}
+}
+
+// Middleware records every request under its route pattern rather than its path
+func (m *HTTPMetrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.inFlight.Add(1)
+		defer m.inFlight.Add(-1)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.requests.Inc(c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+		m.duration.Observe(time.Since(start).Seconds(), c.Request.Method, route)
+	}
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler(registry *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := registry.Write(c.Writer); err != nil {
+			c.Error(err)
+		}
+	}
+}
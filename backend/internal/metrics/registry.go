@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency histogram bounds in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric kinds, as written in the TYPE line
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// family is a named metric written in the Prometheus text format
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+	// scrapeHooks run before each scrape, so collectors can take one consistent reading
+	scrapeHooks []func()
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// register adds a family, panicking on a duplicate name since that is a programming error
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+// OnScrape registers a function run before every scrape
+func (r *Registry) OnScrape(hook func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scrapeHooks = append(r.scrapeHooks, hook)
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	counter := &Counter{vec: newVec(name, help, kindCounter, labels)}
+	r.register(name, counter.vec)
+	return counter
+}
+
+// NewGauge registers a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	gauge := &Gauge{vec: newVec(name, help, kindGauge, labels)}
+	r.register(name, gauge.vec)
+	return gauge
+}
+
+// NewHistogram registers a histogram with the given upper bounds and label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	histogram := &Histogram{name: name, help: help, buckets: buckets, labels: labels, series: make(map[string]*histogramSeries)}
+	r.register(name, histogram)
+	return histogram
+}
+
+// CounterFunc registers a counter whose value is read from fn at each scrape
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, &funcFamily{name: name, help: help, kind: kindCounter, fn: fn})
+}
+
+// GaugeFunc registers a gauge whose value is read from fn at each scrape
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(name, &funcFamily{name: name, help: help, kind: kindGauge, fn: fn})
+}
+
+// Write writes every metric in registration order
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := r.families
+	hooks := r.scrapeHooks
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	buffered := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// Counter is a monotonically increasing value per label set
+type Counter struct {
+	vec *vec
+}
+
+// Inc adds one to the series with the given label values
+func (c *Counter) Inc(values ...string) {
+	c.vec.add(1, values)
+}
+
+// Add adds a non-negative amount to the series with the given label values
+func (c *Counter) Add(amount float64, values ...string) {
+	if amount < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.vec.add(amount, values)
+}
+
+// Gauge is a value that can go up and down per label set
+type Gauge struct {
+	vec *vec
+}
+
+// Add adds amount, possibly negative, to the series with the given label values
+func (g *Gauge) Add(amount float64, values ...string) {
+	g.vec.add(amount, values)
+}
+
+// Set replaces the value of the series with the given label values
+func (g *Gauge) Set(value float64, values ...string) {
+	g.vec.set(value, values)
+}
+
+// vec holds one float value per label set
+type vec struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*vecSeries
+}
+
+type vecSeries struct {
+	values []string
+	value  float64
+}
+
+func newVec(name, help, kind string, labels []string) *vec {
+	return &vec{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*vecSeries)}
+}
+
+// seriesLocked returns the series for label values, creating it; caller must hold mu
+func (v *vec) seriesLocked(values []string) *vecSeries {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	series, ok := v.series[key]
+	if !ok {
+		series = &vecSeries{values: append([]string(nil), values...)}
+		v.series[key] = series
+	}
+	return series
+}
+
+func (v *vec) add(amount float64, values []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.seriesLocked(values).value += amount
+}
+
+func (v *vec) set(value float64, values []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.seriesLocked(values).value = value
+}
+
+func (v *vec) write(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, v.kind)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// A metric without labels is always exposed, even before it changes
+	if len(v.labels) == 0 && len(v.series) == 0 {
+		writeSample(w, v.name, nil, nil, 0)
+		return
+	}
+	for _, key := range sortedKeys(v.series) {
+		series := v.series[key]
+		writeSample(w, v.name, v.labels, series.values, series.value)
+	}
+}
+
+// Histogram counts observations in cumulative buckets per label set
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Observe records a value in the series with the given label values
+func (h *Histogram) Observe(value float64, values ...string) {
+	if len(values) != len(h.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", h.name, len(h.labels), len(values)))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := strings.Join(values, "\xff")
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+	// Buckets are counted individually and summed when written
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		series.counts[i]++
+	}
+	series.sum += value
+	series.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	writeHeader(w, h.name, h.help, kindHistogram)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range sortedKeys(h.series) {
+		series := h.series[key]
+		values := append(append([]string(nil), series.values...), "")
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += series.counts[i]
+			values[len(values)-1] = formatFloat(bound)
+			writeSample(w, h.name+"_bucket", labels, values, float64(cumulative))
+		}
+		values[len(values)-1] = "+Inf"
+		writeSample(w, h.name+"_bucket", labels, values, float64(series.count))
+		writeSample(w, h.name+"_sum", h.labels, series.values, series.sum)
+		writeSample(w, h.name+"_count", h.labels, series.values, float64(series.count))
+	}
+}
+
+// funcFamily is an unlabelled metric read from a function at scrape time
+type funcFamily struct {
+	name, help, kind string
+	fn               func() float64
+}
+
+func (f *funcFamily) write(w *bufio.Writer) {
+	writeHeader(w, f.name, f.help, f.kind)
+	writeSample(w, f.name, nil, nil, f.fn())
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeSample writes one sample line
+func writeSample(w *bufio.Writer, name string, labels, values []string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[i])
+			fmt.Fprintf(w, `%s="%s"`, label, escaped)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// formatFloat formats a sample value the way Prometheus parses it
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sortedKeys returns map keys in order, so scrapes list series consistently
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
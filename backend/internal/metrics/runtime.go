@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// RegisterRuntime registers Go runtime and process metrics
+// Memory statistics are read once per scrape, since reading them briefly stops the world
+func RegisterRuntime(registry *Registry) {
+	var (
+		mu    sync.Mutex
+		stats runtime.MemStats
+	)
+	registry.OnScrape(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		runtime.ReadMemStats(&stats)
+	})
+	memStat := func(read func(stats *runtime.MemStats) float64) func() float64 {
+		return func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+			return read(&stats)
+		}
+	}
+
+	start := float64(time.Now().Unix())
+	registry.GaugeFunc("process_start_time_seconds", "Start time of the process since the Unix epoch", func() float64 { return start })
+
+	info := registry.NewGauge("go_info", "Version of the Go runtime", "version")
+	info.Set(1, runtime.Version())
+
+	registry.GaugeFunc("go_goroutines", "Number of goroutines", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	registry.GaugeFunc("go_memstats_alloc_bytes", "Bytes of allocated heap objects", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.Alloc)
+	}))
+	registry.CounterFunc("go_memstats_alloc_bytes_total", "Cumulative bytes allocated for heap objects", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.TotalAlloc)
+	}))
+	registry.GaugeFunc("go_memstats_sys_bytes", "Bytes of memory obtained from the OS", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.Sys)
+	}))
+	registry.GaugeFunc("go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.HeapInuse)
+	}))
+	registry.GaugeFunc("go_memstats_heap_objects", "Number of allocated heap objects", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.HeapObjects)
+	}))
+	registry.CounterFunc("go_gc_cycles_total", "Completed garbage collection cycles", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.NumGC)
+	}))
+	registry.CounterFunc("go_gc_pause_seconds_total", "Cumulative garbage collection stop-the-world pause time", memStat(func(s *runtime.MemStats) float64 {
+		return time.Duration(s.PauseTotalNs).Seconds()
+	}))
+	registry.GaugeFunc("go_memstats_last_gc_time_seconds", "Time of the last garbage collection since the Unix epoch", memStat(func(s *runtime.MemStats) float64 {
+		return float64(s.LastGC) / 1e9
+	}))
+}
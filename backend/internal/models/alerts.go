@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// ActivitySummary represents event and user counts over a period
+type ActivitySummary struct {
+	Events      int `json:"events"`
+	ActiveUsers int `json:"activeUsers"`
+}
+
+// AlertChannel configures where notifications for a rule are delivered
+type AlertChannel struct {
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// AlertRule represents a user-defined condition on a usage metric
+//
+// Threshold conditions ("below", "above") compare daily values and fire when
+// the condition holds for ConsecutiveDays days in a row. Change conditions
+// ("decrease", "increase") compare the last PeriodDays days with the period
+// before it and fire when the change exceeds Threshold percent.
+type AlertRule struct {
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	CompanyID       string         `json:"companyId"`
+	Metric          string         `json:"metric"`
+	Condition       string         `json:"condition"`
+	Threshold       float64        `json:"threshold"`
+	ConsecutiveDays int            `json:"consecutiveDays,omitempty"`
+	PeriodDays      int            `json:"periodDays,omitempty"`
+	Channels        []AlertChannel `json:"channels"`
+	Disabled        bool           `json:"disabled"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+}
+
+// AlertStatus represents the current state of an alert rule
+type AlertStatus struct {
+	RuleID        string    `json:"ruleId"`
+	RuleName      string    `json:"ruleName"`
+	State         string    `json:"state"`
+	Value         float64   `json:"value"`
+	Message       string    `json:"message"`
+	Since         time.Time `json:"since"`
+	LastEvaluated time.Time `json:"lastEvaluated"`
+}
+
+// AlertEvent records a rule transitioning between states
+type AlertEvent struct {
+	RuleID    string    `json:"ruleId"`
+	RuleName  string    `json:"ruleName"`
+	CompanyID string    `json:"companyId"`
+	State     string    `json:"state"`
+	Value     float64   `json:"value"`
+	Message   string    `json:"message"`
+	AsOf      string    `json:"asOf"`
+	At        time.Time `json:"at"`
+}
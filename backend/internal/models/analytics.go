@@ -22,11 +22,18 @@ type Company struct {
 	EventCount   int       `json:"eventCount"`
 	ActiveUsers  int       `json:"activeUsers"`
 	LastActivity time.Time `json:"lastActivity"`
+	Slug         string    `json:"slug,omitempty"`
+	Plan         string    `json:"plan,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	CSMOwner     string    `json:"csmOwner,omitempty"`
+	ARR          float64   `json:"arr,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
 }
 
 // UserActivity represents user activity data
 type UserActivity struct {
 	Email       string `json:"email"`
+	UserID      string `json:"userId,omitempty"`
 	EventCount  int    `json:"eventCount"`
 	CompanyName string `json:"companyName"`
 }
@@ -37,14 +44,6 @@ type UsageTrend struct {
 	Events int    `json:"events"`
 }
 
-// CompanyTrend represents company-specific daily usage trend data (used internally)
-type CompanyTrend struct {
-	Date        string `json:"date"`
-	CompanyID   string `json:"companyId"`
-	CompanyName string `json:"companyName"`
-	Events      int    `json:"events"`
-}
-
 // UsageTrends represents usage trends data
 type UsageTrends struct {
 	// Company-keyed trends object
@@ -52,6 +51,24 @@ type UsageTrends struct {
 	Trends map[string][]UsageTrend `json:"trends"`
 }
 
+// TrendSeries represents one company's daily usage in the v1 trends format
+type TrendSeries struct {
+	CompanyID string       `json:"companyId"`
+	Label     string       `json:"label"`
+	Total     int          `json:"total"`
+	Points    []UsageTrend `json:"points"`
+}
+
+// TrendsV1 represents usage trends keyed by company ID
+// Every series and the totals series cover the same days
+type TrendsV1 struct {
+	Days []string `json:"days"`
+	// Order lists company IDs by total events descending, then by ID
+	Order  []string               `json:"order"`
+	Series map[string]TrendSeries `json:"series"`
+	Totals []UsageTrend           `json:"totals"`
+}
+
 // DashboardSummary represents dashboard summary metrics
 type DashboardSummary struct {
 	TotalEvents    int    `json:"totalEvents"`
@@ -65,6 +82,43 @@ type AnalyticsResponse struct {
 	Trends    UsageTrends      `json:"trends"`
 	Companies []Company        `json:"companies"`
 	TopUsers  []UserActivity   `json:"topUsers"`
+	Groups    []CompanyGroup   `json:"groups,omitempty"`
+}
+
+// AnalyticsResponseV1 represents the analytics response served under /api/v1
+// It differs from AnalyticsResponse only in the trends format
+type AnalyticsResponseV1 struct {
+	Version   string           `json:"version"`
+	Summary   DashboardSummary `json:"summary"`
+	Trends    TrendsV1         `json:"trends"`
+	Companies []Company        `json:"companies"`
+	TopUsers  []UserActivity   `json:"topUsers"`
+	Groups    []CompanyGroup   `json:"groups,omitempty"`
+}
+
+// AnalyticsSnapshot represents the summary and trends sent when a live stream starts
+type AnalyticsSnapshot struct {
+	Summary DashboardSummary `json:"summary"`
+	Trends  TrendsV1         `json:"trends"`
+}
+
+// AnalyticsUpdate represents the trend points changed by newly ingested events
+// Points carry their new values rather than increments, so applying one twice is harmless
+type AnalyticsUpdate struct {
+	Summary DashboardSummary `json:"summary"`
+	Order   []string         `json:"order"`
+	// Series holds each changed series with its new total and only its changed points
+	Series []TrendSeries `json:"series"`
+	Totals []UsageTrend  `json:"totals"`
+}
+
+// CompanyGroup represents totals for companies sharing a profile value
+// A company with several tags counts towards each of its tag groups
+type CompanyGroup struct {
+	Key         string `json:"key"`
+	Companies   int    `json:"companies"`
+	EventCount  int    `json:"eventCount"`
+	ActiveUsers int    `json:"activeUsers"`
 }
 
 // QueryParams represents query parameters for analytics requests
@@ -74,4 +128,21 @@ type QueryParams struct {
 	Search    string `json:"search"`
 	FromDate  string `json:"fromDate"`
 	ToDate    string `json:"toDate"`
+	// Plan, Region, CSMOwner and Tag filter companies by their registry profile
+	Plan     string `json:"plan,omitempty"`
+	Region   string `json:"region,omitempty"`
+	CSMOwner string `json:"csmOwner,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	// GroupBy adds per-group totals for a profile dimension
+	GroupBy string `json:"groupBy,omitempty"`
+	// Scope limits results to the caller's companies; it is never persisted
+	Scope *CompanyScope `json:"-"`
+	// Privacy is the caller's email redaction mode; it is never persisted
+	Privacy string `json:"-"`
+}
+
+// CompanyScope restricts queries to a set of companies
+// A nil scope means every company is visible
+type CompanyScope struct {
+	CompanyIDs []string
 }
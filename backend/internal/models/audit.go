@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// AuditEntry records one API request or admin action
+//
+// Companies lists the companies whose data a request could read: the
+// companies it named through a query parameter, path, saved view or GraphQL
+// arguments, the caller's assigned companies, or "*" for every company. It
+// is empty for requests that read no usage data.
+type AuditEntry struct {
+	ID         string              `json:"id"`
+	Time       time.Time           `json:"time"`
+	Kind       string              `json:"kind"`
+	Subject    string              `json:"subject"`
+	Method     string              `json:"method,omitempty"`
+	Role       string              `json:"role,omitempty"`
+	ClientIP   string              `json:"clientIp,omitempty"`
+	Action     string              `json:"action"`
+	Route      string              `json:"route,omitempty"`
+	Params     map[string]string   `json:"params,omitempty"`
+	Query      map[string][]string `json:"query,omitempty"`
+	Companies  []string            `json:"companies,omitempty"`
+	Status     int                 `json:"status,omitempty"`
+	DurationMs int64               `json:"durationMs"`
+	Details    string              `json:"details,omitempty"`
+}
+
+// AuditQuery filters audit log entries; empty fields match everything
+type AuditQuery struct {
+	Subject   string
+	CompanyID string
+	Kind      string
+	Action    string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+// AuditResponse represents a page of audit log entries, newest first
+type AuditResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
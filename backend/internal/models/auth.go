@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// APIKey represents an issued API key; only a hash of its secret is stored
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Hash      string     `json:"hash"`
+	Scopes    []string   `json:"scopes"`
+	Role      string     `json:"role"`
+	Companies []string   `json:"companies,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// DashboardUser represents an account that can log in to the dashboard
+//
+// Reps see only the companies assigned to them. Managers also see the
+// companies of every rep whose Manager is their username.
+type DashboardUser struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"`
+	Scopes       []string  `json:"scopes"`
+	Role         string    `json:"role"`
+	Companies    []string  `json:"companies,omitempty"`
+	Manager      string    `json:"manager,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Principal identifies the caller of an authenticated request
+// Companies lists the visible companies for every role except admin
+type Principal struct {
+	Subject   string   `json:"subject"`
+	Method    string   `json:"method"`
+	Scopes    []string `json:"scopes"`
+	Role      string   `json:"role"`
+	Companies []string `json:"companies,omitempty"`
+	Privacy   string   `json:"privacy"`
+}
+
+// LoginRequest represents dashboard login credentials
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse represents a successful dashboard login
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Principal Principal `json:"principal"`
+}
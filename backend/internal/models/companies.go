@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// CompanyProfile holds the canonical name and account metadata of a company
+// Registered is false for companies seen only in usage events
+type CompanyProfile struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Slug       string    `json:"slug"`
+	Plan       string    `json:"plan"`
+	ARR        float64   `json:"arr"`
+	CSMOwner   string    `json:"csmOwner"`
+	Region     string    `json:"region"`
+	Tags       []string  `json:"tags"`
+	Registered bool      `json:"registered"`
+	UpdatedAt  time.Time `json:"updatedAt,omitzero"`
+}
+
+// CompanyProfileRequest represents the body of company update requests
+type CompanyProfileRequest struct {
+	Name     string   `json:"name"`
+	Slug     string   `json:"slug"`
+	Plan     string   `json:"plan"`
+	ARR      float64  `json:"arr"`
+	CSMOwner string   `json:"csmOwner"`
+	Region   string   `json:"region"`
+	Tags     []string `json:"tags"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// EventSearchParams represents query parameters for event search requests
+type EventSearchParams struct {
+	Query     string `json:"q"`
+	CompanyID string `json:"companyId"`
+	DateRange int    `json:"dateRange"`
+	FromDate  string `json:"fromDate"`
+	ToDate    string `json:"toDate"`
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
+	// Scope limits results to the caller's companies
+	Scope *CompanyScope `json:"-"`
+	// Privacy is the caller's email redaction mode
+	Privacy string `json:"-"`
+}
+
+// EventSearchResult represents a single ranked search match
+type EventSearchResult struct {
+	Event UsageEvent `json:"event"`
+	Score float64    `json:"score"`
+}
+
+// EventSearchResponse represents the response for event search requests
+type EventSearchResponse struct {
+	Query   string              `json:"query"`
+	Total   int                 `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+	Results []EventSearchResult `json:"results"`
+}
+
+// EventListParams represents query parameters for raw event listing requests
+type EventListParams struct {
+	Filters QueryParams `json:"filters"`
+	Sort    string      `json:"sort"`
+	Cursor  string      `json:"cursor"`
+	Limit   int         `json:"limit"`
+	Fields  []string    `json:"fields"`
+}
+
+// EventListResponse represents a page of raw events
+// Each event only contains the requested fields
+type EventListResponse struct {
+	Events     []map[string]interface{} `json:"events"`
+	Total      int                      `json:"total"`
+	NextCursor string                   `json:"nextCursor,omitempty"`
+	HasMore    bool                     `json:"hasMore"`
+}
+
+// IngestStats counts ingested events since startup
+type IngestStats struct {
+	Batches   uint64 `json:"batches"`
+	Accepted  uint64 `json:"accepted"`
+	Invalid   uint64 `json:"invalid"`
+	Duplicate uint64 `json:"duplicate"`
+}
+
+// DatasetStats describes the size and freshness of the loaded events
+type DatasetStats struct {
+	Events      int         `json:"events"`
+	Companies   int         `json:"companies"`
+	NewestEvent time.Time   `json:"newestEvent"`
+	Ingest      IngestStats `json:"ingest"`
+}
+
+// IngestRequest represents a batch of events posted to the ingest endpoint
+type IngestRequest struct {
+	Events []UsageEvent `json:"events"`
+}
+
+// IngestRejection describes an event that could not be ingested
+type IngestRejection struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// IngestResponse summarizes the outcome of an ingest request
+type IngestResponse struct {
+	Accepted int               `json:"accepted"`
+	Rejected []IngestRejection `json:"rejected"`
+	Total    int               `json:"total"`
+}
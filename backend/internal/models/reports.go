@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ReportDefinition describes a scheduled analytics report
+// The query comes from the saved view when View is set, otherwise from Query
+type ReportDefinition struct {
+	Name      string          `json:"name"`
+	Schedule  string          `json:"schedule"`
+	Formats   []string        `json:"formats"`
+	View      string          `json:"view,omitempty"`
+	Query     QueryParams     `json:"query"`
+	Retention ReportRetention `json:"retention"`
+}
+
+// ReportRetention controls how long generated report files are kept
+// Zero values mean no limit for that dimension
+type ReportRetention struct {
+	MaxFiles   int `json:"maxFiles"`
+	MaxAgeDays int `json:"maxAgeDays"`
+}
+
+// ReportFile represents a generated report on disk
+type ReportFile struct {
+	Name      string    `json:"name"`
+	Report    string    `json:"report"`
+	Format    string    `json:"format"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
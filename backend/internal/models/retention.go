@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// RetentionPolicy controls how long data is kept
+// Periods are written like "90d", "12w", "13mo" or "2y"; empty keeps data forever
+type RetentionPolicy struct {
+	RawEvents       string `json:"rawEvents"`
+	DailyAggregates string `json:"dailyAggregates"`
+	// MaxPurgePercent is the largest share of raw events a purge may remove without confirmation
+	MaxPurgePercent int `json:"maxPurgePercent"`
+}
+
+// DailyAggregate is an anonymous summary of one company's activity on one day
+// Aggregates of purged raw events are archived so long-range history survives retention
+type DailyAggregate struct {
+	Date        string `json:"date"`
+	CompanyID   string `json:"companyId"`
+	CompanyName string `json:"companyName"`
+	Events      int    `json:"events"`
+	ActiveUsers int    `json:"activeUsers"`
+	Archived    bool   `json:"archived"`
+}
+
+// PurgeRecord is the audit record of one purge
+// Subject never holds the erased email itself, only its SHA-256 digest
+type PurgeRecord struct {
+	ID            string     `json:"id"`
+	Kind          string     `json:"kind"`
+	Subject       string     `json:"subject,omitempty"`
+	Cutoff        *time.Time `json:"cutoff,omitempty"`
+	RequestedBy   string     `json:"requestedBy"`
+	EventsRemoved int        `json:"eventsRemoved"`
+	Companies     []string   `json:"companies"`
+	At            time.Time  `json:"at"`
+}
+
+// RetentionStatus describes the retention policy and the data it currently covers
+type RetentionStatus struct {
+	Policy             RetentionPolicy `json:"policy"`
+	RawEvents          int             `json:"rawEvents"`
+	OldestEvent        *time.Time      `json:"oldestEvent,omitempty"`
+	RawEventsCutoff    *time.Time      `json:"rawEventsCutoff,omitempty"`
+	ArchivedAggregates int             `json:"archivedAggregates"`
+	LastRun            *time.Time      `json:"lastRun,omitempty"`
+}
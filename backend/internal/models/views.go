@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ViewOptions holds presentation options saved alongside a view's query
+type ViewOptions struct {
+	Granularity string `json:"granularity,omitempty"`
+	Compare     string `json:"compare,omitempty"`
+	ChartType   string `json:"chartType,omitempty"`
+}
+
+// SavedView represents a named, persisted analytics query
+type SavedView struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Owner     string      `json:"owner"`
+	Query     QueryParams `json:"query"`
+	Options   ViewOptions `json:"options"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// SavedViewRequest represents the body of create and update view requests
+// The owner is not part of it: views belong to whoever creates them
+type SavedViewRequest struct {
+	Name    string      `json:"name"`
+	Query   QueryParams `json:"query"`
+	Options ViewOptions `json:"options"`
+}
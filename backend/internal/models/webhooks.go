@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// WebhookEndpoint represents a registered receiver for usage notifications
+//
+// Events lists the notification types the endpoint subscribes to (all when
+// empty). InactiveDays and Milestones tune the inactivity and event-count
+// notifications for this endpoint only.
+type WebhookEndpoint struct {
+	ID           string    `json:"id"`
+	URL          string    `json:"url"`
+	Secret       string    `json:"secret,omitempty"`
+	Events       []string  `json:"events"`
+	InactiveDays int       `json:"inactiveDays"`
+	Milestones   []int     `json:"milestones"`
+	Privacy      string    `json:"privacy"`
+	Disabled     bool      `json:"disabled"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// WebhookPayload is the signed JSON body posted to webhook endpoints
+type WebhookPayload struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData holds the details of a webhook notification
+type WebhookEventData struct {
+	CompanyID    string     `json:"companyId,omitempty"`
+	CompanyName  string     `json:"companyName,omitempty"`
+	UserID       string     `json:"userId,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	Milestone    int        `json:"milestone,omitempty"`
+	EventCount   int        `json:"eventCount,omitempty"`
+	InactiveDays int        `json:"inactiveDays,omitempty"`
+	LastSeen     *time.Time `json:"lastSeen,omitempty"`
+}
+
+// WebhookDelivery records a single delivery attempt
+type WebhookDelivery struct {
+	ID          string     `json:"id"`
+	EndpointID  string     `json:"endpointId"`
+	EventID     string     `json:"eventId"`
+	EventType   string     `json:"eventType"`
+	Attempt     int        `json:"attempt"`
+	StatusCode  int        `json:"statusCode,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Succeeded   bool       `json:"succeeded"`
+	DurationMs  int64      `json:"durationMs"`
+	At          time.Time  `json:"at"`
+	NextRetryAt *time.Time `json:"nextRetryAt,omitempty"`
+}
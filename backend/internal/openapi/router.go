@@ -0,0 +1,148 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jsonContent is the media type of every documented body
+const jsonContent = "application/json"
+
+// ErrorResponse is the body of error responses
+type ErrorResponse struct {
+	Error             string             `json:"error"`
+	Details           string             `json:"details,omitempty"`
+	InvalidParameters []InvalidParameter `json:"invalidParameters,omitempty"`
+}
+
+// InvalidParameter describes one parameter rejected by validation
+type InvalidParameter struct {
+	Name   string `json:"name"`
+	In     string `json:"in"`
+	Reason string `json:"reason"`
+}
+
+// Spec builds an OpenAPI document from the routes registered through it,
+// so the document cannot drift from the routes and their validation
+type Spec struct {
+	mu       sync.RWMutex
+	document Document
+}
+
+// NewSpec creates an empty spec whose operations authenticate with a bearer token
+func NewSpec(info Info) *Spec {
+	return &Spec{
+		document: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]SecurityScheme{
+					"bearerAuth": {
+						Type:        "http",
+						Scheme:      "bearer",
+						Description: "API key or session token",
+					},
+				},
+			},
+			Security: []map[string][]any{{"bearerAuth": {}}},
+		},
+	}
+}
+
+// Handle registers a route on group, documents it and validates its parameters before handlers run
+// It panics when the path has a parameter the operation does not document, like gin does for invalid routes
+func (s *Spec) Handle(group *gin.RouterGroup, method, path string, operation Operation, handlers ...gin.HandlerFunc) {
+	fullPath := strings.TrimSuffix(group.BasePath(), "/") + path
+
+	s.mu.Lock()
+	documentPath := s.addLocked(fullPath, method, &operation)
+	s.mu.Unlock()
+
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") && !hasParameter(operation, InPath, segment[1:]) {
+			panic(fmt.Sprintf("openapi: %s %s does not document path parameter %s", method, documentPath, segment[1:]))
+		}
+	}
+
+	group.Handle(method, path, append([]gin.HandlerFunc{Validate(operation)}, handlers...)...)
+}
+
+// Serve handles requests for the OpenAPI document
+func (s *Spec) Serve(c *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c.JSON(http.StatusOK, s.document)
+}
+
+// addLocked documents an operation and returns its OpenAPI path; caller must hold mu
+func (s *Spec) addLocked(fullPath, method string, operation *Operation) string {
+	// gin's :name parameters are written {name} in OpenAPI paths
+	segments := strings.Split(fullPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	documentPath := strings.Join(segments, "/")
+
+	if operation.Body != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonContent: {Schema: s.document.schemaFor(reflect.TypeOf(operation.Body))}},
+		}
+	}
+
+	responses := map[string]Response{
+		"200": {Description: "OK"},
+		"400": s.errorResponseLocked("Invalid parameters or body"),
+		"401": s.errorResponseLocked("Missing or invalid credentials"),
+		"403": s.errorResponseLocked("Credentials lack the required scope or company access"),
+	}
+	if operation.Result != nil {
+		responses["200"] = Response{
+			Description: "OK",
+			Content:     map[string]MediaType{jsonContent: {Schema: s.document.schemaFor(reflect.TypeOf(operation.Result))}},
+		}
+	}
+	for status, response := range operation.Responses {
+		// Error responses without their own content use the shared error body
+		if response.Content == nil && (strings.HasPrefix(status, "4") || strings.HasPrefix(status, "5")) {
+			response.Content = s.errorResponseLocked(response.Description).Content
+		}
+		responses[status] = response
+	}
+	operation.Responses = responses
+
+	item, ok := s.document.Paths[documentPath]
+	if !ok {
+		item = make(PathItem)
+		s.document.Paths[documentPath] = item
+	}
+	item[strings.ToLower(method)] = operation
+	return documentPath
+}
+
+// errorResponseLocked returns an error response; caller must hold mu
+func (s *Spec) errorResponseLocked(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{jsonContent: {Schema: s.document.schemaFor(reflect.TypeOf(ErrorResponse{}))}},
+	}
+}
+
+// hasParameter reports whether an operation documents a parameter
+func hasParameter(operation Operation, in, name string) bool {
+	for _, parameter := range operation.Parameters {
+		if parameter.In == in && parameter.Name == name {
+			return true
+		}
+	}
+	return false
+}
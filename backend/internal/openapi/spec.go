@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Parameter locations
+const (
+	InQuery = "query"
+	InPath  = "path"
+)
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Security   []map[string][]any  `json:"security,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path keyed by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how requests authenticate
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Operation describes one route
+// Body and Result are Go values whose types document the request and response bodies
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+
+	Body   any `json:"-"`
+	Result any `json:"-"`
+}
+
+// Parameter describes a query or path parameter
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
+// RequestBody describes a JSON request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes one response status
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema used by the API
+// Parameter validation enforces Type, Format, Enum, Minimum, Maximum and MinLength
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            int                `json:"minLength,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// String returns a string schema
+func String() Schema {
+	return Schema{Type: "string"}
+}
+
+// Date returns a YYYY-MM-DD date schema
+func Date() Schema {
+	return Schema{Type: "string", Format: "date"}
+}
+
+// Enum returns a string schema accepting only values
+func Enum(values ...string) Schema {
+	return Schema{Type: "string", Enum: values}
+}
+
+// Integer returns an integer schema bounded by minimum and maximum
+func Integer(minimum, maximum float64) Schema {
+	return Schema{Type: "integer", Minimum: &minimum, Maximum: &maximum}
+}
+
+// AtLeast returns an integer schema with only a lower bound
+func AtLeast(minimum float64) Schema {
+	return Schema{Type: "integer", Minimum: &minimum}
+}
+
+// Query returns an optional query parameter
+func Query(name, description string, schema Schema) Parameter {
+	return Parameter{Name: name, In: InQuery, Description: description, Schema: schema}
+}
+
+// Path returns a required string path parameter
+func Path(name, description string) Parameter {
+	return Parameter{Name: name, In: InPath, Description: description, Required: true, Schema: String()}
+}
+
+// timeType is documented as an RFC 3339 string, matching its JSON encoding
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema of a Go type as encoding/json renders it,
+// adding named struct types to the document's components
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := d.schemaFor(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// Reserve the name first so self-referencing types terminate
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = *d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		// Interfaces accept any JSON value
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of a struct's JSON fields
+// Fields without omitempty or omitzero are listed as required
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = d.schemaFor(field.Type)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Validate checks a request's parameters against an operation
+// Every invalid, missing or undocumented parameter is reported in one 400 response
+func Validate(operation Operation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var invalid []InvalidParameter
+		documented := make(map[string]bool)
+
+		for _, parameter := range operation.Parameters {
+			var value string
+			var present bool
+			switch parameter.In {
+			case InQuery:
+				documented[parameter.Name] = true
+				value, present = c.GetQuery(parameter.Name)
+			case InPath:
+				value = c.Param(parameter.Name)
+				present = value != ""
+			}
+
+			if !present {
+				if parameter.Required {
+					invalid = append(invalid, InvalidParameter{Name: parameter.Name, In: parameter.In, Reason: "is required"})
+				}
+				continue
+			}
+			if reason := check(parameter.Schema, value); reason != "" {
+				invalid = append(invalid, InvalidParameter{Name: parameter.Name, In: parameter.In, Reason: reason})
+			}
+		}
+
+		// Undocumented parameters are usually typos that would otherwise be ignored silently
+		for name := range c.Request.URL.Query() {
+			if !documented[name] {
+				invalid = append(invalid, InvalidParameter{Name: name, In: InQuery, Reason: "is not a known parameter"})
+			}
+		}
+
+		if len(invalid) == 0 {
+			c.Next()
+			return
+		}
+
+		sort.Slice(invalid, func(i, j int) bool {
+			return invalid[i].Name < invalid[j].Name
+		})
+		reasons := make([]string, len(invalid))
+		for i, parameter := range invalid {
+			reasons[i] = parameter.Name + " " + parameter.Reason
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
+			Error:             "Invalid query parameters",
+			Details:           strings.Join(reasons, "; "),
+			InvalidParameters: invalid,
+		})
+	}
+}
+
+// check returns why value does not satisfy schema, or "" when it does
+func check(schema Schema, value string) string {
+	switch schema.Type {
+	case "integer":
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return "must be an integer"
+		}
+		return checkRange(schema, float64(number))
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "must be a number"
+		}
+		return checkRange(schema, number)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be true or false"
+		}
+		return ""
+	}
+
+	if len(value) < schema.MinLength {
+		if schema.MinLength == 1 {
+			return "must not be empty"
+		}
+		return fmt.Sprintf("must be at least %d characters", schema.MinLength)
+	}
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		return "must be one of " + strings.Join(schema.Enum, ", ")
+	}
+	switch schema.Format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 timestamp"
+		}
+	}
+	return ""
+}
+
+// checkRange returns why number falls outside the schema's bounds, or ""
+func checkRange(schema Schema, number float64) string {
+	switch {
+	case schema.Minimum != nil && schema.Maximum != nil && (number < *schema.Minimum || number > *schema.Maximum):
+		return fmt.Sprintf("must be between %g and %g", *schema.Minimum, *schema.Maximum)
+	case schema.Minimum != nil && number < *schema.Minimum:
+		return fmt.Sprintf("must be at least %g", *schema.Minimum)
+	case schema.Maximum != nil && number > *schema.Maximum:
+		return fmt.Sprintf("must be at most %g", *schema.Maximum)
+	}
+	return ""
+}
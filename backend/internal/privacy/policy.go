@@ -0,0 +1,41 @@
+package privacy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Policy maps roles to the privacy mode applied to their responses
+type Policy map[string]string
+
+// ParsePolicy parses "role=mode" pairs separated by commas, e.g. "manager=mask,rep=hash"
+// Roles that are not listed see emails unchanged
+func ParsePolicy(value string) (Policy, error) {
+	policy := Policy{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		role, mode, found := strings.Cut(pair, "=")
+		role, mode = strings.TrimSpace(role), strings.TrimSpace(mode)
+		if !found || role == "" {
+			return nil, fmt.Errorf("invalid privacy policy entry %q, expected role=mode", pair)
+		}
+		if !slices.Contains(Modes, mode) {
+			return nil, fmt.Errorf("invalid privacy mode %q for %s, expected one of %s", mode, role, strings.Join(Modes, ", "))
+		}
+		policy[role] = mode
+	}
+	return policy, nil
+}
+
+// Mode returns the privacy mode for a role
+func (p Policy) Mode(role string) string {
+	if mode, ok := p[role]; ok {
+		return mode
+	}
+	return ModeNone
+}
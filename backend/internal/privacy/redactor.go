@@ -0,0 +1,176 @@
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Privacy modes controlling how end-user emails are shown
+const (
+	ModeNone = "none"
+	ModeMask = "mask"
+	ModeHash = "hash"
+)
+
+// Modes lists every privacy mode
+var Modes = []string{ModeNone, ModeMask, ModeHash}
+
+// pseudonymPrefix starts every pseudonymous user ID
+const pseudonymPrefix = "user-"
+
+// digestPrefix starts every email digest
+const digestPrefix = "hmac-sha256:"
+
+var (
+	// emailPattern matches email addresses embedded in free text
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// pseudonymPattern matches pseudonymous user IDs in queries
+	pseudonymPattern = regexp.MustCompile(`\buser-[0-9a-f]{12}\b`)
+)
+
+// Redactor masks or pseudonymizes emails
+// Pseudonyms are keyed HMACs, so they are stable across restarts as long as
+// the key is kept, and cannot be reversed without the emails seen at ingest
+type Redactor struct {
+	key []byte
+
+	mu      sync.RWMutex
+	reverse map[string]string
+}
+
+// NewRedactor creates a redactor with a pseudonym key
+func NewRedactor(key []byte) *Redactor {
+	return &Redactor{
+		key:     key,
+		reverse: make(map[string]string),
+	}
+}
+
+// LoadOrCreateKey reads the pseudonym key from path, generating and saving one if it does not exist
+func LoadOrCreateKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) < 16 {
+			return nil, fmt.Errorf("invalid privacy key in %s", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read privacy key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create privacy key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write privacy key: %w", err)
+	}
+	return key, nil
+}
+
+// Pseudonym returns the stable pseudonymous ID for an email and remembers it for Resolve
+func (r *Redactor) Pseudonym(email string) string {
+	normalized := strings.ToLower(email)
+
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(normalized))
+	pseudonym := pseudonymPrefix + hex.EncodeToString(mac.Sum(nil))[:12]
+
+	r.mu.RLock()
+	_, known := r.reverse[pseudonym]
+	r.mu.RUnlock()
+	if !known {
+		r.mu.Lock()
+		r.reverse[pseudonym] = email
+		r.mu.Unlock()
+	}
+	return pseudonym
+}
+
+// Digest returns the keyed digest of an email that audit and purge records hold instead of it
+// Without the key, nobody can check a guessed address against a record; the input is
+// prefixed so a digest cannot be matched to the same email's pseudonym
+func (r *Redactor) Digest(email string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte("digest:" + strings.ToLower(strings.TrimSpace(email))))
+	return digestPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Register records the emails in content so their pseudonyms can be resolved later
+func (r *Redactor) Register(content string) {
+	for _, email := range emailPattern.FindAllString(content, -1) {
+		r.Pseudonym(email)
+	}
+}
+
+// Resolve returns the email behind a pseudonym seen by this redactor
+func (r *Redactor) Resolve(pseudonym string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	email, ok := r.reverse[pseudonym]
+	return email, ok
+}
+
+// ResolveQuery replaces pseudonyms in a query with quoted emails, so drill-downs
+// from redacted results find the same user's events
+func (r *Redactor) ResolveQuery(query string) string {
+	return pseudonymPattern.ReplaceAllStringFunc(query, func(pseudonym string) string {
+		if email, ok := r.Resolve(pseudonym); ok {
+			return `"` + email + `"`
+		}
+		return pseudonym
+	})
+}
+
+// Email presents an email according to mode
+func (r *Redactor) Email(email, mode string) string {
+	switch mode {
+	case ModeHash:
+		return r.Pseudonym(email)
+	case ModeMask:
+		return maskEmail(email)
+	default:
+		return email
+	}
+}
+
+// Text redacts every email embedded in free text according to mode
+func (r *Redactor) Text(text, mode string) string {
+	if mode != ModeHash && mode != ModeMask {
+		return text
+	}
+	return emailPattern.ReplaceAllStringFunc(text, func(email string) string {
+		return r.Email(email, mode)
+	})
+}
+
+// Emails returns the email addresses embedded in free text, each one whole
+func Emails(text string) []string {
+	return emailPattern.FindAllString(text, -1)
+}
+
+// StripEmails removes the email addresses embedded in free text
+func StripEmails(text string) string {
+	return emailPattern.ReplaceAllString(text, " ")
+}
+
+// maskEmail keeps the first character of the local part and the domain: "j***@acme.com"
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
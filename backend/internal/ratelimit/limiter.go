@@ -0,0 +1,122 @@
+// Package ratelimit limits how often each client can call the API.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sweepInterval is how often buckets of idle clients are dropped
+const sweepInterval = time.Minute
+
+// bucket holds a client's remaining requests as of updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter is a token bucket per client: each client can make burst requests at once,
+// refilled at perMinute requests a minute
+type Limiter struct {
+	perMinute int
+	burst     int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates a limiter; a perMinute of zero disables limiting
+// A burst below one allows a single request at a time
+func NewLimiter(perMinute, burst int) *Limiter {
+	return &Limiter{
+		perMinute: perMinute,
+		burst:     max(burst, 1),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes one request from key's bucket
+// It returns the requests left, and when none are left how long until the next one is allowed
+func (l *Limiter) Allow(key string, now time.Time) (remaining int, wait time.Duration, ok bool) {
+	if l.perMinute <= 0 {
+		return l.burst, 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweepLocked(now)
+	}
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(l.burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / l.ratePerSecond() * float64(time.Second))
+		return 0, wait, false
+	}
+	b.tokens--
+	return int(b.tokens), 0, true
+}
+
+// refill returns the tokens a bucket holds at now
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed <= 0 {
+		return b.tokens
+	}
+	return math.Min(float64(l.burst), b.tokens+elapsed*l.ratePerSecond())
+}
+
+func (l *Limiter) ratePerSecond() float64 {
+	return float64(l.perMinute) / 60
+}
+
+// sweepLocked drops buckets that have refilled, since a new bucket starts full anyway; caller must hold mu
+func (l *Limiter) sweepLocked(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware rejects requests over the limit with 429 Too Many Requests
+// key names the client a request counts against
+func (l *Limiter) Middleware(key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.perMinute <= 0 {
+			c.Next()
+			return
+		}
+
+		remaining, wait, ok := l.Allow(key(c), time.Now())
+		c.Header("X-RateLimit-Limit", strconv.Itoa(l.burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Rate limit exceeded",
+				"details": fmt.Sprintf("at most %d requests a minute, retry in %ds", l.perMinute, retryAfter),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAllowRefillsAtTheConfiguredRate(t *testing.T) {
+	// One request a second, two at once
+	limiter := NewLimiter(60, 2)
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name      string
+		at        time.Duration
+		key       string
+		ok        bool
+		remaining int
+		wait      time.Duration
+	}{
+		{"first of the burst", 0, "a", true, 1, 0},
+		{"second of the burst", 0, "a", true, 0, 0},
+		{"burst spent", 0, "a", false, 0, time.Second},
+		{"other client", 0, "b", true, 1, 0},
+		{"half refilled", 500 * time.Millisecond, "a", false, 0, 500 * time.Millisecond},
+		{"one refilled", time.Second, "a", true, 0, 0},
+		{"spent again", time.Second, "a", false, 0, time.Second},
+		{"refill stops at the burst", time.Minute, "a", true, 1, 0},
+		{"no more than the burst", time.Minute, "a", true, 0, 0},
+		{"over the burst", time.Minute, "a", false, 0, time.Second},
+	}
+	for _, step := range steps {
+		remaining, wait, ok := limiter.Allow(step.key, start.Add(step.at))
+		if ok != step.ok || remaining != step.remaining || wait != step.wait {
+			t.Errorf("%s: Allow = (%d, %v, %v), want (%d, %v, %v)", step.name, remaining, wait, ok, step.remaining, step.wait, step.ok)
+		}
+	}
+}
+
+func TestMiddlewareSetsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// One request a minute, so the next is allowed in just under 60 seconds
+	router.Use(NewLimiter(1, 1).Middleware(func(c *gin.Context) string { return "client" }))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/", nil))
+	if first.Code != http.StatusOK {
+		t.Fatalf("first request: status %d, want %d", first.Code, http.StatusOK)
+	}
+
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/", nil))
+	if second.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d, want %d", second.Code, http.StatusTooManyRequests)
+	}
+	if got := second.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+	if got := second.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, want %q", got, "0")
+	}
+}
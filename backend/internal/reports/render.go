@@ -0,0 +1,128 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+	"usage-analytics-dashboard/internal/export"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// FormatHTML is the report-only HTML format; CSV and JSON come from the export package
+const FormatHTML = "html"
+
+// IsFormat reports whether format can be used for scheduled reports
+func IsFormat(format string) bool {
+	return format == export.FormatCSV || format == export.FormatJSON || format == FormatHTML
+}
+
+// Render writes an analytics response in the given report format
+func Render(w io.Writer, format string, definition models.ReportDefinition, response models.AnalyticsResponse, generatedAt time.Time) error {
+	switch format {
+	case export.FormatCSV:
+		return export.WriteCSV(w, export.AnalyticsTables(response))
+	case export.FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Report      string                   `json:"report"`
+			GeneratedAt time.Time                `json:"generatedAt"`
+			Query       models.QueryParams       `json:"query"`
+			Analytics   models.AnalyticsResponse `json:"analytics"`
+		}{definition.Name, generatedAt, definition.Query, response})
+	case FormatHTML:
+		return htmlTemplate.Execute(w, htmlReport{
+			Definition:  definition,
+			GeneratedAt: generatedAt.UTC().Format(time.RFC1123),
+			Response:    response,
+			Trends:      trendRows(response.Trends),
+		})
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// htmlReport is the data passed to the HTML report template
+type htmlReport struct {
+	Definition  models.ReportDefinition
+	GeneratedAt string
+	Response    models.AnalyticsResponse
+	Trends      []trendRow
+}
+
+// trendRow is a single company's total events over the report period
+type trendRow struct {
+	Company string
+	Events  int
+	Days    int
+}
+
+// trendRows summarizes per-company trends for the HTML report
+func trendRows(trends models.UsageTrends) []trendRow {
+	rows := make([]trendRow, 0, len(trends.Trends))
+	for company, series := range trends.Trends {
+		row := trendRow{Company: company, Days: len(series)}
+		for _, point := range series {
+			row.Events += point.Events
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Events > rows[j].Events
+	})
+	return rows
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"rank": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Definition.Name}} - Usage Report</title>
+<style>
+body { font-family: -apple-system, Segoe UI, sans-serif; margin: 2rem; color: #1f2937; }
+table { border-collapse: collapse; margin-bottom: 2rem; min-width: 24rem; }
+th, td { border: 1px solid #e5e7eb; padding: 0.4rem 0.8rem; text-align: left; }
+th { background: #f3f4f6; }
+.muted { color: #6b7280; }
+</style>
+</head>
+<body>
+<h1>{{.Definition.Name}}</h1>
+<p class="muted">Generated {{.GeneratedAt}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Total Events</th><td>{{.Response.Summary.TotalEvents}}</td></tr>
+<tr><th>Total Companies</th><td>{{.Response.Summary.TotalCompanies}}</td></tr>
+<tr><th>Peak Usage Day</th><td>{{.Response.Summary.PeakUsageDay}}</td></tr>
+</table>
+
+<h2>Companies</h2>
+<table>
+<tr><th>Name</th><th>Events</th><th>Active Users</th><th>Last Activity</th></tr>
+{{range .Response.Companies}}<tr><td>{{.Name}}</td><td>{{.EventCount}}</td><td>{{.ActiveUsers}}</td><td>{{.LastActivity.Format "2006-01-02 15:04"}}</td></tr>
+{{else}}<tr><td colspan="4" class="muted">No activity</td></tr>
+{{end}}</table>
+
+<h2>Trends</h2>
+<table>
+<tr><th>Company</th><th>Events</th><th>Days</th></tr>
+{{range .Trends}}<tr><td>{{.Company}}</td><td>{{.Events}}</td><td>{{.Days}}</td></tr>
+{{else}}<tr><td colspan="3" class="muted">No activity</td></tr>
+{{end}}</table>
+
+<h2>Top Users</h2>
+<table>
+<tr><th>#</th><th>Email</th><th>Company</th><th>Events</th></tr>
+{{range $i, $user := .Response.TopUsers}}<tr><td>{{rank $i}}</td><td>{{$user.Email}}</td><td>{{$user.CompanyName}}</td><td>{{$user.EventCount}}</td></tr>
+{{else}}<tr><td colspan="4" class="muted">No activity</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
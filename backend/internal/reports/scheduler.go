@@ -0,0 +1,330 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/views"
+)
+
+// fileTimeLayout is the timestamp embedded in generated report file names
+const fileTimeLayout = "20060102T150405Z"
+
+// reportNamePattern restricts report names to safe file name characters
+var reportNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ErrReportNotFound is returned when a generated report file does not exist
+var ErrReportNotFound = errors.New("report not found")
+
+// job is a report definition paired with its parsed schedule
+type job struct {
+	definition models.ReportDefinition
+	schedule   *Schedule
+	next       time.Time
+}
+
+// Scheduler runs report definitions on their cron schedules and writes the results to disk
+type Scheduler struct {
+	analyticsService *services.AnalyticsService
+	viewStore        *views.Store
+	dir              string
+	location         *time.Location
+	jobs             []*job
+
+	// mu serializes report generation and retention so files are never removed mid-write
+	mu sync.Mutex
+}
+
+// LoadDefinitions reads report definitions from a JSON file
+// A missing file is not an error and yields no definitions
+func LoadDefinitions(path string) ([]models.ReportDefinition, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report definitions: %w", err)
+	}
+
+	var definitions []models.ReportDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("failed to parse report definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// NewScheduler validates the report definitions and creates a scheduler writing into dir
+func NewScheduler(analyticsService *services.AnalyticsService, viewStore *views.Store, dir string, definitions []models.ReportDefinition) (*Scheduler, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	scheduler := &Scheduler{
+		analyticsService: analyticsService,
+		viewStore:        viewStore,
+		dir:              dir,
+		location:         time.UTC,
+	}
+
+	seen := make(map[string]bool)
+	for _, definition := range definitions {
+		if !reportNamePattern.MatchString(definition.Name) {
+			return nil, fmt.Errorf("report name %q must only contain letters, digits, '-' and '_'", definition.Name)
+		}
+		if seen[definition.Name] {
+			return nil, fmt.Errorf("duplicate report name %q", definition.Name)
+		}
+		seen[definition.Name] = true
+
+		schedule, err := ParseSchedule(definition.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("report %q: %w", definition.Name, err)
+		}
+
+		if len(definition.Formats) == 0 {
+			definition.Formats = []string{FormatHTML}
+		}
+		for _, format := range definition.Formats {
+			if !IsFormat(format) {
+				return nil, fmt.Errorf("report %q: unsupported format %q", definition.Name, format)
+			}
+		}
+
+		scheduler.jobs = append(scheduler.jobs, &job{definition: definition, schedule: schedule})
+	}
+
+	return scheduler, nil
+}
+
+// Start runs the scheduler loop until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	now := time.Now().In(s.location)
+	for _, j := range s.jobs {
+		j.next = j.schedule.Next(now)
+		log.Printf("Report %q scheduled, next run at %s", j.definition.Name, j.next.Format(time.RFC3339))
+	}
+
+	// Enforce retention for anything generated before a restart
+	for _, j := range s.jobs {
+		s.applyRetention(j.definition, now)
+	}
+
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tick := <-ticker.C:
+				s.runDue(tick.In(s.location))
+			}
+		}
+	}()
+}
+
+// runDue generates every report whose next run time has passed
+func (s *Scheduler) runDue(now time.Time) {
+	for _, j := range s.jobs {
+		if j.next.IsZero() || now.Before(j.next) {
+			continue
+		}
+
+		if _, err := s.Generate(j.definition, now); err != nil {
+			log.Printf("Failed to generate report %q: %v", j.definition.Name, err)
+		}
+		j.next = j.schedule.Next(now)
+	}
+}
+
+// Generate runs a report's query and writes one file per format
+func (s *Scheduler) Generate(definition models.ReportDefinition, now time.Time) ([]models.ReportFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Resolve saved views at run time so edits to the view apply to the next run
+	if definition.View != "" {
+		view, err := s.viewStore.Get(definition.View)
+		if err != nil {
+			return nil, fmt.Errorf("view %q: %w", definition.View, err)
+		}
+		definition.Query = view.Query
+	}
+
+	response := s.analyticsService.GenerateAnalytics(definition.Query)
+	generatedAt := now.UTC()
+
+	var files []models.ReportFile
+	for _, format := range definition.Formats {
+		name := fmt.Sprintf("%s-%s.%s", definition.Name, generatedAt.Format(fileTimeLayout), format)
+		path := filepath.Join(s.dir, name)
+
+		if err := s.writeFile(path, format, definition, response, generatedAt); err != nil {
+			return files, err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, models.ReportFile{
+			Name:      name,
+			Report:    definition.Name,
+			Format:    format,
+			Size:      info.Size(),
+			CreatedAt: generatedAt,
+		})
+		log.Printf("Generated report %s", name)
+	}
+
+	s.applyRetentionLocked(definition, now)
+	return files, nil
+}
+
+// writeFile renders a report into a temporary file and renames it into place
+func (s *Scheduler) writeFile(path, format string, definition models.ReportDefinition, response models.AnalyticsResponse, generatedAt time.Time) error {
+	tmp, err := os.CreateTemp(s.dir, ".report-*")
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Render(tmp, format, definition, response, generatedAt); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to render %s report: %w", format, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// List returns generated report files, newest first
+func (s *Scheduler) List() ([]models.ReportFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+
+	files := []models.ReportFile{}
+	for _, entry := range entries {
+		file, ok := parseReportFileName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			file.Size = info.Size()
+		}
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].CreatedAt.Equal(files[j].CreatedAt) {
+			return files[i].CreatedAt.After(files[j].CreatedAt)
+		}
+		return files[i].Name < files[j].Name
+	})
+
+	return files, nil
+}
+
+// Path resolves a generated report file name to its location on disk
+func (s *Scheduler) Path(name string) (string, error) {
+	// Only names produced by the scheduler are served, which rules out path traversal
+	if _, ok := parseReportFileName(name); !ok {
+		return "", ErrReportNotFound
+	}
+
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrReportNotFound
+	}
+	return path, nil
+}
+
+// applyRetention removes report files outside the definition's retention policy
+func (s *Scheduler) applyRetention(definition models.ReportDefinition, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applyRetentionLocked(definition, now)
+}
+
+// applyRetentionLocked is applyRetention for callers already holding mu
+func (s *Scheduler) applyRetentionLocked(definition models.ReportDefinition, now time.Time) {
+	retention := definition.Retention
+	if retention.MaxFiles <= 0 && retention.MaxAgeDays <= 0 {
+		return
+	}
+
+	files, err := s.List()
+	if err != nil {
+		log.Printf("Failed to apply retention for report %q: %v", definition.Name, err)
+		return
+	}
+
+	// Count runs rather than files so every format of a run is kept together
+	runs := 0
+	var lastRun time.Time
+	cutoff := now.AddDate(0, 0, -retention.MaxAgeDays)
+
+	for _, file := range files {
+		if file.Report != definition.Name {
+			continue
+		}
+		if !file.CreatedAt.Equal(lastRun) {
+			runs++
+			lastRun = file.CreatedAt
+		}
+
+		expired := retention.MaxAgeDays > 0 && file.CreatedAt.Before(cutoff)
+		excess := retention.MaxFiles > 0 && runs > retention.MaxFiles
+		if expired || excess {
+			if err := os.Remove(filepath.Join(s.dir, file.Name)); err != nil {
+				log.Printf("Failed to remove expired report %s: %v", file.Name, err)
+				continue
+			}
+			log.Printf("Removed expired report %s", file.Name)
+		}
+	}
+}
+
+// parseReportFileName extracts report metadata from a generated file name
+func parseReportFileName(name string) (models.ReportFile, bool) {
+	ext := filepath.Ext(name)
+	format := strings.TrimPrefix(ext, ".")
+	if !IsFormat(format) {
+		return models.ReportFile{}, false
+	}
+
+	base := strings.TrimSuffix(name, ext)
+	separator := strings.LastIndex(base, "-")
+	if separator <= 0 {
+		return models.ReportFile{}, false
+	}
+
+	report := base[:separator]
+	createdAt, err := time.Parse(fileTimeLayout, base[separator+1:])
+	if err != nil || !reportNamePattern.MatchString(report) {
+		return models.ReportFile{}, false
+	}
+
+	return models.ReportFile{
+		Name:      name,
+		Report:    report,
+		Format:    format,
+		CreatedAt: createdAt,
+	}, true
+}
@@ -0,0 +1,530 @@
+package retention
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// Purge kinds recorded in the audit log
+const (
+	PurgeRetention = "retention"
+	PurgeErasure   = "erasure"
+)
+
+// ErrInvalidUser is returned when an erasure request does not name a user
+var ErrInvalidUser = errors.New("user must be an email address or a known pseudonymous user ID")
+
+// DefaultMaxPurgePercent is used when a policy sets no MaxPurgePercent
+const DefaultMaxPurgePercent = 10
+
+// PurgeTooLargeError is returned when a retention run would remove a larger share of
+// raw events than the policy allows without confirmation; nothing is purged then
+type PurgeTooLargeError struct {
+	Cutoff     time.Time
+	Events     int
+	Total      int
+	MaxPercent int
+}
+
+func (e *PurgeTooLargeError) Error() string {
+	return fmt.Sprintf("retention would purge %d of %d raw events, created before %s, which is more than the %d%% allowed without confirmation",
+		e.Events, e.Total, e.Cutoff.Format(dateLayout), e.MaxPercent)
+}
+
+// dateLayout formats aggregate days
+const dateLayout = "2006-01-02"
+
+// managerState is the on-disk representation of the manager
+type managerState struct {
+	// Tombstones are the IDs of purged events, removed again whenever the
+	// source data is reloaded so purges survive restarts
+	Tombstones []string                `json:"tombstones"`
+	Aggregates []models.DailyAggregate `json:"aggregates"`
+	Purges     []models.PurgeRecord    `json:"purges"`
+	LastRun    *time.Time              `json:"lastRun,omitempty"`
+}
+
+// Manager applies the retention policy, erases users on request and keeps
+// the archived daily aggregates and purge audit records
+type Manager struct {
+	analyticsService *services.AnalyticsService
+	path             string
+	policy           models.RetentionPolicy
+	rawEvents        Period
+	dailyAggregates  Period
+	maxPurgePercent  int
+
+	mu         sync.Mutex
+	tombstones map[string]bool
+	aggregates map[string]models.DailyAggregate
+	purges     []models.PurgeRecord
+	lastRun    *time.Time
+
+	subscribers []PurgeSubscriber
+}
+
+// PurgeSubscriber is called with the audit record of every purge
+// Subscribers run while the manager is locked and must not call back into it
+type PurgeSubscriber func(record models.PurgeRecord)
+
+// NewManager loads retention state from path, starting empty if the file does not exist,
+// and removes previously purged events from the analytics service
+func NewManager(analyticsService *services.AnalyticsService, path string, policy models.RetentionPolicy) (*Manager, error) {
+	rawEvents, err := ParsePeriod(policy.RawEvents)
+	if err != nil {
+		return nil, fmt.Errorf("raw events: %w", err)
+	}
+	dailyAggregates, err := ParsePeriod(policy.DailyAggregates)
+	if err != nil {
+		return nil, fmt.Errorf("daily aggregates: %w", err)
+	}
+	if policy.MaxPurgePercent < 0 || policy.MaxPurgePercent > 100 {
+		return nil, fmt.Errorf("max purge percent must be between 0 (the default, %d) and 100", DefaultMaxPurgePercent)
+	}
+	if policy.MaxPurgePercent == 0 {
+		policy.MaxPurgePercent = DefaultMaxPurgePercent
+	}
+
+	manager := &Manager{
+		analyticsService: analyticsService,
+		path:             path,
+		policy:           policy,
+		rawEvents:        rawEvents,
+		dailyAggregates:  dailyAggregates,
+		maxPurgePercent:  policy.MaxPurgePercent,
+		tombstones:       make(map[string]bool),
+		aggregates:       make(map[string]models.DailyAggregate),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return manager, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention file: %w", err)
+	}
+
+	var state managerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse retention file: %w", err)
+	}
+	for _, id := range state.Tombstones {
+		manager.tombstones[id] = true
+	}
+	for _, aggregate := range state.Aggregates {
+		manager.aggregates[aggregateKey(aggregate.Date, aggregate.CompanyID)] = aggregate
+	}
+	manager.purges = state.Purges
+	manager.lastRun = state.LastRun
+
+	if len(manager.tombstones) > 0 {
+		analyticsService.Purge(func(event models.UsageEvent) bool {
+			return manager.tombstones[event.ID]
+		})
+	}
+
+	return manager, nil
+}
+
+// Subscribe registers a function to be called after every recorded purge
+func (m *Manager) Subscribe(subscriber PurgeSubscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, subscriber)
+}
+
+// Start applies the policy immediately and then on the given interval until ctx is cancelled
+// Runs that would purge more than the policy's MaxPurgePercent are skipped until confirmed through Run
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var tooLarge *PurgeTooLargeError
+			if _, err := m.Run(false); errors.As(err, &tooLarge) {
+				log.Printf("Retention skipped: %v; check the policy, then POST /api/retention/run?confirm=true", err)
+			} else if err != nil {
+				log.Printf("Retention run failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run applies the policy as of the analytics clock, which date ranges are measured from too
+func (m *Manager) Run(confirm bool) (*models.PurgeRecord, error) {
+	return m.Apply(m.analyticsService.Now(), confirm)
+}
+
+// DryRun returns the record Run would produce, without purging anything
+func (m *Manager) DryRun() *models.PurgeRecord {
+	return m.Preview(m.analyticsService.Now())
+}
+
+// Apply purges raw events older than the policy allows at now, archiving their daily
+// aggregates first, and drops archived aggregates past their own retention
+// Unless confirm is set, it fails with a PurgeTooLargeError instead of removing more
+// than the policy's MaxPurgePercent of raw events
+// It returns the audit record of the purge, or nil when nothing was removed
+func (m *Manager) Apply(now time.Time, confirm bool) (*models.PurgeRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var record *models.PurgeRecord
+	if !m.rawEvents.Forever() {
+		cutoff := m.rawEvents.Cutoff(now)
+		if !confirm {
+			expired, total := m.expired(cutoff)
+			if len(expired)*100 > total*m.maxPurgePercent {
+				return nil, &PurgeTooLargeError{Cutoff: cutoff, Events: len(expired), Total: total, MaxPercent: m.maxPurgePercent}
+			}
+		}
+
+		removed := m.analyticsService.Purge(func(event models.UsageEvent) bool {
+			return event.CreatedAt.Before(cutoff)
+		})
+
+		if len(removed) > 0 {
+			m.archiveLocked(removed)
+			m.tombstoneLocked(removed)
+			record = &models.PurgeRecord{
+				ID:            newID(),
+				Kind:          PurgeRetention,
+				Cutoff:        &cutoff,
+				RequestedBy:   "system",
+				EventsRemoved: len(removed),
+				Companies:     companies(removed),
+				At:            time.Now().UTC(),
+			}
+			m.purges = append(m.purges, *record)
+			log.Printf("Retention purged %d events created before %s", len(removed), cutoff.Format(dateLayout))
+		}
+	}
+
+	if !m.dailyAggregates.Forever() {
+		cutoff := m.dailyAggregates.Cutoff(now).Format(dateLayout)
+		for key, aggregate := range m.aggregates {
+			if aggregate.Date < cutoff {
+				delete(m.aggregates, key)
+			}
+		}
+	}
+
+	ranAt := time.Now().UTC()
+	m.lastRun = &ranAt
+	if err := m.saveLocked(); err != nil {
+		return nil, err
+	}
+	if record != nil {
+		m.notifyLocked(*record)
+	}
+	return record, nil
+}
+
+// Preview returns the record Apply would produce at now, without purging anything
+// It returns nil when the policy would remove no raw events
+func (m *Manager) Preview(now time.Time) *models.PurgeRecord {
+	if m.rawEvents.Forever() {
+		return nil
+	}
+	cutoff := m.rawEvents.Cutoff(now)
+	expired, _ := m.expired(cutoff)
+	if len(expired) == 0 {
+		return nil
+	}
+	return &models.PurgeRecord{
+		Kind:          PurgeRetention,
+		Cutoff:        &cutoff,
+		RequestedBy:   "system",
+		EventsRemoved: len(expired),
+		Companies:     companies(expired),
+		At:            time.Now().UTC(),
+	}
+}
+
+// Erase removes every event made by or mentioning a user, identified by email or
+// pseudonymous ID, and records the erasure
+// Archived aggregates hold no emails, so only raw events are affected; live
+// aggregates are computed from raw events and reflect the erasure immediately
+func (m *Manager) Erase(user, requestedBy string) (models.PurgeRecord, error) {
+	email := m.analyticsService.ResolveUser(strings.TrimSpace(user))
+	if !strings.Contains(email, "@") {
+		return models.PurgeRecord{}, ErrInvalidUser
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := m.analyticsService.Purge(func(event models.UsageEvent) bool {
+		return services.ContainsUser(event, email)
+	})
+	m.tombstoneLocked(removed)
+
+	// The record proves the erasure happened without keeping the email
+	record := models.PurgeRecord{
+		ID:            newID(),
+		Kind:          PurgeErasure,
+		Subject:       m.analyticsService.DigestUser(email),
+		RequestedBy:   requestedBy,
+		EventsRemoved: len(removed),
+		Companies:     companies(removed),
+		At:            time.Now().UTC(),
+	}
+	m.purges = append(m.purges, record)
+
+	if err := m.saveLocked(); err != nil {
+		return models.PurgeRecord{}, err
+	}
+	m.notifyLocked(record)
+	return record, nil
+}
+
+// Status returns the policy and what it currently covers
+func (m *Manager) Status() models.RetentionStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := models.RetentionStatus{
+		Policy:             m.policy,
+		ArchivedAggregates: len(m.aggregates),
+		LastRun:            m.lastRun,
+	}
+	status.Policy.MaxPurgePercent = m.maxPurgePercent
+	if !m.rawEvents.Forever() {
+		cutoff := m.rawEvents.Cutoff(m.analyticsService.Now())
+		status.RawEventsCutoff = &cutoff
+	}
+
+	events := m.analyticsService.Events()
+	status.RawEvents = len(events)
+	for _, event := range events {
+		if status.OldestEvent == nil || event.CreatedAt.Before(*status.OldestEvent) {
+			oldest := event.CreatedAt
+			status.OldestEvent = &oldest
+		}
+	}
+	return status
+}
+
+// Purges returns the most recent purge records, newest first
+func (m *Manager) Purges(limit int) []models.PurgeRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := []models.PurgeRecord{}
+	for i := len(m.purges) - 1; i >= 0 && len(records) < limit; i-- {
+		records = append(records, m.purges[i])
+	}
+	return records
+}
+
+// DailyAggregates returns per-day, per-company activity between two dates inclusive,
+// combining archived aggregates with live ones computed from raw events
+// A nil scope allows every company; zero dates leave the range open
+func (m *Manager) DailyAggregates(scope *models.CompanyScope, companyID string, fromDate, toDate time.Time) []models.DailyAggregate {
+	allowed := func(date, id string) bool {
+		if scope != nil && !slices.Contains(scope.CompanyIDs, id) {
+			return false
+		}
+		if companyID != "" && id != companyID {
+			return false
+		}
+		if !fromDate.IsZero() && date < fromDate.Format(dateLayout) {
+			return false
+		}
+		return toDate.IsZero() || date <= toDate.Format(dateLayout)
+	}
+
+	merged := make(map[string]models.DailyAggregate)
+	for _, aggregate := range m.analyticsService.DailyActivity() {
+		if allowed(aggregate.Date, aggregate.CompanyID) {
+			merged[aggregateKey(aggregate.Date, aggregate.CompanyID)] = aggregate
+		}
+	}
+
+	m.mu.Lock()
+	for key, archived := range m.aggregates {
+		if !allowed(archived.Date, archived.CompanyID) {
+			continue
+		}
+		if live, ok := merged[key]; ok {
+			// Late events for an archived day have not been purged yet
+			archived = mergeAggregates(archived, live)
+		}
+		merged[key] = archived
+	}
+	m.mu.Unlock()
+
+	aggregates := make([]models.DailyAggregate, 0, len(merged))
+	for _, aggregate := range merged {
+		// Prefer the canonical name; companies with no remaining events keep their archived one
+		if name := m.analyticsService.CompanyName(aggregate.CompanyID); name != aggregate.CompanyID {
+			aggregate.CompanyName = name
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		if aggregates[i].Date != aggregates[j].Date {
+			return aggregates[i].Date < aggregates[j].Date
+		}
+		return aggregates[i].CompanyID < aggregates[j].CompanyID
+	})
+	return aggregates
+}
+
+// expired returns the raw events created before cutoff, and how many raw events there are
+func (m *Manager) expired(cutoff time.Time) ([]models.UsageEvent, int) {
+	events := m.analyticsService.Events()
+	var expired []models.UsageEvent
+	for _, event := range events {
+		if event.CreatedAt.Before(cutoff) {
+			expired = append(expired, event)
+		}
+	}
+	return expired, len(events)
+}
+
+// archiveLocked folds purged events into the archived daily aggregates; caller must hold mu
+func (m *Manager) archiveLocked(events []models.UsageEvent) {
+	for key, live := range aggregate(events) {
+		if archived, ok := m.aggregates[key]; ok {
+			live = mergeAggregates(archived, live)
+		}
+		live.Archived = true
+		m.aggregates[key] = live
+	}
+}
+
+// notifyLocked passes a purge record to every subscriber; caller must hold mu
+func (m *Manager) notifyLocked(record models.PurgeRecord) {
+	for _, subscriber := range m.subscribers {
+		subscriber(record)
+	}
+}
+
+// tombstoneLocked remembers purged event IDs; caller must hold mu
+func (m *Manager) tombstoneLocked(events []models.UsageEvent) {
+	for _, event := range events {
+		m.tombstones[event.ID] = true
+	}
+}
+
+// saveLocked writes the state to disk atomically; caller must hold mu
+func (m *Manager) saveLocked() error {
+	state := managerState{
+		Tombstones: make([]string, 0, len(m.tombstones)),
+		Aggregates: make([]models.DailyAggregate, 0, len(m.aggregates)),
+		Purges:     m.purges,
+		LastRun:    m.lastRun,
+	}
+	for id := range m.tombstones {
+		state.Tombstones = append(state.Tombstones, id)
+	}
+	sort.Strings(state.Tombstones)
+	for _, aggregate := range m.aggregates {
+		state.Aggregates = append(state.Aggregates, aggregate)
+	}
+	sort.Slice(state.Aggregates, func(i, j int) bool {
+		return aggregateKey(state.Aggregates[i].Date, state.Aggregates[i].CompanyID) <
+			aggregateKey(state.Aggregates[j].Date, state.Aggregates[j].CompanyID)
+	})
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode retention state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create retention directory: %w", err)
+	}
+
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write retention file: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to write retention file: %w", err)
+	}
+	return nil
+}
+
+// aggregate summarizes events per day and company, keyed by aggregateKey
+func aggregate(events []models.UsageEvent) map[string]models.DailyAggregate {
+	aggregates := make(map[string]models.DailyAggregate)
+	users := make(map[string]map[string]bool)
+
+	for _, event := range events {
+		date := event.CreatedAt.UTC().Format(dateLayout)
+		key := aggregateKey(date, event.CompanyID)
+		content := utils.ParseEventContent(event.Content)
+
+		daily, ok := aggregates[key]
+		if !ok {
+			daily = models.DailyAggregate{Date: date, CompanyID: event.CompanyID, CompanyName: content.CompanyName}
+			users[key] = make(map[string]bool)
+		}
+		daily.Events++
+		if content.Email != "" && !users[key][content.Email] {
+			users[key][content.Email] = true
+			daily.ActiveUsers++
+		}
+		aggregates[key] = daily
+	}
+
+	return aggregates
+}
+
+// mergeAggregates adds a later aggregate for the same day and company to an archived one
+// Users cannot be de-duplicated across the two, so the larger count is kept as a lower bound
+func mergeAggregates(archived, later models.DailyAggregate) models.DailyAggregate {
+	archived.Events += later.Events
+	archived.ActiveUsers = max(archived.ActiveUsers, later.ActiveUsers)
+	if archived.CompanyName == "" {
+		archived.CompanyName = later.CompanyName
+	}
+	return archived
+}
+
+// aggregateKey identifies one day of one company
+func aggregateKey(date, companyID string) string {
+	return date + "|" + companyID
+}
+
+// companies returns the sorted distinct company IDs of events
+func companies(events []models.UsageEvent) []string {
+	ids := []string{}
+	for _, event := range events {
+		if !slices.Contains(ids, event.CompanyID) {
+			ids = append(ids, event.CompanyID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// newID generates a random purge record identifier
+func newID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf) // crypto/rand.Read never fails since Go 1.24
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,172 @@
+package retention
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/services"
+)
+
+func testEvent(id, content string) models.UsageEvent {
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	return models.UsageEvent{
+		ID:                id,
+		CreatedAt:         created,
+		CompanyID:         "company-1",
+		Type:              "Action",
+		Content:           content,
+		UpdatedAt:         created,
+		OriginalTimestamp: created,
+	}
+}
+
+func TestEraseRemovesOnlyTheExactUser(t *testing.T) {
+	events := []models.UsageEvent{
+		testEvent("1", "Login - Sample Company s.cherveny@sample.com /home"),
+		testEvent("2", "Login - Sample Company wes.cherveny@sample.com /home"),
+		testEvent("3", "Open - Sample Company S.Cherveny@sample.com /work-orders"),
+		testEvent("4", "Login - Sample Company jo@sample.com /home"),
+	}
+	path := filepath.Join(t.TempDir(), "retention.json")
+	redactor := privacy.NewRedactor([]byte("0123456789abcdef"))
+	service := services.NewAnalyticsService(events, redactor, nil)
+	manager, err := NewManager(service, path, models.RetentionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := manager.Erase(" S.Cherveny@sample.com ", "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.EventsRemoved != 2 {
+		t.Errorf("EventsRemoved = %d, want 2", record.EventsRemoved)
+	}
+	// The audit log digests the address the same way, so both records of an erasure match
+	if want := redactor.Digest("s.cherveny@sample.com"); record.Subject != want {
+		t.Errorf("Subject = %q, want the keyed digest %q", record.Subject, want)
+	}
+	assertEventIDs(t, service.Events(), "2", "4")
+
+	// Tombstones only cover the erased user's events, so a reload keeps everyone else's
+	reloaded := services.NewAnalyticsService(events, redactor, nil)
+	if _, err := NewManager(reloaded, path, models.RetentionPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	assertEventIDs(t, reloaded.Events(), "2", "4")
+}
+
+func TestEraseRejectsUnknownUsers(t *testing.T) {
+	service := services.NewAnalyticsService([]models.UsageEvent{testEvent("1", "Login - Acme a@acme.com /home")}, nil, nil)
+	manager, err := NewManager(service, filepath.Join(t.TempDir(), "retention.json"), models.RetentionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Erase("not-an-email", "tester"); err != ErrInvalidUser {
+		t.Errorf("Erase error = %v, want ErrInvalidUser", err)
+	}
+}
+
+// datedEvents returns one event per age, created that many days before now
+func datedEvents(now time.Time, ages ...int) []models.UsageEvent {
+	var events []models.UsageEvent
+	for i, age := range ages {
+		event := testEvent(fmt.Sprint(i+1), "Login - Sample Company a@sample.com /home")
+		event.CreatedAt = now.AddDate(0, 0, -age)
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestRunMeasuresFromTheAnalyticsClock(t *testing.T) {
+	// The bundled data is dated 2025, and so is the clock date ranges use
+	now := services.NewAnalyticsService(nil, nil, nil).Now()
+	if now.Year() != 2025 {
+		t.Fatalf("analytics clock is in %d, want 2025", now.Year())
+	}
+	service := services.NewAnalyticsService(datedEvents(now, 1, 30, 100), nil, nil)
+	manager, err := NewManager(service, filepath.Join(t.TempDir(), "retention.json"), models.RetentionPolicy{RawEvents: "90d", MaxPurgePercent: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if preview := manager.DryRun(); preview == nil || preview.EventsRemoved != 1 {
+		t.Fatalf("dry run = %+v, want 1 event", preview)
+	}
+	assertEventIDs(t, service.Events(), "1", "2", "3")
+
+	record, err := manager.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.EventsRemoved != 1 {
+		t.Fatalf("purge = %+v, want 1 event", record)
+	}
+	assertEventIDs(t, service.Events(), "1", "2")
+	if status := manager.Status(); status.RawEventsCutoff == nil || status.RawEventsCutoff.Year() != 2025 {
+		t.Errorf("status cutoff = %v, want one in 2025", status.RawEventsCutoff)
+	}
+}
+
+func TestApplyNeedsConfirmationForLargePurges(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	service := services.NewAnalyticsService(datedEvents(now, 1, 2, 200, 300), nil, nil)
+	path := filepath.Join(t.TempDir(), "retention.json")
+	manager, err := NewManager(service, path, models.RetentionPolicy{RawEvents: "90d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Half the events are past the cutoff, more than the default limit
+	record, err := manager.Apply(now, false)
+	var tooLarge *PurgeTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Apply error = %v, want PurgeTooLargeError", err)
+	}
+	if record != nil || tooLarge.Events != 2 || tooLarge.Total != 4 || tooLarge.MaxPercent != DefaultMaxPurgePercent {
+		t.Errorf("got record %+v and %+v, want no record and 2 of 4 events over %d%%", record, tooLarge, DefaultMaxPurgePercent)
+	}
+	assertEventIDs(t, service.Events(), "1", "2", "3", "4")
+	if preview := manager.Preview(now); preview == nil || preview.EventsRemoved != 2 {
+		t.Errorf("preview = %+v, want 2 events", preview)
+	}
+
+	record, err = manager.Apply(now, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.EventsRemoved != 2 {
+		t.Fatalf("confirmed purge = %+v, want 2 events", record)
+	}
+	assertEventIDs(t, service.Events(), "1", "2")
+
+	// Later runs that remove little need no confirmation
+	if _, err := manager.Apply(now.AddDate(0, 0, 1), false); err != nil {
+		t.Errorf("small purge: %v", err)
+	}
+}
+
+func TestNewManagerRejectsInvalidPurgeLimits(t *testing.T) {
+	service := services.NewAnalyticsService(nil, nil, nil)
+	for _, percent := range []int{-1, 101} {
+		if _, err := NewManager(service, filepath.Join(t.TempDir(), "retention.json"), models.RetentionPolicy{MaxPurgePercent: percent}); err == nil {
+			t.Errorf("MaxPurgePercent %d accepted", percent)
+		}
+	}
+}
+
+func assertEventIDs(t *testing.T, events []models.UsageEvent, want ...string) {
+	t.Helper()
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.ID != want[i] {
+			t.Errorf("event %d = %s, want %s", i, event.ID, want[i])
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package retention
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// periodPattern matches retention periods such as "90d", "12w", "13mo" or "2y"
+var periodPattern = regexp.MustCompile(`^(\d+)(d|w|mo|y)$`)
+
+// Period is a calendar retention period; the zero value keeps data forever
+type Period struct {
+	years  int
+	months int
+	days   int
+}
+
+// ParsePeriod parses a period like "13mo"; "" and "forever" keep data forever
+func ParsePeriod(value string) (Period, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "forever" {
+		return Period{}, nil
+	}
+
+	match := periodPattern.FindStringSubmatch(value)
+	if match == nil {
+		return Period{}, fmt.Errorf("invalid retention period %q, expected a number followed by d, w, mo or y", value)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n <= 0 {
+		return Period{}, fmt.Errorf("invalid retention period %q, must be positive", value)
+	}
+
+	switch match[2] {
+	case "d":
+		return Period{days: n}, nil
+	case "w":
+		return Period{days: 7 * n}, nil
+	case "mo":
+		return Period{months: n}, nil
+	default:
+		return Period{years: n}, nil
+	}
+}
+
+// Forever reports whether the period keeps data forever
+func (p Period) Forever() bool {
+	return p == Period{}
+}
+
+// Cutoff returns the start of the oldest day still retained at now
+// Data is purged in whole days so an archived day is never split
+func (p Period) Cutoff(now time.Time) time.Time {
+	return startOfDay(now.AddDate(-p.years, -p.months, -p.days))
+}
+
+// startOfDay truncates t to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,146 @@
+package rollups
+
+import (
+	"maps"
+	"slices"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// DayLayout formats rollup days
+const DayLayout = "2006-01-02"
+
+// Key identifies one rollup cell: a day, company, user and route
+// Email and Route are empty for events whose content does not name them
+type Key struct {
+	Day       string
+	CompanyID string
+	Email     string
+	Route     string
+}
+
+// Cell holds the totals of one rollup cell
+type Cell struct {
+	Events       int
+	LastActivity time.Time
+}
+
+// Filter selects rollup cells; the zero value selects every cell
+type Filter struct {
+	// Company reports whether a company is included; nil includes every company
+	Company func(companyID string) bool
+	// From and To are inclusive day bounds in DayLayout; empty leaves the range open
+	From string
+	To   string
+}
+
+// Table maintains per-day × company × user × route rollups of usage events
+// It is not safe for concurrent use; the owner guards it with its own lock
+type Table struct {
+	days      map[string]map[Key]*Cell
+	companies map[string]bool
+	names     map[string]string
+}
+
+// NewTable creates an empty rollup table
+func NewTable() *Table {
+	return &Table{
+		days:      make(map[string]map[Key]*Cell),
+		companies: make(map[string]bool),
+		names:     make(map[string]string),
+	}
+}
+
+// Build creates a rollup table from events
+func Build(events []models.UsageEvent) *Table {
+	table := NewTable()
+	for _, event := range events {
+		table.Add(event)
+	}
+	return table
+}
+
+// Add folds one event into its cell
+func (t *Table) Add(event models.UsageEvent) {
+	content := utils.ParseEventContent(event.Content)
+	key := Key{
+		Day:       event.CreatedAt.UTC().Format(DayLayout),
+		CompanyID: event.CompanyID,
+		Email:     content.Email,
+		Route:     content.Path,
+	}
+
+	cells, ok := t.days[key.Day]
+	if !ok {
+		cells = make(map[Key]*Cell)
+		t.days[key.Day] = cells
+	}
+	cell, ok := cells[key]
+	if !ok {
+		cell = &Cell{}
+		cells[key] = cell
+	}
+	cell.Events++
+	if event.CreatedAt.After(cell.LastActivity) {
+		cell.LastActivity = event.CreatedAt
+	}
+
+	t.companies[event.CompanyID] = true
+
+	// The first name seen for a company is kept so it stays stable as events arrive
+	if _, known := t.names[event.CompanyID]; !known && content.CompanyName != "" {
+		t.names[event.CompanyID] = content.CompanyName
+	}
+}
+
+// Each calls fn for every cell selected by filter
+func (t *Table) Each(filter Filter, fn func(key Key, cell Cell)) {
+	for day, cells := range t.days {
+		if filter.From != "" && day < filter.From {
+			continue
+		}
+		if filter.To != "" && day > filter.To {
+			continue
+		}
+		for key, cell := range cells {
+			if filter.Company != nil && !filter.Company(key.CompanyID) {
+				continue
+			}
+			fn(key, *cell)
+		}
+	}
+}
+
+// CompanyName returns the name of a company, falling back to its ID
+func (t *Table) CompanyName(companyID string) string {
+	if name, ok := t.names[companyID]; ok {
+		return name
+	}
+	return companyID
+}
+
+// Companies returns the IDs of every company with events, sorted
+func (t *Table) Companies() []string {
+	return slices.Sorted(maps.Keys(t.companies))
+}
+
+// Latest returns the creation time of the newest event added, zero when there is none
+func (t *Table) Latest() time.Time {
+	var latestDay string
+	for day := range t.days {
+		latestDay = max(latestDay, day)
+	}
+	var latest time.Time
+	for _, cell := range t.days[latestDay] {
+		if cell.LastActivity.After(latest) {
+			latest = cell.LastActivity
+		}
+	}
+	return latest
+}
+
+// HasCompany reports whether any event of a company has been added
+func (t *Table) HasCompany(companyID string) bool {
+	return t.companies[companyID]
+}
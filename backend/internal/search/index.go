@@ -0,0 +1,321 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Field names supported by the index
+const (
+	FieldContent = "content"
+	FieldUser    = "user"
+	FieldCompany = "company"
+	FieldPath    = "path"
+	// FieldText is content without its emails; it cannot be named in queries and
+	// replaces content in SearchRedacted
+	FieldText = "text"
+)
+
+// defaultFields are searched when a term has no field qualifier
+var defaultFields = []string{FieldContent, FieldCompany}
+
+// fieldWeights boosts matches in more specific fields
+var fieldWeights = map[string]float64{
+	FieldContent: 1.0,
+	FieldUser:    2.0,
+	FieldCompany: 1.5,
+	FieldPath:    1.0,
+}
+
+// Document is the searchable representation of a single event
+type Document struct {
+	ID     int
+	Fields map[string]string
+}
+
+// Hit represents a matching document and its relevance score
+type Hit struct {
+	DocID int
+	Score float64
+}
+
+// posting records the positions of a term within one document
+type posting struct {
+	doc       int
+	positions []int
+}
+
+// Index is an inverted index over event documents
+type Index struct {
+	mu sync.RWMutex
+
+	// postings maps field -> term -> postings (ordered by doc ID)
+	postings map[string]map[string][]posting
+	// terms holds a sorted vocabulary per field for prefix lookups
+	terms map[string][]string
+	dirty bool
+
+	// fieldLengths maps field -> doc ID -> token count
+	fieldLengths map[string]map[int]int
+	docs         map[int]bool
+}
+
+// NewIndex creates an empty inverted index
+func NewIndex() *Index {
+	return &Index{
+		postings:     make(map[string]map[string][]posting),
+		terms:        make(map[string][]string),
+		fieldLengths: make(map[string]map[int]int),
+		docs:         make(map[int]bool),
+	}
+}
+
+// Add tokenizes a document and adds it to the index
+// Documents must be added in increasing ID order
+func (idx *Index) Add(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs[doc.ID] = true
+
+	for field, text := range doc.Fields {
+		tokens := Tokenize(text)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		if idx.postings[field] == nil {
+			idx.postings[field] = make(map[string][]posting)
+			idx.fieldLengths[field] = make(map[int]int)
+		}
+		idx.fieldLengths[field][doc.ID] = len(tokens)
+
+		// Group token positions by term
+		positions := make(map[string][]int)
+		for pos, token := range tokens {
+			positions[token] = append(positions[token], pos)
+		}
+
+		for term, termPositions := range positions {
+			idx.postings[field][term] = append(idx.postings[field][term], posting{
+				doc:       doc.ID,
+				positions: termPositions,
+			})
+		}
+	}
+
+	idx.dirty = true
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search parses and evaluates a query, returning hits ordered by relevance
+func (idx *Index) Search(query string) ([]Hit, error) {
+	node, err := parseQuery(query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return idx.run(node), nil
+}
+
+// SearchRedacted evaluates a query for callers who may not see emails
+// Terms match content without its emails, and users are found only by terms resolveUser
+// accepts, such as pseudonyms, which search for the email it returns
+func (idx *Index) SearchRedacted(query string, resolveUser func(term string) (string, bool)) ([]Hit, error) {
+	node, err := parseQuery(query, resolveUser)
+	if err != nil {
+		return nil, err
+	}
+	return idx.run(node), nil
+}
+
+// run evaluates a parsed query, returning hits ordered by relevance
+func (idx *Index) run(node node) []Hit {
+	idx.refreshTerms()
+
+	idx.mu.RLock()
+	scores := node.eval(idx)
+	idx.mu.RUnlock()
+
+	hits := make([]Hit, 0, len(scores))
+	for docID, score := range scores {
+		hits = append(hits, Hit{DocID: docID, Score: score})
+	}
+
+	// Sort by score descending, then by doc ID for stable ordering
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].DocID < hits[j].DocID
+	})
+
+	return hits
+}
+
+// refreshTerms rebuilds the sorted vocabularies after new documents were added
+func (idx *Index) refreshTerms() {
+	idx.mu.RLock()
+	dirty := idx.dirty
+	idx.mu.RUnlock()
+	if !dirty {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for field, termMap := range idx.postings {
+		terms := make([]string, 0, len(termMap))
+		for term := range termMap {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		idx.terms[field] = terms
+	}
+	idx.dirty = false
+}
+
+// termScores scores every document containing term in field
+// Caller must hold the read lock
+func (idx *Index) termScores(field, term string) map[int]float64 {
+	return idx.scorePostings(field, idx.postings[field][term], nil)
+}
+
+// prefixScores scores every document containing a term starting with prefix
+// Caller must hold the read lock
+func (idx *Index) prefixScores(field, prefix string) map[int]float64 {
+	scores := make(map[int]float64)
+
+	terms := idx.terms[field]
+	start := sort.SearchStrings(terms, prefix)
+	for i := start; i < len(terms) && strings.HasPrefix(terms[i], prefix); i++ {
+		for docID, score := range idx.termScores(field, terms[i]) {
+			scores[docID] += score
+		}
+	}
+
+	return scores
+}
+
+// phraseScores scores every document containing the terms consecutively
+// Caller must hold the read lock
+func (idx *Index) phraseScores(field string, terms []string) map[int]float64 {
+	if len(terms) == 0 {
+		return map[int]float64{}
+	}
+	if len(terms) == 1 {
+		return idx.termScores(field, terms[0])
+	}
+
+	// Index the positions of every phrase term by document
+	termPositions := make([]map[int][]int, len(terms))
+	for i, term := range terms {
+		termPositions[i] = make(map[int][]int)
+		for _, p := range idx.postings[field][term] {
+			termPositions[i][p.doc] = p.positions
+		}
+	}
+
+	// Candidates are documents containing the first term
+	var matches []posting
+	for _, p := range idx.postings[field][terms[0]] {
+		var phrasePositions []int
+		for _, start := range p.positions {
+			if phraseAt(termPositions, p.doc, start) {
+				phrasePositions = append(phrasePositions, start)
+			}
+		}
+		if len(phrasePositions) > 0 {
+			matches = append(matches, posting{doc: p.doc, positions: phrasePositions})
+		}
+	}
+
+	// Phrases are rarer than their individual terms, so weight by term count
+	return idx.scorePostings(field, matches, func(score float64) float64 {
+		return score * float64(len(terms))
+	})
+}
+
+// phraseAt reports whether every phrase term appears at consecutive positions from start
+func phraseAt(termPositions []map[int][]int, doc, start int) bool {
+	for offset := 1; offset < len(termPositions); offset++ {
+		positions, ok := termPositions[offset][doc]
+		if !ok || !containsInt(positions, start+offset) {
+			return false
+		}
+	}
+	return true
+}
+
+// scorePostings computes tf-idf scores for a postings list
+// Caller must hold the read lock
+func (idx *Index) scorePostings(field string, postings []posting, adjust func(float64) float64) map[int]float64 {
+	scores := make(map[int]float64, len(postings))
+	if len(postings) == 0 {
+		return scores
+	}
+
+	live := 0
+	for _, p := range postings {
+		if idx.docs[p.doc] {
+			live++
+		}
+	}
+	if live == 0 {
+		return scores
+	}
+
+	idf := math.Log(1 + float64(len(idx.docs))/float64(live))
+	weight := fieldWeights[field]
+	if weight == 0 {
+		weight = 1.0
+	}
+
+	for _, p := range postings {
+		if !idx.docs[p.doc] {
+			continue
+		}
+		// Normalize term frequency by field length so short fields rank higher
+		length := idx.fieldLengths[field][p.doc]
+		tf := float64(len(p.positions)) / math.Sqrt(float64(max(length, 1)))
+		score := tf * idf * weight
+		if adjust != nil {
+			score = adjust(score)
+		}
+		scores[p.doc] = score
+	}
+
+	return scores
+}
+
+// allDocs returns every indexed document with a zero score
+// Caller must hold the read lock
+func (idx *Index) allDocs() map[int]float64 {
+	scores := make(map[int]float64, len(idx.docs))
+	for docID := range idx.docs {
+		scores[docID] = 0
+	}
+	return scores
+}
+
+// Tokenize lowercases text and splits it into alphanumeric tokens
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsInt reports whether a sorted slice contains value
+func containsInt(values []int, value int) bool {
+	i := sort.SearchInts(values, value)
+	return i < len(values) && values[i] == value
+}
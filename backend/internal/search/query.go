@@ -0,0 +1,420 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Query syntax:
+//
+//	export                   term in content or company
+//	expo*                    prefix search
+//	"work orders"            phrase query
+//	user:alice company:acme  field-qualified terms (user, company, path, content)
+//	a AND b, a OR b, NOT a   boolean operators (AND is implicit, "-a" negates)
+//	(a OR b) AND c           grouping
+
+// node is an evaluable query expression
+type node interface {
+	eval(idx *Index) map[int]float64
+}
+
+// termNode matches a single term, prefix or phrase
+type termNode struct {
+	fields []string
+	terms  []string
+	prefix bool
+}
+
+// andNode matches documents matching every child, excluding negated children
+type andNode struct {
+	include []node
+	exclude []node
+}
+
+// orNode matches documents matching any child
+type orNode struct {
+	children []node
+}
+
+// notNode matches every document not matching child
+type notNode struct {
+	child node
+}
+
+func (n *termNode) eval(idx *Index) map[int]float64 {
+	scores := make(map[int]float64)
+	for _, field := range n.fields {
+		var fieldScores map[int]float64
+		switch {
+		case n.prefix && len(n.terms) == 1:
+			fieldScores = idx.prefixScores(field, n.terms[0])
+		case n.prefix:
+			// Prefix applies to the last term of a phrase
+			fieldScores = idx.phrasePrefixScores(field, n.terms)
+		default:
+			fieldScores = idx.phraseScores(field, n.terms)
+		}
+		for docID, score := range fieldScores {
+			scores[docID] += score
+		}
+	}
+	return scores
+}
+
+func (n *andNode) eval(idx *Index) map[int]float64 {
+	var scores map[int]float64
+	if len(n.include) == 0 {
+		// Purely negative query - start from every document
+		scores = idx.allDocs()
+	}
+
+	for _, child := range n.include {
+		childScores := child.eval(idx)
+		if scores == nil {
+			scores = childScores
+			continue
+		}
+		for docID, score := range scores {
+			if childScore, ok := childScores[docID]; ok {
+				scores[docID] = score + childScore
+			} else {
+				delete(scores, docID)
+			}
+		}
+	}
+
+	for _, child := range n.exclude {
+		for docID := range child.eval(idx) {
+			delete(scores, docID)
+		}
+	}
+
+	return scores
+}
+
+func (n *orNode) eval(idx *Index) map[int]float64 {
+	scores := make(map[int]float64)
+	for _, child := range n.children {
+		for docID, score := range child.eval(idx) {
+			scores[docID] += score
+		}
+	}
+	return scores
+}
+
+func (n *notNode) eval(idx *Index) map[int]float64 {
+	scores := idx.allDocs()
+	for docID := range n.child.eval(idx) {
+		delete(scores, docID)
+	}
+	return scores
+}
+
+// phrasePrefixScores matches a phrase whose last term is a prefix
+// Caller must hold the read lock
+func (idx *Index) phrasePrefixScores(field string, terms []string) map[int]float64 {
+	scores := make(map[int]float64)
+
+	last := len(terms) - 1
+	phrase := append([]string{}, terms...)
+	vocabulary := idx.terms[field]
+	start := sort.SearchStrings(vocabulary, terms[last])
+	for i := start; i < len(vocabulary) && strings.HasPrefix(vocabulary[i], terms[last]); i++ {
+		phrase[last] = vocabulary[i]
+		for docID, score := range idx.phraseScores(field, phrase) {
+			scores[docID] += score
+		}
+	}
+
+	return scores
+}
+
+// token types produced by the query lexer
+const (
+	tokenWord = iota
+	tokenPhrase
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+// queryToken is a lexical token of the query language
+type queryToken struct {
+	kind  int
+	field string
+	text  string
+}
+
+// parseQuery parses a search query into an evaluable expression
+// A non-nil resolveUser restricts the query as SearchRedacted describes
+func parseQuery(query string, resolveUser func(string) (string, bool)) (node, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	p := &queryParser{tokens: tokens, resolveUser: resolveUser}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return n, nil
+}
+
+// lexQuery splits a query string into tokens
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen, text: ")"})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, queryToken{kind: tokenNot, text: "-"})
+			i++
+		case r == '"':
+			end := indexRune(runes, i+1, '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated phrase starting at position %d", i+1)
+			}
+			tokens = append(tokens, queryToken{kind: tokenPhrase, text: string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			word := string(runes[start:i])
+
+			// Field qualifier followed by a phrase, e.g. company:"sample company"
+			if strings.HasSuffix(word, ":") && i < len(runes) && runes[i] == '"' {
+				field := strings.ToLower(strings.TrimSuffix(word, ":"))
+				if !isField(field) {
+					return nil, fmt.Errorf("unknown field %q", field)
+				}
+				end := indexRune(runes, i+1, '"')
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated phrase starting at position %d", i+1)
+				}
+				tokens = append(tokens, queryToken{kind: tokenPhrase, field: field, text: string(runes[i+1 : end])})
+				i = end + 1
+				continue
+			}
+
+			tokens = append(tokens, wordToken(word))
+		}
+	}
+
+	return tokens, nil
+}
+
+// wordToken classifies a bare word as an operator or a (possibly qualified) term
+func wordToken(word string) queryToken {
+	switch word {
+	case "AND", "&&":
+		return queryToken{kind: tokenAnd, text: word}
+	case "OR", "||":
+		return queryToken{kind: tokenOr, text: word}
+	case "NOT":
+		return queryToken{kind: tokenNot, text: word}
+	}
+
+	// Only known field names qualify a term, so "10:30" stays a plain term
+	if field, value, found := strings.Cut(word, ":"); found && value != "" && isField(strings.ToLower(field)) {
+		return queryToken{kind: tokenWord, field: strings.ToLower(field), text: value}
+	}
+	return queryToken{kind: tokenWord, text: word}
+}
+
+// queryParser is a recursive descent parser over query tokens
+type queryParser struct {
+	tokens      []queryToken
+	pos         int
+	resolveUser func(string) (string, bool)
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// parseOr parses: and (OR and)*
+func (p *queryParser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []node{first}
+	for token := p.peek(); token != nil && token.kind == tokenOr; token = p.peek() {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &orNode{children: children}, nil
+}
+
+// parseAnd parses: unary ((AND)? unary)*
+func (p *queryParser) parseAnd() (node, error) {
+	and := &andNode{}
+
+	for {
+		token := p.peek()
+		if token == nil || token.kind == tokenOr || token.kind == tokenRParen {
+			break
+		}
+		if token.kind == tokenAnd {
+			p.pos++
+			continue
+		}
+
+		negate := false
+		for token := p.peek(); token != nil && token.kind == tokenNot; token = p.peek() {
+			negate = !negate
+			p.pos++
+		}
+
+		child, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if negate {
+			and.exclude = append(and.exclude, child)
+		} else {
+			and.include = append(and.include, child)
+		}
+	}
+
+	if len(and.include) == 0 && len(and.exclude) == 0 {
+		if token := p.peek(); token != nil {
+			return nil, fmt.Errorf("unexpected %q", token.text)
+		}
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	if len(and.include) == 1 && len(and.exclude) == 0 {
+		return and.include[0], nil
+	}
+	if len(and.include) == 0 && len(and.exclude) == 1 {
+		return &notNode{child: and.exclude[0]}, nil
+	}
+	return and, nil
+}
+
+// parsePrimary parses: ( or ) | term | phrase
+func (p *queryParser) parsePrimary() (node, error) {
+	token := p.peek()
+	if token == nil {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+
+	switch token.kind {
+	case tokenLParen:
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != tokenRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	case tokenWord, tokenPhrase:
+		p.pos++
+		return p.termNode(*token)
+	default:
+		return nil, fmt.Errorf("unexpected %q", token.text)
+	}
+}
+
+// termNode builds a term node from a word or phrase token
+func (p *queryParser) termNode(token queryToken) (node, error) {
+	text := token.text
+	prefix := false
+	if token.kind == tokenWord && strings.HasSuffix(text, "*") {
+		prefix = true
+		text = strings.TrimRight(text, "*")
+	}
+
+	terms := Tokenize(text)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("term %q has no searchable characters", token.text)
+	}
+
+	fields := defaultFields
+	if token.field != "" {
+		fields = []string{token.field}
+	}
+	if p.resolveUser != nil {
+		return p.redactedTermNode(token, fields, terms, prefix), nil
+	}
+
+	return &termNode{fields: fields, terms: terms, prefix: prefix}, nil
+}
+
+// redactedTermNode restricts a term for callers who may not see emails
+// A term resolveUser accepts searches for its user; other user terms match nothing,
+// and content is replaced by the text around its emails
+func (p *queryParser) redactedTermNode(token queryToken, fields, terms []string, prefix bool) node {
+	if token.field == "" || token.field == FieldUser {
+		if email, ok := p.resolveUser(strings.TrimSpace(token.text)); ok && !prefix {
+			return &termNode{fields: []string{FieldUser}, terms: Tokenize(email)}
+		}
+		if token.field == FieldUser {
+			return &termNode{}
+		}
+	}
+
+	redacted := make([]string, len(fields))
+	for i, field := range fields {
+		if field == FieldContent {
+			field = FieldText
+		}
+		redacted[i] = field
+	}
+	return &termNode{fields: redacted, terms: terms, prefix: prefix}
+}
+
+// isField reports whether name is a searchable field
+func isField(name string) bool {
+	_, ok := fieldWeights[name]
+	return ok
+}
+
+// indexRune returns the index of target in runes at or after start, or -1
+func indexRune(runes []rune, start int, target rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPhrasePrefix(t *testing.T) {
+	idx := NewIndex()
+	for id, content := range []string{
+		"open work orders",
+		"open work requests",
+		"close work orders",
+		"open workspace",
+		"open work",
+	} {
+		idx.Add(Document{ID: id, Fields: map[string]string{FieldContent: content}})
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		// A word that tokenizes into several terms is a phrase with a prefix last term
+		{"work-ord*", []int{0, 2}},
+		{"open/work*", []int{0, 1, 3, 4}},
+		{"open-work-o*", []int{0}},
+		{"open-work-z*", nil},
+		{"close-w*", []int{2}},
+	}
+	for _, tt := range tests {
+		hits, err := idx.Search(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		var got []int
+		for _, hit := range hits {
+			got = append(got, hit.DocID)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got documents %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
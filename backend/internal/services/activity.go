@@ -0,0 +1,80 @@
+package services
+
+import (
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/rollups"
+)
+
+// Activity returns event and unique user counts for a company (all companies when empty)
+// between two dates inclusive
+func (s *AnalyticsService) Activity(companyID string, fromDate, toDate time.Time) models.ActivitySummary {
+	filter := rollups.Filter{
+		From: startOfDay(fromDate).Format(rollups.DayLayout),
+		To:   startOfDay(toDate).Format(rollups.DayLayout),
+	}
+	if companyID != "" {
+		filter.Company = func(id string) bool { return id == companyID }
+	}
+
+	summary := models.ActivitySummary{}
+	users := make(map[string]bool)
+
+	s.mu.RLock()
+	s.rollups.Each(filter, func(key rollups.Key, cell rollups.Cell) {
+		summary.Events += cell.Events
+		if key.Email != "" {
+			users[key.Email] = true
+		}
+	})
+	s.mu.RUnlock()
+
+	summary.ActiveUsers = len(users)
+	return summary
+}
+
+// DailyActivity returns event and unique user counts per day and company
+func (s *AnalyticsService) DailyActivity() []models.DailyAggregate {
+	daily := make(map[[2]string]*models.DailyAggregate)
+	users := make(map[[2]string]map[string]bool)
+
+	s.mu.RLock()
+	s.rollups.Each(rollups.Filter{}, func(key rollups.Key, cell rollups.Cell) {
+		id := [2]string{key.Day, key.CompanyID}
+		aggregate, ok := daily[id]
+		if !ok {
+			aggregate = &models.DailyAggregate{Date: key.Day, CompanyID: key.CompanyID, CompanyName: s.companyName(s.rollups, key.CompanyID)}
+			daily[id] = aggregate
+			users[id] = make(map[string]bool)
+		}
+		aggregate.Events += cell.Events
+		if key.Email != "" && !users[id][key.Email] {
+			users[id][key.Email] = true
+			aggregate.ActiveUsers++
+		}
+	})
+	s.mu.RUnlock()
+
+	aggregates := make([]models.DailyAggregate, 0, len(daily))
+	for _, aggregate := range daily {
+		aggregates = append(aggregates, *aggregate)
+	}
+	return aggregates
+}
+
+// LatestEventTime returns the creation time of the most recent event
+func (s *AnalyticsService) LatestEventTime() time.Time {
+	var latest time.Time
+	for _, event := range s.Events() {
+		if event.CreatedAt.After(latest) {
+			latest = event.CreatedAt
+		}
+	}
+	return latest
+}
+
+// startOfDay truncates t to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
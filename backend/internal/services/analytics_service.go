@@ -1,124 +1,293 @@
 package services
 
 import (
+	"maps"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/rollups"
+	"usage-analytics-dashboard/internal/search"
 )
 
 // AnalyticsService handles analytics business logic
 type AnalyticsService struct {
-	events []models.UsageEvent
+	mu          sync.RWMutex
+	events      []models.UsageEvent
+	eventIDs    map[string]bool
+	index       *search.Index
+	rollups     *rollups.Table
+	redactor    *privacy.Redactor
+	directory   CompanyDirectory
+	subscribers []IngestSubscriber
+	ingestStats models.IngestStats
+
+	purgeSubscribers []PurgeSubscriber
 }
 
 // NewAnalyticsService creates a new analytics service
-func NewAnalyticsService(events []models.UsageEvent) *AnalyticsService {
-	return &AnalyticsService{events: events}
+// The redactor pseudonymizes emails for callers with a privacy mode and the directory
+// supplies registered company profiles; either may be nil
+func NewAnalyticsService(events []models.UsageEvent, redactor *privacy.Redactor, directory CompanyDirectory) *AnalyticsService {
+	service := &AnalyticsService{
+		events:    events,
+		eventIDs:  make(map[string]bool, len(events)),
+		index:     search.NewIndex(),
+		rollups:   rollups.NewTable(),
+		redactor:  redactor,
+		directory: directory,
+	}
+
+	// Build the full-text index and rollups at ingest so queries avoid full scans
+	for i, event := range events {
+		service.eventIDs[event.ID] = true
+		service.index.Add(searchDocument(i, event))
+		service.rollups.Add(event)
+		service.registerEmails(event)
+	}
+
+	return service
 }
 
 // GenerateAnalytics generates comprehensive analytics based on query parameters
+// Trends are keyed by camelCase company name, the shape served before API versioning
 func (s *AnalyticsService) GenerateAnalytics(params models.QueryParams) models.AnalyticsResponse {
-	// First, filter events based on all criteria (search, date range, company)
-	filteredEvents := s.filterEvents(params)
-
-	// Generate all analytics using the filtered data
-	summary := s.getSummary(filteredEvents)
-	trends := s.getTrends(filteredEvents, params)
-	companies := s.getCompanyMetrics(filteredEvents)
-	topUsers := s.getTopUsers(filteredEvents)
-
-	return models.AnalyticsResponse{
-		Summary:   summary,
-		Trends:    trends,
-		Companies: companies,
-		TopUsers:  topUsers,
+	var response models.AnalyticsResponse
+	s.withTable(params, func(table *rollups.Table, filter rollups.Filter) {
+		response = models.AnalyticsResponse{
+			Summary:   s.getSummary(table, filter),
+			Trends:    s.getTrends(table, filter),
+			Companies: s.getCompanyMetrics(table, filter),
+			TopUsers:  s.redactUsers(s.getTopUsers(table, filter), params.Privacy),
+		}
+		if params.GroupBy != "" {
+			response.Groups = s.getGroups(table, filter, params.GroupBy)
+		}
+	})
+	return response
+}
+
+// GenerateAnalyticsV1 generates analytics with trends keyed by company ID
+func (s *AnalyticsService) GenerateAnalyticsV1(params models.QueryParams) models.AnalyticsResponseV1 {
+	var response models.AnalyticsResponseV1
+	s.withTable(params, func(table *rollups.Table, filter rollups.Filter) {
+		response = models.AnalyticsResponseV1{
+			Version:   "v1",
+			Summary:   s.getSummary(table, filter),
+			Trends:    s.getTrendSeries(table, filter),
+			Companies: s.getCompanyMetrics(table, filter),
+			TopUsers:  s.redactUsers(s.getTopUsers(table, filter), params.Privacy),
+		}
+		if params.GroupBy != "" {
+			response.Groups = s.getGroups(table, filter, params.GroupBy)
+		}
+	})
+	return response
+}
+
+// GenerateSnapshot generates the summary and trends pushed to live dashboards
+func (s *AnalyticsService) GenerateSnapshot(params models.QueryParams) models.AnalyticsSnapshot {
+	var snapshot models.AnalyticsSnapshot
+	s.withTable(params, func(table *rollups.Table, filter rollups.Filter) {
+		snapshot = models.AnalyticsSnapshot{
+			Summary: s.getSummary(table, filter),
+			Trends:  s.getTrendSeries(table, filter),
+		}
+	})
+	return snapshot
+}
+
+// withTable calls fn with the rollup table and filter that answer params
+// Free-text search rolls up the matching raw events, since rollups do not keep event content
+func (s *AnalyticsService) withTable(params models.QueryParams, fn func(table *rollups.Table, filter rollups.Filter)) {
+	filter := s.rollupFilter(params)
+
+	if params.Search != "" {
+		fn(rollups.Build(s.matchingEvents(params)), filter)
+		return
 	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.rollups, filter)
 }
 
-// getSummary generates dashboard summary metrics
-func (s *AnalyticsService) getSummary(events []models.UsageEvent) models.DashboardSummary {
-	if len(events) == 0 {
-		return models.DashboardSummary{}
+// rollupFilter translates the scope, company, profile and date filters into a rollup filter
+func (s *AnalyticsService) rollupFilter(params models.QueryParams) rollups.Filter {
+	filter := rollups.Filter{
+		Company: func(companyID string) bool {
+			return s.companyAllowed(params, companyID)
+		},
 	}
+	if fromDate, toDate, ok := s.dateBounds(params); ok {
+		filter.From = fromDate.Format(rollups.DayLayout)
+		filter.To = toDate.Format(rollups.DayLayout)
+	}
+	return filter
+}
+
+// CompanyExists reports whether any loaded event belongs to companyID
+func (s *AnalyticsService) CompanyExists(companyID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rollups.HasCompany(companyID)
+}
+
+// CompanyVisible reports whether companyID exists and is in scope
+// Out-of-scope companies look the same as unknown ones, so callers cannot probe for them
+func (s *AnalyticsService) CompanyVisible(scope *models.CompanyScope, companyID string) bool {
+	return inScope(scope, companyID) && s.CompanyExists(companyID)
+}
 
-	companyCounts := make(map[string]int)
+// getSummary generates dashboard summary metrics
+func (s *AnalyticsService) getSummary(table *rollups.Table, filter rollups.Filter) models.DashboardSummary {
+	summary := models.DashboardSummary{}
+	companies := make(map[string]bool)
 	dailyEventCounts := make(map[string]int)
 
-	for _, event := range events {
-		companyCounts[event.CompanyID]++
+	table.Each(filter, func(key rollups.Key, cell rollups.Cell) {
+		summary.TotalEvents += cell.Events
+		companies[key.CompanyID] = true
 
 		// Count events per day for peak usage calculation
-		dateStr := event.CreatedAt.Format("2006-01-02")
-		dailyEventCounts[dateStr]++
-	}
+		dailyEventCounts[key.Day] += cell.Events
+	})
+	summary.TotalCompanies = len(companies)
 
-	// Find peak usage day
-	peakUsageDay := ""
+	// Find peak usage day, preferring the earliest day on ties
 	maxDailyEvents := 0
 	for date, count := range dailyEventCounts {
-		if count > maxDailyEvents {
+		if count > maxDailyEvents || (count == maxDailyEvents && date < summary.PeakUsageDay) {
 			maxDailyEvents = count
-			peakUsageDay = date
+			summary.PeakUsageDay = date
 		}
 	}
 
-	return models.DashboardSummary{
-		TotalEvents:    len(events),
-		TotalCompanies: len(companyCounts),
-		PeakUsageDay:   peakUsageDay,
+	return summary
+}
+
+// getTrends generates usage trends data, one zero-filled daily series per company
+// keyed by the camelCase company name
+// Companies whose names camelCase to the same key overwrite each other; the v1 format avoids this
+func (s *AnalyticsService) getTrends(table *rollups.Table, filter rollups.Filter) models.UsageTrends {
+	trends := make(map[string][]models.UsageTrend)
+
+	companyDays := countCompanyDays(table, filter)
+	if len(companyDays) == 0 {
+		return models.UsageTrends{Trends: trends}
+	}
+
+	days := trendDays(filter, companyDays)
+	for _, companyID := range slices.Sorted(maps.Keys(companyDays)) {
+		counts := companyDays[companyID]
+		companyTrends := make([]models.UsageTrend, 0, len(days))
+		for _, day := range days {
+			companyTrends = append(companyTrends, models.UsageTrend{Date: day, Events: counts[day]})
+		}
+
+		// Convert company name to camelCase for property name
+		trends[s.toCamelCase(s.companyName(table, companyID))] = companyTrends
 	}
+
+	return models.UsageTrends{Trends: trends}
 }
 
-// getTrends generates usage trends data
-func (s *AnalyticsService) getTrends(events []models.UsageEvent, params models.QueryParams) models.UsageTrends {
-	if len(events) == 0 {
-		return models.UsageTrends{Trends: make(map[string][]models.UsageTrend)}
+// getTrendSeries generates usage trends in the v1 format: one zero-filled daily series
+// per company keyed by ID, an explicit order and a totals series
+func (s *AnalyticsService) getTrendSeries(table *rollups.Table, filter rollups.Filter) models.TrendsV1 {
+	trends := models.TrendsV1{
+		Days:   []string{},
+		Order:  []string{},
+		Series: make(map[string]models.TrendSeries),
+		Totals: []models.UsageTrend{},
 	}
 
-	// Create trends map
-	trends := make(map[string][]models.UsageTrend)
+	companyDays := countCompanyDays(table, filter)
+	if len(companyDays) == 0 {
+		return trends
+	}
 
-	if params.CompanyID == "" {
-		// "All Companies" selected - generate trends for each company
-		companyTrends := s.groupByDayAndCompany(events, params)
+	days := trendDays(filter, companyDays)
+	trends.Days = days
+	totals := make([]models.UsageTrend, len(days))
+	for i, day := range days {
+		totals[i].Date = day
+	}
 
-		// Group by company name
-		companyMap := make(map[string][]models.UsageTrend)
-		for _, trend := range companyTrends {
-			companyName := trend.CompanyName
-			if companyMap[companyName] == nil {
-				companyMap[companyName] = []models.UsageTrend{}
-			}
-			companyMap[companyName] = append(companyMap[companyName], models.UsageTrend{
-				Date:   trend.Date,
-				Events: trend.Events,
-			})
+	for companyID, counts := range companyDays {
+		series := models.TrendSeries{
+			CompanyID: companyID,
+			Label:     s.companyName(table, companyID),
+			Points:    make([]models.UsageTrend, 0, len(days)),
 		}
+		for i, day := range days {
+			series.Points = append(series.Points, models.UsageTrend{Date: day, Events: counts[day]})
+			series.Total += counts[day]
+			totals[i].Events += counts[day]
+		}
+		trends.Series[companyID] = series
+		trends.Order = append(trends.Order, companyID)
+	}
+	trends.Totals = totals
 
-		// Sort each company's trends by date and format company names as camelCase
-		for companyName, companyTrends := range companyMap {
-			sort.Slice(companyTrends, func(i, j int) bool {
-				return companyTrends[i].Date < companyTrends[j].Date
-			})
-			// Convert company name to camelCase for property name
-			camelCaseName := s.toCamelCase(companyName)
-			trends[camelCaseName] = companyTrends
+	// Sort by total events descending
+	sort.Slice(trends.Order, func(i, j int) bool {
+		a, b := trends.Series[trends.Order[i]], trends.Series[trends.Order[j]]
+		if a.Total != b.Total {
+			return a.Total > b.Total
 		}
-	} else {
-		// Specific company selected - generate trends for that company only
-		dailyTrends := s.groupByDay(events, params)
-
-		// Get company name for the selected company
-		companyName := s.extractCompanyName(params.CompanyID, events)
-		if companyName != "" {
-			// Convert company name to camelCase for property name
-			camelCaseName := s.toCamelCase(companyName)
-			trends[camelCaseName] = dailyTrends
+		return a.CompanyID < b.CompanyID
+	})
+
+	return trends
+}
+
+// countCompanyDays counts events per company per day
+func countCompanyDays(table *rollups.Table, filter rollups.Filter) map[string]map[string]int {
+	companyDays := make(map[string]map[string]int)
+	table.Each(filter, func(key rollups.Key, cell rollups.Cell) {
+		if companyDays[key.CompanyID] == nil {
+			companyDays[key.CompanyID] = make(map[string]int)
+		}
+		companyDays[key.CompanyID][key.Day] += cell.Events
+	})
+	return companyDays
+}
+
+// trendDays lists every day in the filter's range, using the days with events
+// for whichever bound is open
+func trendDays(filter rollups.Filter, companyDays map[string]map[string]int) []string {
+	from, to := filter.From, filter.To
+	if from == "" || to == "" {
+		for _, counts := range companyDays {
+			for day := range counts {
+				if filter.From == "" && (from == "" || day < from) {
+					from = day
+				}
+				if filter.To == "" && day > to {
+					to = day
+				}
+			}
 		}
 	}
 
-	return models.UsageTrends{Trends: trends}
+	start, err := time.Parse(rollups.DayLayout, from)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse(rollups.DayLayout, to)
+	if err != nil {
+		return nil
+	}
+
+	var days []string
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(rollups.DayLayout))
+	}
+	return days
 }
 
 // toCamelCase converts a company name to camelCase format
@@ -148,44 +317,42 @@ func (s *AnalyticsService) toCamelCase(name string) string {
 }
 
 // getCompanyMetrics generates company analytics data
-func (s *AnalyticsService) getCompanyMetrics(events []models.UsageEvent) []models.Company {
-	if len(events) == 0 {
-		return []models.Company{}
-	}
-
+func (s *AnalyticsService) getCompanyMetrics(table *rollups.Table, filter rollups.Filter) []models.Company {
 	companyMap := make(map[string]*models.Company)
 	userMap := make(map[string]map[string]bool)
 
-	for _, event := range events {
-		companyID := event.CompanyID
-
-		if company, exists := companyMap[companyID]; exists {
-			company.EventCount++
-			if event.CreatedAt.After(company.LastActivity) {
-				company.LastActivity = event.CreatedAt
+	table.Each(filter, func(key rollups.Key, cell rollups.Cell) {
+		company, exists := companyMap[key.CompanyID]
+		if !exists {
+			company = &models.Company{
+				ID:   key.CompanyID,
+				Name: s.companyName(table, key.CompanyID),
 			}
-		} else {
-			companyMap[companyID] = &models.Company{
-				ID:           companyID,
-				Name:         s.extractCompanyName(companyID, events),
-				EventCount:   1,
-				ActiveUsers:  0,
-				LastActivity: event.CreatedAt,
+			if profile, ok := s.profile(key.CompanyID); ok {
+				company.Slug = profile.Slug
+				company.Plan = profile.Plan
+				company.Region = profile.Region
+				company.CSMOwner = profile.CSMOwner
+				company.ARR = profile.ARR
+				company.Tags = profile.Tags
 			}
+			companyMap[key.CompanyID] = company
+			userMap[key.CompanyID] = make(map[string]bool)
 		}
 
-		// Track unique users per company
-		if userMap[companyID] == nil {
-			userMap[companyID] = make(map[string]bool)
+		company.EventCount += cell.Events
+		if cell.LastActivity.After(company.LastActivity) {
+			company.LastActivity = cell.LastActivity
 		}
-		userEmail := s.extractUserEmail(event.Content)
-		if userEmail != "" {
-			userMap[companyID][userEmail] = true
+
+		// Track unique users per company
+		if key.Email != "" {
+			userMap[key.CompanyID][key.Email] = true
 		}
-	}
+	})
 
 	// Convert to slice and calculate active users
-	var companies []models.Company
+	companies := make([]models.Company, 0, len(companyMap))
 	for _, company := range companyMap {
 		company.ActiveUsers = len(userMap[company.ID])
 		companies = append(companies, *company)
@@ -193,51 +360,53 @@ func (s *AnalyticsService) getCompanyMetrics(events []models.UsageEvent) []model
 
 	// Sort by event count descending
 	sort.Slice(companies, func(i, j int) bool {
-		return companies[i].EventCount > companies[j].EventCount
+		if companies[i].EventCount != companies[j].EventCount {
+			return companies[i].EventCount > companies[j].EventCount
+		}
+		return companies[i].ID < companies[j].ID
 	})
 
 	return companies
 }
 
 // getTopUsers generates top user activity data
-func (s *AnalyticsService) getTopUsers(events []models.UsageEvent) []models.UserActivity {
-	if len(events) == 0 {
-		return []models.UserActivity{}
-	}
-
+func (s *AnalyticsService) getTopUsers(table *rollups.Table, filter rollups.Filter) []models.UserActivity {
 	userMap := make(map[string]*models.UserActivity)
+	lastSeen := make(map[string]time.Time)
 	companyMap := make(map[string]string)
 
-	for _, event := range events {
-		userEmail := s.extractUserEmail(event.Content)
-		if userEmail == "" {
-			continue
+	table.Each(filter, func(key rollups.Key, cell rollups.Cell) {
+		if key.Email == "" {
+			return
 		}
 
-		if user, exists := userMap[userEmail]; exists {
-			user.EventCount++
-		} else {
-			userMap[userEmail] = &models.UserActivity{
-				Email:      userEmail,
-				EventCount: 1,
-			}
+		user, exists := userMap[key.Email]
+		if !exists {
+			user = &models.UserActivity{Email: key.Email}
+			userMap[key.Email] = user
 		}
+		user.EventCount += cell.Events
 
-		// Store company association
-		companyMap[userEmail] = event.CompanyID
-	}
+		// Associate the user with the company of their most recent activity
+		if cell.LastActivity.After(lastSeen[key.Email]) {
+			lastSeen[key.Email] = cell.LastActivity
+			companyMap[key.Email] = key.CompanyID
+		}
+	})
 
 	// Convert to slice and add company names
-	var users []models.UserActivity
+	users := make([]models.UserActivity, 0, len(userMap))
 	for _, user := range userMap {
-		companyID := companyMap[user.Email]
-		user.CompanyName = s.extractCompanyName(companyID, events)
+		user.CompanyName = s.companyName(table, companyMap[user.Email])
 		users = append(users, *user)
 	}
 
 	// Sort by event count descending
 	sort.Slice(users, func(i, j int) bool {
-		return users[i].EventCount > users[j].EventCount
+		if users[i].EventCount != users[j].EventCount {
+			return users[i].EventCount > users[j].EventCount
+		}
+		return users[i].Email < users[j].Email
 	})
 
 	// Return top 10 users
@@ -249,50 +418,95 @@ func (s *AnalyticsService) getTopUsers(events []models.UsageEvent) []models.User
 
 // filterEvents applies all filters to events
 func (s *AnalyticsService) filterEvents(params models.QueryParams) []models.UsageEvent {
-	filtered := s.events
+	return s.applyDateFilter(s.matchingEvents(params), params)
+}
 
-	// Filter by company ID
-	if params.CompanyID != "" {
-		var companyFiltered []models.UsageEvent
+// matchingEvents applies the scope, company and search filters to events
+func (s *AnalyticsService) matchingEvents(params models.QueryParams) []models.UsageEvent {
+	filtered := s.Events()
+
+	// Restrict to the companies the caller may see and asked for before any other filter,
+	// so every aggregate built from the result is scoped
+	if params.Scope != nil || params.CompanyID != "" || hasProfileFilter(params) {
+		var scoped []models.UsageEvent
 		for _, event := range filtered {
-			if event.CompanyID == params.CompanyID {
-				companyFiltered = append(companyFiltered, event)
+			if s.companyAllowed(params, event.CompanyID) {
+				scoped = append(scoped, event)
 			}
 		}
-		filtered = companyFiltered
+		filtered = scoped
 	}
 
-	// Filter by search term
+	// Filter by search term, accepting pseudonymous user IDs from redacted responses
+	// Callers who may not see emails find users only by pseudonym, and other terms skip emails
 	if params.Search != "" {
+		redacted := s.redacting(params.Privacy)
+		email, byUser := "", false
+		if redacted {
+			email, byUser = s.resolveUser(params.Search)
+		} else {
+			params.Search = s.resolvePseudonym(params.Search)
+		}
+		searchTerm := strings.ToLower(params.Search)
+
 		var searchFiltered []models.UsageEvent
 		for _, event := range filtered {
-			content := strings.ToLower(event.Content)
 			companyID := strings.ToLower(event.CompanyID)
-			userEmail := strings.ToLower(s.extractUserEmail(event.Content))
-			searchTerm := strings.ToLower(params.Search)
-
-			if strings.Contains(content, searchTerm) ||
-				strings.Contains(companyID, searchTerm) ||
-				strings.Contains(userEmail, searchTerm) {
+			var matched bool
+			switch {
+			case byUser:
+				matched = ContainsUser(event, email)
+			case redacted:
+				content := strings.ToLower(privacy.StripEmails(event.Content))
+				matched = strings.Contains(content, searchTerm) || strings.Contains(companyID, searchTerm)
+			default:
+				content := strings.ToLower(event.Content)
+				userEmail := strings.ToLower(s.extractUserEmail(event.Content))
+				matched = strings.Contains(content, searchTerm) ||
+					strings.Contains(companyID, searchTerm) ||
+					strings.Contains(userEmail, searchTerm)
+			}
+			if matched {
 				searchFiltered = append(searchFiltered, event)
 			}
 		}
 		filtered = searchFiltered
 	}
 
-	// Apply date filtering - this is the key fix
-	filtered = s.applyDateFilter(filtered, params)
-
 	return filtered
 }
 
+// inScope reports whether a company is visible under scope; a nil scope allows every company
+func inScope(scope *models.CompanyScope, companyID string) bool {
+	return scope == nil || slices.Contains(scope.CompanyIDs, companyID)
+}
+
 // applyDateFilter applies date range filtering to events
 func (s *AnalyticsService) applyDateFilter(events []models.UsageEvent, params models.QueryParams) []models.UsageEvent {
 	if len(events) == 0 {
 		return events
 	}
 
-	var fromDate, toDate time.Time
+	fromDate, toDate, ok := s.dateBounds(params)
+	if !ok {
+		// No date filtering
+		return events
+	}
+
+	// Filter events by date range
+	var dateFiltered []models.UsageEvent
+	for _, event := range events {
+		if inDateRange(event.CreatedAt, fromDate, toDate) {
+			dateFiltered = append(dateFiltered, event)
+		}
+	}
+
+	return dateFiltered
+}
+
+// dateBounds resolves the date range used for filtering events
+// Returns ok=false when no date filtering should be applied
+func (s *AnalyticsService) dateBounds(params models.QueryParams) (fromDate, toDate time.Time, ok bool) {
 	demoCurrentDate := s.getDemoCurrentDate()
 
 	// Determine date range
@@ -302,7 +516,7 @@ func (s *AnalyticsService) applyDateFilter(events []models.UsageEvent, params mo
 			fromDate = from
 		} else {
 			// If fromDate parsing fails, return all events
-			return events
+			return time.Time{}, time.Time{}, false
 		}
 
 		if params.ToDate != "" {
@@ -328,181 +542,30 @@ func (s *AnalyticsService) applyDateFilter(events []models.UsageEvent, params mo
 		fromDate = toDate.AddDate(0, 0, -params.DateRange+1)
 	} else {
 		// No date filtering
-		return events
+		return time.Time{}, time.Time{}, false
 	}
 
-	// Filter events by date range
-	var dateFiltered []models.UsageEvent
-	for _, event := range events {
-		if (event.CreatedAt.After(fromDate) || event.CreatedAt.Equal(fromDate)) &&
-			(event.CreatedAt.Before(toDate) || event.CreatedAt.Equal(toDate)) {
-			dateFiltered = append(dateFiltered, event)
-		}
-	}
-
-	return dateFiltered
+	return fromDate, toDate, true
 }
 
-// groupByDay groups events by day for trend analysis
-func (s *AnalyticsService) groupByDay(events []models.UsageEvent, params models.QueryParams) []models.UsageTrend {
-	if len(events) == 0 {
-		return []models.UsageTrend{}
-	}
-
-	// Get demo current date for demo purposes
-	demoCurrentDate := s.getDemoCurrentDate()
-
-	// Determine date range
-	var fromDate, toDate time.Time
-
-	if params.FromDate != "" && params.ToDate != "" {
-		// Use specific date range
-		if from, err := time.Parse("2006-01-02", params.FromDate); err == nil {
-			fromDate = from
-		}
-		if to, err := time.Parse("2006-01-02", params.ToDate); err == nil {
-			toDate = to
-		}
-	} else if params.FromDate != "" {
-		// Only fromDate provided - start from specified date and go up to today
-		if from, err := time.Parse("2006-01-02", params.FromDate); err == nil {
-			fromDate = from
-			toDate = demoCurrentDate
-		}
-	} else {
-		// Use dateRange parameter
-		toDate = demoCurrentDate
-		fromDate = toDate.AddDate(0, 0, -params.DateRange+1)
-	}
-
-	// Generate date range with zero-filling
-	dateMap := make(map[string]int)
-	currentDate := fromDate
-
-	for currentDate.Before(toDate) || currentDate.Equal(toDate) {
-		dateStr := currentDate.Format("2006-01-02")
-		dateMap[dateStr] = 0
-		currentDate = currentDate.AddDate(0, 0, 1)
-	}
-
-	// Count events per day
-	for _, event := range events {
-		if (event.CreatedAt.After(fromDate) || event.CreatedAt.Equal(fromDate)) &&
-			(event.CreatedAt.Before(toDate) || event.CreatedAt.Equal(toDate)) {
-			dateStr := event.CreatedAt.Format("2006-01-02")
-			dateMap[dateStr]++
-		}
-	}
-
-	// Convert to slice and sort
-	var trends []models.UsageTrend
-	for date, count := range dateMap {
-		trends = append(trends, models.UsageTrend{
-			Date:   date,
-			Events: count,
-		})
-	}
-
-	sort.Slice(trends, func(i, j int) bool {
-		return trends[i].Date < trends[j].Date
-	})
-
-	return trends
+// inDateRange reports whether t falls on a day within [fromDate, toDate]
+func inDateRange(t, fromDate, toDate time.Time) bool {
+	return !t.Before(fromDate) && t.Before(toDate.AddDate(0, 0, 1))
 }
 
-// groupByDayAndCompany groups events by day and company for multiline chart
-func (s *AnalyticsService) groupByDayAndCompany(events []models.UsageEvent, params models.QueryParams) []models.CompanyTrend {
-	if len(events) == 0 {
-		return []models.CompanyTrend{}
-	}
-
-	// Get demo current date for demo purposes
-	demoCurrentDate := s.getDemoCurrentDate()
-
-	// Determine date range (same logic as groupByDay)
-	var fromDate, toDate time.Time
-
-	if params.FromDate != "" && params.ToDate != "" {
-		if from, err := time.Parse("2006-01-02", params.FromDate); err == nil {
-			fromDate = from
-		}
-		if to, err := time.Parse("2006-01-02", params.ToDate); err == nil {
-			toDate = to
-		}
-	} else if params.FromDate != "" {
-		if from, err := time.Parse("2006-01-02", params.FromDate); err == nil {
-			fromDate = from
-			toDate = demoCurrentDate
-		}
-	} else {
-		toDate = demoCurrentDate
-		fromDate = toDate.AddDate(0, 0, -params.DateRange+1)
-	}
-
-	if fromDate.IsZero() || toDate.IsZero() {
-		return []models.CompanyTrend{}
-	}
-
-	// Generate date range with zero-filling for each company
-	companyDateMap := make(map[string]map[string]int)
-	companyNames := make(map[string]string)
-
-	// Initialize all companies with zero counts for all dates
-	for _, event := range events {
-		companyID := event.CompanyID
-		if companyDateMap[companyID] == nil {
-			companyDateMap[companyID] = make(map[string]int)
-			companyNames[companyID] = s.extractCompanyName(companyID, events)
-
-			// Initialize all dates with zero
-			currentDate := fromDate
-			for currentDate.Before(toDate) || currentDate.Equal(toDate) {
-				dateStr := currentDate.Format("2006-01-02")
-				companyDateMap[companyID][dateStr] = 0
-				currentDate = currentDate.AddDate(0, 0, 1)
-			}
-		}
-	}
-
-	// Count events per day per company
-	for _, event := range events {
-		if (event.CreatedAt.After(fromDate) || event.CreatedAt.Equal(fromDate)) &&
-			(event.CreatedAt.Before(toDate) || event.CreatedAt.Equal(toDate)) {
-			dateStr := event.CreatedAt.Format("2006-01-02")
-			companyDateMap[event.CompanyID][dateStr]++
-		}
-	}
-
-	// Convert to slice
-	var trends []models.CompanyTrend
-	for companyID, dateMap := range companyDateMap {
-		companyName := companyNames[companyID]
-		for date, count := range dateMap {
-			trends = append(trends, models.CompanyTrend{
-				Date:        date,
-				CompanyID:   companyID,
-				CompanyName: companyName,
-				Events:      count,
-			})
-		}
-	}
-
-	// Sort by date, then by company name for consistent ordering
-	sort.Slice(trends, func(i, j int) bool {
-		if trends[i].Date != trends[j].Date {
-			return trends[i].Date < trends[j].Date
-		}
-		return trends[i].CompanyName < trends[j].CompanyName
-	})
-
-	return trends
+// Now returns the current time with the year set to 2025 to align with the CSV data
+// Date ranges and retention both measure from it, so they agree on what today is
+func (s *AnalyticsService) Now() time.Time {
+	now := time.Now()
+	return time.Date(2025, now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), now.Location())
 }
 
-// getDemoCurrentDate returns current date but with year 2025 for demo purposes
+// getDemoCurrentDate returns today's date on the Now clock as midnight UTC
+// The configured timezone decides which date today is, but days are UTC days
+// everywhere else, as in rollup keys and parsed fromDate and toDate values
 func (s *AnalyticsService) getDemoCurrentDate() time.Time {
-	currentTime := time.Now()
-	// Use 2025 for demo purposes to align with CSV data
-	return time.Date(2025, currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, currentTime.Location())
+	now := s.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 // extractUserEmail extracts user email from content field
@@ -515,55 +578,3 @@ func (s *AnalyticsService) extractUserEmail(content string) string {
 	}
 	return ""
 }
-
-// extractCompanyName extracts company name from content field
-func (s *AnalyticsService) extractCompanyName(companyID string, events []models.UsageEvent) string {
-	// Look for company name in content field
-	for _, event := range events {
-		if event.CompanyID == companyID {
-			// Extract company name from content
-			// Format: "User active CMMS - Company Name user@email.com /path"
-			content := event.Content
-			if strings.Contains(content, " - ") {
-				parts := strings.Split(content, " - ")
-				if len(parts) >= 2 {
-					companyPart := parts[1]
-
-					// Find the first email address (contains @) to determine where company name ends
-					emailIndex := strings.Index(companyPart, "@")
-					if emailIndex > 0 {
-						// Company name is everything before the email
-						// Find the last space before the @ symbol to get just the company name
-						companyNamePart := companyPart[:emailIndex]
-						lastSpaceIndex := strings.LastIndex(companyNamePart, " ")
-						if lastSpaceIndex > 0 {
-							// Take everything before the last space (which is the company name)
-							companyName := companyNamePart[:lastSpaceIndex]
-							// Trim any trailing spaces
-							companyName = strings.TrimSpace(companyName)
-							return companyName
-						} else {
-							// No space found, return the whole part before @
-							companyName := strings.TrimSpace(companyNamePart)
-							return companyName
-						}
-					}
-
-					// Fallback: if no email found, take everything before the first space
-					if strings.Contains(companyPart, " ") {
-						spaceIndex := strings.Index(companyPart, " ")
-						if spaceIndex > 0 {
-							companyName := companyPart[:spaceIndex]
-							return companyName
-						}
-					}
-					return companyPart
-				}
-			}
-			break
-		}
-	}
-
-	// Fallback to company ID if no name found
-	return companyID
-}
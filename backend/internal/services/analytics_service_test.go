@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+func TestDateRangeUsesRollupDaysOutsideUTC(t *testing.T) {
+	local := time.Local
+	defer func() { time.Local = local }()
+
+	for _, zone := range []*time.Location{time.FixedZone("UTC+14", 14*3600), time.FixedZone("UTC-12", -12*3600)} {
+		time.Local = zone
+		service := NewAnalyticsService(nil, nil, nil)
+		today := service.getDemoCurrentDate()
+
+		var events []models.UsageEvent
+		for i, offset := range []time.Duration{-time.Hour, time.Hour, 12 * time.Hour, 23 * time.Hour, 25 * time.Hour} {
+			created := today.Add(offset)
+			events = append(events, models.UsageEvent{
+				ID:                string(rune('a' + i)),
+				CreatedAt:         created,
+				CompanyID:         "company-1",
+				Type:              "Action",
+				Content:           "Login - Sample Company ann@sample.com /home",
+				UpdatedAt:         created,
+				OriginalTimestamp: created,
+			})
+		}
+		service = NewAnalyticsService(events, nil, nil)
+
+		// Without a search the summary comes from rollups; with one it comes from the raw events
+		rolledUp := service.GenerateAnalytics(models.QueryParams{DateRange: 1}).Summary.TotalEvents
+		searched := service.GenerateAnalytics(models.QueryParams{DateRange: 1, Search: "login"}).Summary.TotalEvents
+		day := today.Format("2006-01-02")
+		explicit := service.GenerateAnalytics(models.QueryParams{FromDate: day, ToDate: day, Search: "login"}).Summary.TotalEvents
+		if rolledUp != 3 || searched != 3 || explicit != 3 {
+			t.Errorf("%s: today has %d events from rollups, %d from search and %d from fromDate and toDate, want 3",
+				zone, rolledUp, searched, explicit)
+		}
+	}
+}
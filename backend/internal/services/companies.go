@@ -0,0 +1,211 @@
+package services
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/rollups"
+)
+
+// Company profile dimensions accepted by groupBy
+const (
+	GroupByPlan     = "plan"
+	GroupByRegion   = "region"
+	GroupByCSMOwner = "csmOwner"
+	GroupByTag      = "tag"
+)
+
+// GroupDimensions lists every groupBy value
+var GroupDimensions = []string{GroupByPlan, GroupByRegion, GroupByCSMOwner, GroupByTag}
+
+// unassignedGroup collects companies without a value for the grouping dimension
+const unassignedGroup = "unassigned"
+
+// CompanyDirectory supplies registered company profiles
+type CompanyDirectory interface {
+	Profile(companyID string) (models.CompanyProfile, bool)
+	Profiles() []models.CompanyProfile
+}
+
+// CompanyName returns the canonical name of a company, falling back to the
+// name found in its events and then to its ID
+func (s *AnalyticsService) CompanyName(companyID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.companyName(s.rollups, companyID)
+}
+
+// CompanyProfiles returns the profiles of every registered or observed company
+// visible under scope, ordered by name
+// Companies that are not registered get a profile holding only their name
+func (s *AnalyticsService) CompanyProfiles(scope *models.CompanyScope) []models.CompanyProfile {
+	profiles := make(map[string]models.CompanyProfile)
+	if s.directory != nil {
+		for _, profile := range s.directory.Profiles() {
+			profiles[profile.ID] = profile
+		}
+	}
+
+	s.mu.RLock()
+	for _, companyID := range s.rollups.Companies() {
+		if _, registered := profiles[companyID]; !registered {
+			profiles[companyID] = models.CompanyProfile{
+				ID:   companyID,
+				Name: s.rollups.CompanyName(companyID),
+				Tags: []string{},
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	result := make([]models.CompanyProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		if inScope(scope, profile.ID) {
+			result = append(result, profile)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// CompanyProfile returns the profile of a registered or observed company visible under scope
+func (s *AnalyticsService) CompanyProfile(scope *models.CompanyScope, companyID string) (models.CompanyProfile, bool) {
+	if !inScope(scope, companyID) {
+		return models.CompanyProfile{}, false
+	}
+	if profile, ok := s.profile(companyID); ok {
+		return profile, true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.rollups.HasCompany(companyID) {
+		return models.CompanyProfile{}, false
+	}
+	return models.CompanyProfile{
+		ID:   companyID,
+		Name: s.rollups.CompanyName(companyID),
+		Tags: []string{},
+	}, true
+}
+
+// companyName resolves a company name from the directory, then the rollup table
+func (s *AnalyticsService) companyName(table *rollups.Table, companyID string) string {
+	if profile, ok := s.profile(companyID); ok && profile.Name != "" {
+		return profile.Name
+	}
+	return table.CompanyName(companyID)
+}
+
+// profile returns the registered profile of a company
+func (s *AnalyticsService) profile(companyID string) (models.CompanyProfile, bool) {
+	if s.directory == nil {
+		return models.CompanyProfile{}, false
+	}
+	return s.directory.Profile(companyID)
+}
+
+// companyAllowed reports whether a company passes the scope, company and profile filters
+func (s *AnalyticsService) companyAllowed(params models.QueryParams, companyID string) bool {
+	if !inScope(params.Scope, companyID) {
+		return false
+	}
+	if params.CompanyID != "" && companyID != params.CompanyID {
+		return false
+	}
+	if !hasProfileFilter(params) {
+		return true
+	}
+
+	// Profile filters only match registered companies
+	profile, ok := s.profile(companyID)
+	if !ok {
+		return false
+	}
+	return (params.Plan == "" || strings.EqualFold(profile.Plan, params.Plan)) &&
+		(params.Region == "" || strings.EqualFold(profile.Region, params.Region)) &&
+		(params.CSMOwner == "" || strings.EqualFold(profile.CSMOwner, params.CSMOwner)) &&
+		(params.Tag == "" || slices.Contains(profile.Tags, strings.ToLower(params.Tag)))
+}
+
+// hasProfileFilter reports whether params filter on any company profile field
+func hasProfileFilter(params models.QueryParams) bool {
+	return params.Plan != "" || params.Region != "" || params.CSMOwner != "" || params.Tag != ""
+}
+
+// getGroups totals events, companies and unique users per value of a profile dimension
+func (s *AnalyticsService) getGroups(table *rollups.Table, filter rollups.Filter, dimension string) []models.CompanyGroup {
+	groups := make(map[string]*models.CompanyGroup)
+	companies := make(map[string]map[string]bool)
+	users := make(map[string]map[string]bool)
+	keys := make(map[string][]string)
+
+	table.Each(filter, func(key rollups.Key, cell rollups.Cell) {
+		groupKeys, ok := keys[key.CompanyID]
+		if !ok {
+			groupKeys = s.groupKeys(key.CompanyID, dimension)
+			keys[key.CompanyID] = groupKeys
+		}
+
+		for _, groupKey := range groupKeys {
+			group, ok := groups[groupKey]
+			if !ok {
+				group = &models.CompanyGroup{Key: groupKey}
+				groups[groupKey] = group
+				companies[groupKey] = make(map[string]bool)
+				users[groupKey] = make(map[string]bool)
+			}
+			group.EventCount += cell.Events
+			companies[groupKey][key.CompanyID] = true
+			if key.Email != "" {
+				users[groupKey][key.Email] = true
+			}
+		}
+	})
+
+	result := make([]models.CompanyGroup, 0, len(groups))
+	for key, group := range groups {
+		group.Companies = len(companies[key])
+		group.ActiveUsers = len(users[key])
+		result = append(result, *group)
+	}
+
+	// Sort by event count descending
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].EventCount != result[j].EventCount {
+			return result[i].EventCount > result[j].EventCount
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// groupKeys returns the groups a company belongs to for a dimension
+func (s *AnalyticsService) groupKeys(companyID, dimension string) []string {
+	profile, _ := s.profile(companyID)
+
+	var value string
+	switch dimension {
+	case GroupByPlan:
+		value = profile.Plan
+	case GroupByRegion:
+		value = profile.Region
+	case GroupByCSMOwner:
+		value = profile.CSMOwner
+	case GroupByTag:
+		if len(profile.Tags) > 0 {
+			return profile.Tags
+		}
+	}
+
+	if value == "" {
+		return []string{unassignedGroup}
+	}
+	return []string{value}
+}
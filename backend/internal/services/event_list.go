@@ -0,0 +1,177 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Sort orders supported by ListEvents
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// EventFields lists the projectable event fields by JSON name
+var EventFields = []string{
+	"id",
+	"created_at",
+	"company_id",
+	"type",
+	"content",
+	"attribute",
+	"updated_at",
+	"original_timestamp",
+	"value",
+}
+
+// ListEvents returns a page of filtered raw events ordered by creation time
+func (s *AnalyticsService) ListEvents(params models.EventListParams) (models.EventListResponse, error) {
+	fields := params.Fields
+	if len(fields) == 0 {
+		fields = EventFields
+	}
+	for _, field := range fields {
+		if !isEventField(field) {
+			return models.EventListResponse{}, fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	descending := params.Sort == SortDescending
+	if params.Sort != "" && params.Sort != SortAscending && !descending {
+		return models.EventListResponse{}, fmt.Errorf("sort must be %q or %q", SortAscending, SortDescending)
+	}
+
+	// Use the same filters as the analytics endpoint
+	sorted := s.FilteredEvents(params.Filters)
+	if descending {
+		slices.Reverse(sorted)
+	}
+
+	// Resume after the cursor position
+	start := 0
+	if params.Cursor != "" {
+		cursorTime, cursorID, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return models.EventListResponse{}, err
+		}
+		cursorEvent := models.UsageEvent{ID: cursorID, CreatedAt: cursorTime}
+		start = sort.Search(len(sorted), func(i int) bool {
+			if descending {
+				return eventBefore(sorted[i], cursorEvent)
+			}
+			return eventBefore(cursorEvent, sorted[i])
+		})
+	}
+
+	end := min(start+params.Limit, len(sorted))
+	page := sorted[start:end]
+
+	response := models.EventListResponse{
+		Events:  make([]map[string]interface{}, 0, len(page)),
+		Total:   len(sorted),
+		HasMore: end < len(sorted),
+	}
+	for _, event := range page {
+		response.Events = append(response.Events, projectEvent(event, fields))
+	}
+	if response.HasMore && len(page) > 0 {
+		last := page[len(page)-1]
+		response.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return response, nil
+}
+
+// FilteredEvents returns the events matching params ordered by creation time
+func (s *AnalyticsService) FilteredEvents(params models.QueryParams) []models.UsageEvent {
+	events := s.filterEvents(params)
+
+	// Sort by creation time, breaking ties by ID so cursors are stable
+	// Events are copied, so redacting them leaves the stored events untouched
+	sorted := make([]models.UsageEvent, len(events))
+	for i, event := range events {
+		sorted[i] = s.redactEvent(event, params.Privacy)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return eventBefore(sorted[i], sorted[j])
+	})
+
+	return sorted
+}
+
+// eventBefore orders events by creation time, then by ID
+func eventBefore(a, b models.UsageEvent) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// encodeCursor builds an opaque cursor from the last returned event
+func encodeCursor(createdAt time.Time, id string) string {
+	raw := strconv.FormatInt(createdAt.UnixNano(), 10) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	nanos, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	return time.Unix(0, unixNano).UTC(), id, nil
+}
+
+// isEventField reports whether name is a projectable event field
+func isEventField(name string) bool {
+	for _, field := range EventFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// projectEvent returns the requested fields of an event keyed by JSON name
+func projectEvent(event models.UsageEvent, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		switch field {
+		case "id":
+			projected[field] = event.ID
+		case "created_at":
+			projected[field] = event.CreatedAt
+		case "company_id":
+			projected[field] = event.CompanyID
+		case "type":
+			projected[field] = event.Type
+		case "content":
+			projected[field] = event.Content
+		case "attribute":
+			projected[field] = event.Attribute
+		case "updated_at":
+			projected[field] = event.UpdatedAt
+		case "original_timestamp":
+			projected[field] = event.OriginalTimestamp
+		case "value":
+			projected[field] = event.Value
+		}
+	}
+	return projected
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"math"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/search"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// SearchEvents runs a full-text query against the event index and returns ranked matches
+func (s *AnalyticsService) SearchEvents(params models.EventSearchParams) (models.EventSearchResponse, error) {
+	// Hold the read lock so document IDs stay aligned with the event slice
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Pseudonymous user IDs from redacted responses search for the real email
+	// Callers who may not see emails can name users only that way, and their terms skip emails
+	var hits []search.Hit
+	var err error
+	switch {
+	case s.redacting(params.Privacy):
+		hits, err = s.index.SearchRedacted(params.Query, s.resolveUser)
+	case s.redactor != nil:
+		hits, err = s.index.Search(s.redactor.ResolveQuery(params.Query))
+	default:
+		hits, err = s.index.Search(params.Query)
+	}
+	if err != nil {
+		return models.EventSearchResponse{}, err
+	}
+
+	// Reuse the analytics date logic for the optional date filters
+	fromDate, toDate, hasDates := s.dateBounds(models.QueryParams{
+		DateRange: params.DateRange,
+		FromDate:  params.FromDate,
+		ToDate:    params.ToDate,
+	})
+
+	// Keep only hits that also match the company and date filters
+	var matched []models.EventSearchResult
+	for _, hit := range hits {
+		event := s.events[hit.DocID]
+		if !inScope(params.Scope, event.CompanyID) {
+			continue
+		}
+		if params.CompanyID != "" && event.CompanyID != params.CompanyID {
+			continue
+		}
+		if hasDates && !inDateRange(event.CreatedAt, fromDate, toDate) {
+			continue
+		}
+		matched = append(matched, models.EventSearchResult{
+			Event: s.redactEvent(event, params.Privacy),
+			Score: math.Round(hit.Score*1000) / 1000,
+		})
+	}
+
+	response := models.EventSearchResponse{
+		Query:   params.Query,
+		Total:   len(matched),
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+		Results: []models.EventSearchResult{},
+	}
+
+	// Apply pagination
+	if params.Offset < len(matched) {
+		end := min(params.Offset+params.Limit, len(matched))
+		response.Results = matched[params.Offset:end]
+	}
+
+	return response, nil
+}
+
+// searchDocument builds the searchable fields for an event
+func searchDocument(id int, event models.UsageEvent) search.Document {
+	content := utils.ParseEventContent(event.Content)
+
+	return search.Document{
+		ID: id,
+		Fields: map[string]string{
+			search.FieldContent: event.Content,
+			search.FieldText:    privacy.StripEmails(event.Content),
+			search.FieldUser:    content.Email,
+			search.FieldCompany: content.CompanyName + " " + event.CompanyID,
+			search.FieldPath:    content.Path,
+		},
+	}
+}
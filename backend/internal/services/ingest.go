@@ -0,0 +1,108 @@
+package services
+
+import (
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// IngestSubscriber is called with each batch of newly ingested events
+// Subscribers run synchronously after the batch is stored, so they should
+// hand slow work off to their own goroutines
+type IngestSubscriber func(events []models.UsageEvent)
+
+// Subscribe registers a function to be called after every successful ingest
+func (s *AnalyticsService) Subscribe(subscriber IngestSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, subscriber)
+}
+
+// Events returns the current events; the slice must not be modified
+func (s *AnalyticsService) Events() []models.UsageEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events
+}
+
+// DatasetStats returns the size and freshness of the loaded events and the ingest counters
+func (s *AnalyticsService) DatasetStats() models.DatasetStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return models.DatasetStats{
+		Events:      len(s.events),
+		Companies:   len(s.rollups.Companies()),
+		NewestEvent: s.rollups.Latest(),
+		Ingest:      s.ingestStats,
+	}
+}
+
+// Ingest validates and stores new events, indexes them and notifies subscribers
+// Invalid or duplicate events are rejected individually; the rest are kept
+func (s *AnalyticsService) Ingest(events []models.UsageEvent) models.IngestResponse {
+	response := models.IngestResponse{
+		Rejected: []models.IngestRejection{},
+		Total:    len(events),
+	}
+
+	s.mu.Lock()
+	accepted := make([]models.UsageEvent, 0, len(events))
+	for i, event := range events {
+		if problem := validateEvent(event); problem != "" {
+			response.Rejected = append(response.Rejected, models.IngestRejection{Index: i, ID: event.ID, Error: problem})
+			s.ingestStats.Invalid++
+			continue
+		}
+		if s.eventIDs[event.ID] {
+			response.Rejected = append(response.Rejected, models.IngestRejection{Index: i, ID: event.ID, Error: "duplicate event id"})
+			s.ingestStats.Duplicate++
+			continue
+		}
+
+		// Fill optional timestamps the same way the CSV loader would
+		if event.UpdatedAt.IsZero() {
+			event.UpdatedAt = event.CreatedAt
+		}
+		if event.OriginalTimestamp.IsZero() {
+			event.OriginalTimestamp = event.CreatedAt
+		}
+
+		// Appending never touches elements visible to readers holding an older slice
+		s.eventIDs[event.ID] = true
+		s.index.Add(searchDocument(len(s.events), event))
+		s.rollups.Add(event)
+		s.registerEmails(event)
+		s.events = append(s.events, event)
+		accepted = append(accepted, event)
+	}
+	s.ingestStats.Batches++
+	s.ingestStats.Accepted += uint64(len(accepted))
+	subscribers := s.subscribers
+	s.mu.Unlock()
+
+	response.Accepted = len(accepted)
+	if len(accepted) > 0 {
+		for _, subscriber := range subscribers {
+			subscriber(accepted)
+		}
+	}
+
+	return response
+}
+
+// validateEvent returns a description of what is wrong with an event, or "" if it is valid
+func validateEvent(event models.UsageEvent) string {
+	var problems []string
+	if strings.TrimSpace(event.ID) == "" {
+		problems = append(problems, "id is required")
+	}
+	if event.CreatedAt.IsZero() {
+		problems = append(problems, "created_at is required")
+	}
+	if strings.TrimSpace(event.CompanyID) == "" {
+		problems = append(problems, "company_id is required")
+	}
+	if strings.TrimSpace(event.Content) == "" {
+		problems = append(problems, "content is required")
+	}
+	return strings.Join(problems, "; ")
+}
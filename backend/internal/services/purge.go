@@ -0,0 +1,86 @@
+package services
+
+import (
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/rollups"
+	"usage-analytics-dashboard/internal/search"
+)
+
+// PurgeSubscriber is called with the events removed by each purge
+type PurgeSubscriber func(removed []models.UsageEvent)
+
+// SubscribePurge registers a function to be called after every purge that removed events
+func (s *AnalyticsService) SubscribePurge(subscriber PurgeSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeSubscribers = append(s.purgeSubscribers, subscriber)
+}
+
+// Purge removes every event matching match and returns the removed events
+// Aggregates are computed from the remaining events, so they reflect the purge immediately
+func (s *AnalyticsService) Purge(match func(models.UsageEvent) bool) []models.UsageEvent {
+	s.mu.Lock()
+	var removed []models.UsageEvent
+	kept := make([]models.UsageEvent, 0, len(s.events))
+	for _, event := range s.events {
+		if match(event) {
+			removed = append(removed, event)
+		} else {
+			kept = append(kept, event)
+		}
+	}
+
+	if len(removed) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+
+	// Index document IDs are slice positions, so the index is rebuilt rather than patched,
+	// and rollups cannot un-count a latest activity, so they are rebuilt too.
+	// The old slice is left untouched for readers that still hold it
+	s.events = kept
+	s.index = search.NewIndex()
+	for i, event := range kept {
+		s.index.Add(searchDocument(i, event))
+	}
+	s.rollups = rollups.Build(kept)
+	for _, event := range removed {
+		delete(s.eventIDs, event.ID)
+	}
+	subscribers := s.purgeSubscribers
+	s.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(removed)
+	}
+	return removed
+}
+
+// ResolveUser returns the email for a pseudonymous user ID, or the input when it is not one
+func (s *AnalyticsService) ResolveUser(user string) string {
+	return s.resolvePseudonym(user)
+}
+
+// DigestUser returns the keyed digest recorded for an email in place of the email
+func (s *AnalyticsService) DigestUser(email string) string {
+	return s.redactor.Digest(email)
+}
+
+// ContainsUser reports whether an event was made by, or mentions, email
+// Only whole addresses match, ignoring case, so s.doe@x.com does not match j.s.doe@x.com
+func ContainsUser(event models.UsageEvent, email string) bool {
+	fields := []string{event.Content, event.Attribute}
+	if event.Value != nil {
+		fields = append(fields, *event.Value)
+	}
+	for _, field := range fields {
+		for _, mentioned := range privacy.Emails(field) {
+			if strings.EqualFold(mentioned, email) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"usage-analytics-dashboard/internal/models"
+)
+
+func TestContainsUser(t *testing.T) {
+	value := "owner=S.Cherveny@sample.com"
+	tests := []struct {
+		name  string
+		event models.UsageEvent
+		email string
+		want  bool
+	}{
+		{"author", models.UsageEvent{Content: "Login - Sample Company s.cherveny@sample.com /home"}, "s.cherveny@sample.com", true},
+		{"case-insensitive", models.UsageEvent{Content: "Login - Sample Company S.Cherveny@Sample.com /home"}, "s.cherveny@sample.com", true},
+		{"longer local part", models.UsageEvent{Content: "Login - Sample Company wes.cherveny@sample.com /home"}, "s.cherveny@sample.com", false},
+		{"longer domain", models.UsageEvent{Content: "Login - Sample Company s.cherveny@sample.com.au /home"}, "s.cherveny@sample.com", false},
+		{"attribute", models.UsageEvent{Content: "Invite - Sample Company a@sample.com /team", Attribute: "invitee:s.cherveny@sample.com"}, "s.cherveny@sample.com", true},
+		{"value", models.UsageEvent{Content: "Assign - Sample Company a@sample.com /team", Value: &value}, "s.cherveny@sample.com", true},
+		{"no email", models.UsageEvent{Content: "Nightly sync"}, "s.cherveny@sample.com", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ContainsUser(test.event, test.email); got != test.want {
+				t.Errorf("ContainsUser(%q) = %v, want %v", test.email, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+)
+
+// Redaction is applied to finished results only, so unique-user counts and
+// per-user aggregates are always computed from the real emails
+
+// registerEmails records an event's emails so their pseudonyms can be resolved in queries
+func (s *AnalyticsService) registerEmails(event models.UsageEvent) {
+	if s.redactor != nil {
+		s.redactor.Register(event.Content)
+	}
+}
+
+// redactUsers replaces top user emails according to mode, adding a stable pseudonymous ID
+func (s *AnalyticsService) redactUsers(users []models.UserActivity, mode string) []models.UserActivity {
+	if s.redactor == nil || mode == "" || mode == privacy.ModeNone {
+		return users
+	}
+
+	for i := range users {
+		users[i].UserID = s.redactor.Pseudonym(users[i].Email)
+		users[i].Email = s.redactor.Email(users[i].Email, mode)
+	}
+	return users
+}
+
+// redactEvent replaces emails in an event's free-text fields according to mode
+func (s *AnalyticsService) redactEvent(event models.UsageEvent, mode string) models.UsageEvent {
+	if s.redactor == nil || mode == "" || mode == privacy.ModeNone {
+		return event
+	}
+
+	event.Content = s.redactor.Text(event.Content, mode)
+	event.Attribute = s.redactor.Text(event.Attribute, mode)
+	if event.Value != nil {
+		value := s.redactor.Text(*event.Value, mode)
+		event.Value = &value
+	}
+	return event
+}
+
+// redacting reports whether results for mode hide emails
+func (s *AnalyticsService) redacting(mode string) bool {
+	return s.redactor != nil && mode != "" && mode != privacy.ModeNone
+}
+
+// resolveUser returns the email behind a pseudonymous user ID, and false for any other term
+func (s *AnalyticsService) resolveUser(term string) (string, bool) {
+	return s.redactor.Resolve(strings.ToLower(strings.TrimSpace(term)))
+}
+
+// resolvePseudonym turns a pseudonymous user ID back into its email, leaving other text unchanged
+func (s *AnalyticsService) resolvePseudonym(term string) string {
+	if s.redactor == nil {
+		return term
+	}
+	if email, ok := s.redactor.Resolve(strings.TrimSpace(term)); ok {
+		return email
+	}
+	return term
+}
+
+// RedactUser presents a user's email according to mode for output outside the dashboard,
+// returning the pseudonymous ID alongside it when redacting
+// Without a redactor the email is withheld entirely rather than sent in the clear
+func (s *AnalyticsService) RedactUser(email, mode string) (userID, redacted string) {
+	if mode == privacy.ModeNone {
+		return "", email
+	}
+	if s.redactor == nil {
+		return "", ""
+	}
+	return s.redactor.Pseudonym(email), s.redactor.Email(email, mode)
+}
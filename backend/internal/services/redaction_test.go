@@ -0,0 +1,101 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+)
+
+func redactionTestService(t *testing.T) (*AnalyticsService, string) {
+	t.Helper()
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	event := func(id, content string) models.UsageEvent {
+		return models.UsageEvent{ID: id, CreatedAt: created, CompanyID: "company-1", Type: "Action", Content: content, UpdatedAt: created, OriginalTimestamp: created}
+	}
+	redactor := privacy.NewRedactor([]byte("0123456789abcdef"))
+	service := NewAnalyticsService([]models.UsageEvent{
+		event("1", "Login - Sample Company jane.doe@sample.com /home"),
+		event("2", "Open - Sample Company bob@sample.com /reports/jane"),
+		event("3", "Open - Sample Company ann@sample.com /home"),
+	}, redactor, nil)
+	return service, redactor.Pseudonym("jane.doe@sample.com")
+}
+
+func TestRedactedSearchFindsUsersOnlyByPseudonym(t *testing.T) {
+	service, pseudonym := redactionTestService(t)
+
+	tests := []struct {
+		name    string
+		privacy string
+		search  string
+		want    []string
+	}{
+		{"email hidden", privacy.ModeHash, "jane.doe@sample.com", nil},
+		{"masked email hidden", privacy.ModeMask, "jane.doe@sample.com", nil},
+		{"part of an email hidden", privacy.ModeHash, "jane", []string{"2"}},
+		{"domain hidden", privacy.ModeMask, "sample.com", nil},
+		{"pseudonym", privacy.ModeHash, pseudonym, []string{"1"}},
+		{"text outside emails", privacy.ModeHash, "/home", []string{"1", "3"}},
+		{"email without privacy", privacy.ModeNone, "jane.doe@sample.com", []string{"1"}},
+		{"part of an email without privacy", privacy.ModeNone, "jane", []string{"1", "2"}},
+		{"pseudonym without privacy", privacy.ModeNone, pseudonym, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := service.FilteredEvents(models.QueryParams{
+				Search:   tt.search,
+				Privacy:  tt.privacy,
+				FromDate: "2025-06-01",
+				ToDate:   "2025-06-30",
+			})
+			var got []string
+			for _, event := range events {
+				got = append(got, event.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("search %q: got events %v, want %v", tt.search, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactedEventSearchFindsUsersOnlyByPseudonym(t *testing.T) {
+	service, pseudonym := redactionTestService(t)
+
+	tests := []struct {
+		name    string
+		privacy string
+		query   string
+		want    []string
+	}{
+		{"user email hidden", privacy.ModeHash, "user:jane.doe@sample.com", nil},
+		{"user name hidden", privacy.ModeHash, "user:jane", nil},
+		{"email in text hidden", privacy.ModeMask, `"jane.doe@sample.com"`, nil},
+		{"part of an email hidden", privacy.ModeHash, "jane", []string{"2"}},
+		{"prefix of an email hidden", privacy.ModeHash, "doe*", nil},
+		{"user pseudonym", privacy.ModeHash, "user:" + pseudonym, []string{"1"}},
+		{"bare pseudonym", privacy.ModeHash, pseudonym, []string{"1"}},
+		{"pseudonym or a hidden name", privacy.ModeHash, pseudonym + " OR ann", []string{"1"}},
+		{"user email without privacy", privacy.ModeNone, "user:jane.doe@sample.com", []string{"1"}},
+		{"part of an email without privacy", privacy.ModeNone, "jane", []string{"1", "2"}},
+		{"pseudonym without privacy", privacy.ModeNone, "user:" + pseudonym, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.SearchEvents(models.EventSearchParams{Query: tt.query, Privacy: tt.privacy, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, result := range response.Results {
+				got = append(got, result.Event.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("query %q: got events %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/rollups"
+)
+
+// Trend granularities accepted by ResampleTrends
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// Granularities lists every trend granularity
+var Granularities = []string{GranularityDay, GranularityWeek, GranularityMonth}
+
+// ResampleTrends sums daily trends into weeks starting on Monday or calendar months
+// Buckets are labelled with their first day, so the first and last may start outside the range
+// and only cover part of it; series totals are unchanged
+func ResampleTrends(trends models.TrendsV1, granularity string) (models.TrendsV1, error) {
+	var bucketOf func(day time.Time) time.Time
+	switch granularity {
+	case "", GranularityDay:
+		return trends, nil
+	case GranularityWeek:
+		bucketOf = func(day time.Time) time.Time {
+			return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		}
+	case GranularityMonth:
+		bucketOf = func(day time.Time) time.Time {
+			return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+	default:
+		return models.TrendsV1{}, fmt.Errorf("granularity must be one of %v", Granularities)
+	}
+
+	// Days are ascending, so buckets come out in order
+	resampled := models.TrendsV1{
+		Days:   []string{},
+		Order:  trends.Order,
+		Series: make(map[string]models.TrendSeries, len(trends.Series)),
+	}
+	buckets := make([]int, len(trends.Days))
+	for i, day := range trends.Days {
+		parsed, err := time.Parse(rollups.DayLayout, day)
+		if err != nil {
+			return models.TrendsV1{}, fmt.Errorf("invalid trend day %q: %w", day, err)
+		}
+		bucket := bucketOf(parsed).Format(rollups.DayLayout)
+		if n := len(resampled.Days); n == 0 || resampled.Days[n-1] != bucket {
+			resampled.Days = append(resampled.Days, bucket)
+		}
+		buckets[i] = len(resampled.Days) - 1
+	}
+
+	resample := func(points []models.UsageTrend) []models.UsageTrend {
+		summed := make([]models.UsageTrend, len(resampled.Days))
+		for i, day := range resampled.Days {
+			summed[i].Date = day
+		}
+		// Every series covers the same days, so points line up with Days
+		for i, point := range points {
+			summed[buckets[i]].Events += point.Events
+		}
+		return summed
+	}
+
+	for companyID, series := range trends.Series {
+		series.Points = resample(series.Points)
+		resampled.Series[companyID] = series
+	}
+	resampled.Totals = resample(trends.Totals)
+	return resampled, nil
+}
@@ -0,0 +1,135 @@
+package stream
+
+import (
+	"strconv"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/rollups"
+)
+
+// change records the company days one ingested batch touched
+// A reset change stands for a purge, after which any count may have dropped
+type change struct {
+	seq     uint64
+	touched map[string]map[string]bool
+	reset   bool
+}
+
+// Hub numbers ingested batches and wakes the streams listening for them
+// It keeps the most recent changes so a reconnecting client only receives what it missed
+type Hub struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	history     []change
+	historySize int
+	listeners   map[chan struct{}]struct{}
+}
+
+// NewHub creates a hub remembering the last historySize batches
+func NewHub(historySize int) *Hub {
+	return &Hub{
+		// The epoch tells positions from before a restart apart from current ones
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: historySize,
+		listeners:   make(map[chan struct{}]struct{}),
+	}
+}
+
+// HandleIngest records the company days of newly ingested events and wakes listeners
+func (h *Hub) HandleIngest(events []models.UsageEvent) {
+	touched := make(map[string]map[string]bool)
+	for _, event := range events {
+		days, ok := touched[event.CompanyID]
+		if !ok {
+			days = make(map[string]bool)
+			touched[event.CompanyID] = days
+		}
+		days[event.CreatedAt.UTC().Format(rollups.DayLayout)] = true
+	}
+	h.record(change{touched: touched})
+}
+
+// HandlePurge wakes listeners so they resend their full state
+func (h *Hub) HandlePurge(removed []models.UsageEvent) {
+	if len(removed) > 0 {
+		h.record(change{reset: true})
+	}
+}
+
+// record appends a change and notifies every listener without blocking
+func (h *Hub) record(c change) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	c.seq = h.seq
+	h.history = append(h.history, c)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for listener := range h.listeners {
+		// A pending notification already covers this change
+		select {
+		case listener <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Listen returns a channel signalled after each change and a function that stops it
+func (h *Hub) Listen() (<-chan struct{}, func()) {
+	listener := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	h.listeners[listener] = struct{}{}
+	h.mu.Unlock()
+
+	return listener, func() {
+		h.mu.Lock()
+		delete(h.listeners, listener)
+		h.mu.Unlock()
+	}
+}
+
+// Epoch identifies this hub; sequence numbers restart with each process
+func (h *Hub) Epoch() string {
+	return h.epoch
+}
+
+// Since returns the company days touched after seq and the current sequence number
+// ok is false when the changes cannot be replayed: seq is from another epoch or
+// beyond the history, or a purge happened since
+func (h *Hub) Since(epoch string, seq uint64) (touched map[string]map[string]bool, current uint64, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if epoch != h.epoch || seq > h.seq {
+		return nil, h.seq, false
+	}
+	// The change right after seq must still be in the history
+	if seq < h.seq && (len(h.history) == 0 || h.history[0].seq > seq+1) {
+		return nil, h.seq, false
+	}
+
+	touched = make(map[string]map[string]bool)
+	for _, c := range h.history {
+		if c.seq <= seq {
+			continue
+		}
+		if c.reset {
+			return nil, h.seq, false
+		}
+		for companyID, days := range c.touched {
+			if touched[companyID] == nil {
+				touched[companyID] = make(map[string]bool)
+			}
+			for day := range days {
+				touched[companyID][day] = true
+			}
+		}
+	}
+	return touched, h.seq, true
+}
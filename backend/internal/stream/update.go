@@ -0,0 +1,93 @@
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Cursor identifies what a client has seen: a hub position and the days its trends covered
+// It is sent as the SSE event ID, so browsers return it in Last-Event-ID when reconnecting
+type Cursor struct {
+	Epoch string
+	Seq   uint64
+	// From and To are the first and last trend days, empty when there were none
+	From string
+	To   string
+}
+
+// NewCursor returns the cursor of a snapshot taken at a hub position
+func NewCursor(epoch string, seq uint64, snapshot models.AnalyticsSnapshot) Cursor {
+	cursor := Cursor{Epoch: epoch, Seq: seq}
+	if days := snapshot.Trends.Days; len(days) > 0 {
+		cursor.From = days[0]
+		cursor.To = days[len(days)-1]
+	}
+	return cursor
+}
+
+// String encodes the cursor as an event ID
+func (c Cursor) String() string {
+	return fmt.Sprintf("%s/%d/%s/%s", c.Epoch, c.Seq, c.From, c.To)
+}
+
+// ParseCursor decodes an event ID produced by Cursor.String
+func ParseCursor(id string) (Cursor, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 4 {
+		return Cursor{}, fmt.Errorf("invalid event ID %q", id)
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid event ID %q", id)
+	}
+	return Cursor{Epoch: parts[0], Seq: seq, From: parts[2], To: parts[3]}, nil
+}
+
+// SameWindow reports whether two cursors cover the same days
+// When the days differ, every point moved and the client needs a snapshot
+func (c Cursor) SameWindow(other Cursor) bool {
+	return c.From == other.From && c.To == other.To
+}
+
+// NewUpdate returns the current values of the touched company days visible in snapshot
+// ok is false when none of them is visible, so there is nothing to send
+func NewUpdate(snapshot models.AnalyticsSnapshot, touched map[string]map[string]bool) (update models.AnalyticsUpdate, ok bool) {
+	update = models.AnalyticsUpdate{
+		Summary: snapshot.Summary,
+		Order:   snapshot.Trends.Order,
+		Series:  []models.TrendSeries{},
+		Totals:  []models.UsageTrend{},
+	}
+
+	changedDays := make(map[string]bool)
+	for _, companyID := range snapshot.Trends.Order {
+		days, ok := touched[companyID]
+		if !ok {
+			continue
+		}
+		series := snapshot.Trends.Series[companyID]
+		var points []models.UsageTrend
+		for _, point := range series.Points {
+			if days[point.Date] {
+				points = append(points, point)
+				changedDays[point.Date] = true
+			}
+		}
+		if len(points) > 0 {
+			series.Points = points
+			update.Series = append(update.Series, series)
+		}
+	}
+	if len(update.Series) == 0 {
+		return update, false
+	}
+
+	for _, total := range snapshot.Trends.Totals {
+		if changedDays[total.Date] {
+			update.Totals = append(update.Totals, total)
+		}
+	}
+	return update, true
+}
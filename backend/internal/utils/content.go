@@ -0,0 +1,55 @@
+package utils
+
+import "strings"
+
+// EventContent holds the pieces embedded in an event's content string
+// Format: "User active CMMS - Company Name user@email.com /path"
+type EventContent struct {
+	Action      string
+	CompanyName string
+	Email       string
+	Path        string
+}
+
+// ParseEventContent splits an event content string into its parts
+func ParseEventContent(content string) EventContent {
+	parsed := EventContent{}
+
+	action, rest, found := strings.Cut(content, " - ")
+	if !found {
+		// No separator - treat the whole string as the action
+		parsed.Action = strings.TrimSpace(content)
+		return parsed
+	}
+	parsed.Action = strings.TrimSpace(action)
+
+	words := strings.Fields(rest)
+	emailIndex := -1
+	for i, word := range words {
+		if strings.Contains(word, "@") {
+			emailIndex = i
+			break
+		}
+	}
+
+	if emailIndex < 0 {
+		// Fallback: no email found, company name is the first word
+		if len(words) > 0 {
+			parsed.CompanyName = words[0]
+		}
+		return parsed
+	}
+
+	parsed.CompanyName = strings.Join(words[:emailIndex], " ")
+	parsed.Email = words[emailIndex]
+
+	// Path is the first word after the email that looks like a route
+	for _, word := range words[emailIndex+1:] {
+		if strings.HasPrefix(word, "/") {
+			parsed.Path = word
+			break
+		}
+	}
+
+	return parsed
+}
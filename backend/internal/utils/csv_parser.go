@@ -2,11 +2,11 @@ package utils
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
 	"strings"
-	"time"
 	"usage-analytics-dashboard/internal/models"
 )
 
@@ -23,10 +23,22 @@ func NewCSVParser(filePath string) *CSVParser {
 }
 
 // ParseEvents reads and parses CSV data into UsageEvent structs
+// Rows that cannot be parsed are skipped with a warning
 func (p *CSVParser) ParseEvents() ([]models.UsageEvent, error) {
+	return parseEvents(p, "CSV", "CSV line")
+}
+
+// DryRun parses the file as ParseEvents would and reports on its contents
+func (p *CSVParser) DryRun() (SourceReport, error) {
+	return dryRun(p, p.filePath)
+}
+
+// scan reads the header, then calls visit with every data row in order
+// It fails when the file cannot be read or the header lacks a required column
+func (p *CSVParser) scan(visit func(row sourceRow)) (columnIndex, error) {
 	file, err := os.Open(p.filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+		return columnIndex{}, fmt.Errorf("failed to open CSV file: %w", err)
 	}
 	defer file.Close()
 
@@ -34,76 +46,42 @@ func (p *CSVParser) ParseEvents() ([]models.UsageEvent, error) {
 	reader.LazyQuotes = true
 	reader.FieldsPerRecord = -1
 
-	records, err := reader.ReadAll()
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return columnIndex{}, fmt.Errorf("CSV file is empty or missing data")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+		return columnIndex{}, fmt.Errorf("failed to read CSV header: %w", err)
 	}
-
-	if len(records) < 2 {
-		return nil, fmt.Errorf("CSV file is empty or missing data")
+	columns := newColumnIndex(header)
+	if missing := columns.missing(); len(missing) > 0 {
+		return columns, fmt.Errorf("CSV header is missing columns: %s", strings.Join(missing, ", "))
 	}
 
-	events := make([]models.UsageEvent, 0, len(records)-1)
-
-	for i, record := range records[1:] {
-		if len(record) < 9 {
-			fmt.Printf("Warning: Row %d has insufficient columns (%d), skipping\n", i+1, len(record))
-			continue
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return columns, nil
 		}
-
-		event, err := p.parseRow(record, i+1)
-		if err != nil {
-			fmt.Printf("Warning: Failed to parse row %d: %v\n", i+1, err)
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// The reader resumes at the next line after a malformed one
+			visit(sourceRow{line: parseErr.StartLine, err: parseErr.Err})
 			continue
 		}
-
-		events = append(events, event)
-	}
-
-	return events, nil
-}
-
-// parseRow converts a CSV row to UsageEvent
-func (p *CSVParser) parseRow(record []string, rowNum int) (models.UsageEvent, error) {
-	createdAt, err := time.Parse("2006-01-02 15:04:05.999999999-07", record[1])
-	if err != nil {
-		return models.UsageEvent{}, fmt.Errorf("invalid created_at format: %w", err)
-	}
-
-	updatedAt, err := time.Parse("2006-01-02 15:04:05.999999999-07", record[7])
-	if err != nil {
-		return models.UsageEvent{}, fmt.Errorf("invalid updated_at format: %w", err)
-	}
-
-	var originalTimestamp time.Time
-	if record[8] != "null" && record[8] != "" {
-		originalTimestamp, err = time.Parse("2006-01-02 15:04:05.999999999-07", record[8])
 		if err != nil {
-			return models.UsageEvent{}, fmt.Errorf("invalid original_timestamp format: %w", err)
+			return columns, fmt.Errorf("failed to read CSV file: %w", err)
 		}
-	} else {
-		originalTimestamp = createdAt
-	}
 
-	var value *string
-	if len(record) > 9 && record[9] != "null" && record[9] != "" {
-		if v, err := strconv.ParseFloat(record[9], 64); err == nil {
-			valueStr := strconv.FormatFloat(v, 'f', -1, 64)
-			value = &valueStr
+		line, _ := reader.FieldPos(0)
+		row := sourceRow{line: line, id: columns.get(record, "id")}
+		if len(record) < len(columns.header) {
+			row.err = fmt.Errorf("has %d columns, the header has %d", len(record), len(columns.header))
+		} else {
+			row.event, row.err = parseRow(columns, record)
 		}
+		visit(row)
 	}
-
-	return models.UsageEvent{
-		ID:                record[0],
-		CreatedAt:         createdAt,
-		CompanyID:         record[2],
-		Type:              record[3],
-		Content:           record[4],
-		Attribute:         record[5],
-		UpdatedAt:         updatedAt,
-		OriginalTimestamp: originalTimestamp,
-		Value:             value,
-	}, nil
 }
 
 // extractUserEmail extracts user email from content field
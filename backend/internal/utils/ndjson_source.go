@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// NDJSONSource reads events written one JSON object per line, as event pipelines emit them
+// Objects use the export's column names as keys, like the ingest journal and API event JSON
+type NDJSONSource struct {
+	filePath string
+}
+
+// NewNDJSONSource creates a reader for an NDJSON events file
+func NewNDJSONSource(filePath string) *NDJSONSource {
+	return &NDJSONSource{filePath: filePath}
+}
+
+// ParseEvents reads every line into a UsageEvent, skipping lines that cannot be parsed with a warning
+func (s *NDJSONSource) ParseEvents() ([]models.UsageEvent, error) {
+	return parseEvents(s, "NDJSON", "NDJSON line")
+}
+
+// DryRun parses the file as ParseEvents would and reports on its contents
+func (s *NDJSONSource) DryRun() (SourceReport, error) {
+	return dryRun(s, s.filePath)
+}
+
+// scan calls visit with every non-blank line in order
+// Lines need not share keys, so the columns are every key seen, and a missing required
+// key rejects only the lines without it
+func (s *NDJSONSource) scan(visit func(row sourceRow)) (columnIndex, error) {
+	columns := newColumnIndex(nil)
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return columns, fmt.Errorf("failed to open NDJSON file: %w", err)
+	}
+	defer file.Close()
+
+	// positions maps keys as written to their column, so each is normalized once
+	positions := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			visit(sourceRow{line: line, err: fmt.Errorf("invalid JSON: %w", err)})
+			continue
+		}
+
+		record := make([]string, len(columns.header))
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			i, ok := positions[key]
+			if !ok {
+				i = columns.add(key)
+				positions[key] = i
+			}
+			if i >= len(record) {
+				record = append(record, make([]string, i+1-len(record))...)
+			}
+			record[i] = jsonField(object[key])
+		}
+
+		row := sourceRow{line: line, id: columns.get(record, "id")}
+		row.event, row.err = parseRow(columns, record)
+		visit(row)
+	}
+	if err := scanner.Err(); err != nil {
+		return columns, fmt.Errorf("failed to read NDJSON file: %w", err)
+	}
+	return columns, nil
+}
+
+// jsonField renders a JSON value the way the CSV export writes it: strings unquoted,
+// null empty, and numbers, booleans, objects and arrays as their JSON text
+func jsonField(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	if value := strings.TrimSpace(string(raw)); value != "null" {
+		return value
+	}
+	return ""
+}
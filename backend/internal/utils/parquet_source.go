@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// ParquetSource reads events from a Parquet file, as the data warehouse exports them
+// Only top-level columns are read; timestamp columns may be strings or Parquet timestamps
+type ParquetSource struct {
+	filePath string
+}
+
+// NewParquetSource creates a reader for a Parquet events file
+func NewParquetSource(filePath string) *ParquetSource {
+	return &ParquetSource{filePath: filePath}
+}
+
+// ParseEvents reads every row into a UsageEvent, skipping rows that cannot be parsed with a warning
+func (s *ParquetSource) ParseEvents() ([]models.UsageEvent, error) {
+	return parseEvents(s, "Parquet", "Parquet row")
+}
+
+// DryRun parses the file as ParseEvents would and reports on its contents
+func (s *ParquetSource) DryRun() (SourceReport, error) {
+	return dryRun(s, s.filePath)
+}
+
+// parquetBatch is how many rows are read at a time
+const parquetBatch = 1024
+
+// scan reads the schema, then calls visit with every row in order
+// It fails when the file cannot be read or the schema lacks a required column
+func (s *ParquetSource) scan(visit func(row sourceRow)) (columnIndex, error) {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return columnIndex{}, fmt.Errorf("failed to open Parquet file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return columnIndex{}, fmt.Errorf("failed to open Parquet file: %w", err)
+	}
+	parquetFile, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		return columnIndex{}, fmt.Errorf("failed to read Parquet file: %w", err)
+	}
+
+	// Values name their leaf column, which is mapped to the record position of its top-level field
+	schema := parquetFile.Schema()
+	columns := newColumnIndex(nil)
+	leaves := make(map[int]parquetColumn)
+	for _, field := range schema.Fields() {
+		position := columns.add(field.Name())
+		// Nested and repeated fields are listed but not read
+		if !field.Leaf() || field.Repeated() {
+			continue
+		}
+		if leaf, ok := schema.Lookup(field.Name()); ok {
+			leaves[leaf.ColumnIndex] = parquetColumn{position: position, node: leaf.Node}
+		}
+	}
+	if missing := columns.missing(); len(missing) > 0 {
+		return columns, fmt.Errorf("Parquet schema is missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	line := 0
+	buffer := make([]parquet.Row, parquetBatch)
+	for _, rowGroup := range parquetFile.RowGroups() {
+		rows := rowGroup.Rows()
+		for {
+			n, err := rows.ReadRows(buffer)
+			for _, values := range buffer[:n] {
+				line++
+				record := make([]string, len(columns.header))
+				for _, value := range values {
+					if column, ok := leaves[value.Column()]; ok {
+						record[column.position] = parquetField(column.node, value)
+					}
+				}
+				row := sourceRow{line: line, id: columns.get(record, "id")}
+				row.event, row.err = parseRow(columns, record)
+				visit(row)
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				rows.Close()
+				return columns, fmt.Errorf("failed to read Parquet file: %w", err)
+			}
+		}
+		rows.Close()
+	}
+	return columns, nil
+}
+
+// parquetColumn is a readable top-level column
+type parquetColumn struct {
+	position int
+	node     parquet.Node
+}
+
+// parquetField renders a value the way the CSV export writes it; timestamps and dates become RFC 3339
+func parquetField(node parquet.Node, value parquet.Value) string {
+	if value.IsNull() {
+		return ""
+	}
+
+	columnType := node.Type()
+	if logical := columnType.LogicalType(); logical != nil {
+		switch annotation := logical.Value.(type) {
+		case *format.TimestampType:
+			if annotation.Unit.Value != nil {
+				return formatUnix(value.Int64(), annotation.Unit.Value.Duration())
+			}
+		case *format.DateType:
+			return formatUnix(int64(value.Int32()), 24*time.Hour)
+		}
+	}
+	if converted := columnType.ConvertedType(); converted != nil {
+		switch *converted {
+		case deprecated.TimestampMillis:
+			return formatUnix(value.Int64(), time.Millisecond)
+		case deprecated.TimestampMicros:
+			return formatUnix(value.Int64(), time.Microsecond)
+		case deprecated.Date:
+			return formatUnix(int64(value.Int32()), 24*time.Hour)
+		}
+	}
+
+	switch value.Kind() {
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return string(value.ByteArray())
+	case parquet.Int96:
+		// Hive and Spark write timestamps as INT96: nanoseconds of the day, then the Julian day
+		return int96Time(value.Int96()).Format(time.RFC3339Nano)
+	case parquet.Float:
+		return strconv.FormatFloat(float64(value.Float()), 'f', -1, 32)
+	case parquet.Double:
+		return strconv.FormatFloat(value.Double(), 'f', -1, 64)
+	case parquet.Int32:
+		return strconv.FormatInt(int64(value.Int32()), 10)
+	case parquet.Int64:
+		return strconv.FormatInt(value.Int64(), 10)
+	case parquet.Boolean:
+		return strconv.FormatBool(value.Boolean())
+	}
+	return value.String()
+}
+
+// formatUnix formats n units since the Unix epoch as an RFC 3339 timestamp in UTC
+func formatUnix(n int64, unit time.Duration) string {
+	seconds := int64(time.Second / unit)
+	if seconds == 0 {
+		// Units of a second or more, such as days
+		return time.Unix(n*int64(unit/time.Second), 0).UTC().Format(time.RFC3339Nano)
+	}
+	return time.Unix(n/seconds, (n%seconds)*int64(unit)).UTC().Format(time.RFC3339Nano)
+}
+
+// julianUnixEpoch is the Julian day of 1970-01-01
+const julianUnixEpoch = 2440588
+
+// int96Time converts a legacy INT96 timestamp
+func int96Time(value deprecated.Int96) time.Time {
+	nanos := int64(uint64(value[0]) | uint64(value[1])<<32)
+	days := int64(value[2]) - julianUnixEpoch
+	return time.Unix(days*86400, nanos).UTC()
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+// Event file formats
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// SourceFormats lists every event file format
+var SourceFormats = []string{FormatCSV, FormatNDJSON, FormatParquet}
+
+// EventSource reads usage events from an export file
+// Every format has the export's columns, matched by name, and is checked the same way
+type EventSource interface {
+	// ParseEvents returns the file's events, skipping rows that cannot be parsed with a warning
+	ParseEvents() ([]models.UsageEvent, error)
+	// DryRun reports on the file's contents instead of returning events
+	DryRun() (SourceReport, error)
+}
+
+// NewEventSource returns a reader for path in format, or in the format its extension names when format is empty
+func NewEventSource(path, format string) (EventSource, error) {
+	if format == "" {
+		format = FormatOf(path)
+	}
+	switch format {
+	case FormatCSV:
+		return NewCSVParser(path), nil
+	case FormatNDJSON:
+		return NewNDJSONSource(path), nil
+	case FormatParquet:
+		return NewParquetSource(path), nil
+	case "":
+		return nil, fmt.Errorf("cannot tell the format of %s from its extension; expected one of %s", path, strings.Join(SourceFormats, ", "))
+	default:
+		return nil, fmt.Errorf("unknown events format %q; expected one of %s", format, strings.Join(SourceFormats, ", "))
+	}
+}
+
+// FormatOf returns the format a file's extension names, or "" when it names none
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".parquet", ".pq":
+		return FormatParquet
+	}
+	return ""
+}
+
+// timestampLayout is how the CSV export writes timestamps
+const timestampLayout = "2006-01-02 15:04:05.999999999-07"
+
+// Export columns; files may hold them in any order
+var (
+	requiredColumns = []string{"id", "created_at", "company_id", "type", "content"}
+	optionalColumns = []string{"attribute", "updated_at", "original_timestamp", "value"}
+)
+
+// sourceRow is one record of a file, parsed into an event or rejected with err
+// line is the line number in text formats and the row number in Parquet
+type sourceRow struct {
+	line  int
+	id    string
+	event models.UsageEvent
+	err   error
+}
+
+// rowScanner is implemented by every source
+type rowScanner interface {
+	// scan calls visit with every record in order and returns the file's columns
+	// It fails when the file cannot be read or lacks a required column
+	scan(visit func(row sourceRow)) (columnIndex, error)
+}
+
+// parseEvents collects a source's events; unit names a record in warnings, such as "CSV line"
+func parseEvents(source rowScanner, format, unit string) ([]models.UsageEvent, error) {
+	var events []models.UsageEvent
+	_, err := source.scan(func(row sourceRow) {
+		if row.err != nil {
+			log.Printf("Warning: skipping %s %d: %v", unit, row.line, row.err)
+			return
+		}
+		events = append(events, row.event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%s file is empty or missing data", format)
+	}
+	return events, nil
+}
+
+// parseRow converts a record to UsageEvent
+func parseRow(columns columnIndex, record []string) (models.UsageEvent, error) {
+	for _, name := range []string{"id", "company_id", "created_at"} {
+		if strings.TrimSpace(columns.get(record, name)) == "" {
+			return models.UsageEvent{}, fmt.Errorf("%s is empty", name)
+		}
+	}
+
+	createdAt, err := parseTimestamp(columns.get(record, "created_at"))
+	if err != nil {
+		return models.UsageEvent{}, fmt.Errorf("invalid created_at format: %w", err)
+	}
+
+	// Missing optional timestamps fall back to created_at, as for ingested events
+	updatedAt := createdAt
+	if raw := columns.get(record, "updated_at"); raw != "null" && raw != "" {
+		if updatedAt, err = parseTimestamp(raw); err != nil {
+			return models.UsageEvent{}, fmt.Errorf("invalid updated_at format: %w", err)
+		}
+	}
+
+	originalTimestamp := createdAt
+	if raw := columns.get(record, "original_timestamp"); raw != "null" && raw != "" {
+		if originalTimestamp, err = parseTimestamp(raw); err != nil {
+			return models.UsageEvent{}, fmt.Errorf("invalid original_timestamp format: %w", err)
+		}
+	}
+
+	var value *string
+	if raw := columns.get(record, "value"); raw != "null" && raw != "" {
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			valueStr := strconv.FormatFloat(v, 'f', -1, 64)
+			value = &valueStr
+		}
+	}
+
+	return models.UsageEvent{
+		ID:                columns.get(record, "id"),
+		CreatedAt:         createdAt,
+		CompanyID:         columns.get(record, "company_id"),
+		Type:              columns.get(record, "type"),
+		Content:           columns.get(record, "content"),
+		Attribute:         columns.get(record, "attribute"),
+		UpdatedAt:         updatedAt,
+		OriginalTimestamp: originalTimestamp,
+		Value:             value,
+	}, nil
+}
+
+// parseTimestamp accepts the CSV export's layout and RFC 3339, which JSON pipelines write
+func parseTimestamp(raw string) (time.Time, error) {
+	parsed, err := time.Parse(timestampLayout, raw)
+	if err == nil {
+		return parsed, nil
+	}
+	if parsed, rfcErr := time.Parse(time.RFC3339Nano, raw); rfcErr == nil {
+		return parsed, nil
+	}
+	return time.Time{}, err
+}
+
+// columnIndex locates the export's columns by their names
+type columnIndex struct {
+	header    []string
+	positions map[string]int
+}
+
+func newColumnIndex(header []string) columnIndex {
+	columns := columnIndex{positions: make(map[string]int, len(header))}
+	for _, name := range header {
+		columns.add(name)
+	}
+	return columns
+}
+
+// add appends a column to the header and returns the position its name is read from,
+// which is the first column with that name
+func (c *columnIndex) add(name string) int {
+	c.header = append(c.header, name)
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	if i, seen := c.positions[name]; seen {
+		return i
+	}
+	c.positions[name] = len(c.header) - 1
+	return len(c.header) - 1
+}
+
+// get returns a column's value in record, or "" when there is no such column
+func (c columnIndex) get(record []string, name string) string {
+	if i, ok := c.positions[name]; ok && i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+// missing lists the required columns the file lacks
+func (c columnIndex) missing() []string {
+	var missing []string
+	for _, name := range requiredColumns {
+		if _, ok := c.positions[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// unknown lists columns the parser ignores
+func (c columnIndex) unknown() []string {
+	var unknown []string
+	for name := range c.positions {
+		if !slices.Contains(requiredColumns, name) && !slices.Contains(optionalColumns, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
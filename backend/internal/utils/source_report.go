@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"sort"
+	"time"
+)
+
+// SourceReport describes what loading an events file would do, without loading it
+type SourceReport struct {
+	File    string   `json:"file"`
+	Columns []string `json:"columns"`
+	// MissingColumns are required columns the file lacks; CSV and Parquet rows are not read then
+	MissingColumns []string `json:"missingColumns"`
+	// UnknownColumns are columns the parser ignores
+	UnknownColumns []string `json:"unknownColumns"`
+
+	Rows     int `json:"rows"`
+	Accepted int `json:"accepted"`
+	// Rejected rows are skipped when the file is loaded
+	Rejected []RowIssue `json:"rejected"`
+	// DuplicateIDs are IDs on more than one accepted row, which would be counted twice
+	DuplicateIDs []DuplicateID `json:"duplicateIds"`
+
+	CreatedAt         *TimeRange `json:"createdAt"`
+	UpdatedAt         *TimeRange `json:"updatedAt"`
+	OriginalTimestamp *TimeRange `json:"originalTimestamp"`
+
+	Companies []CompanyRows `json:"companies"`
+	// UnparseableContent are rows whose content has no user or company, so they count towards no user
+	UnparseableContent []RowIssue `json:"unparseableContent"`
+	// UpdatedBeforeCreated are rows whose updated_at is earlier than their created_at
+	UpdatedBeforeCreated []RowIssue `json:"updatedBeforeCreated"`
+}
+
+// RowIssue is a problem found on one record of the file
+// Line is the line number in CSV and NDJSON files, and the row number in Parquet files
+type RowIssue struct {
+	Line   int    `json:"line"`
+	ID     string `json:"id,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// DuplicateID is an event ID and every record it appears on
+type DuplicateID struct {
+	ID    string `json:"id"`
+	Lines []int  `json:"lines"`
+}
+
+// TimeRange is the earliest and latest value of a timestamp column
+type TimeRange struct {
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// CompanyRows counts the accepted rows of one company
+type CompanyRows struct {
+	CompanyID string `json:"companyId"`
+	// Name is the company name found in the first row's content
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+// Failed reports whether loading the file would fail or silently lose or double count rows
+// Unknown columns, unparseable content and timestamp anomalies are warnings
+func (r SourceReport) Failed() bool {
+	return len(r.MissingColumns) > 0 || r.Accepted == 0 || len(r.Rejected) > 0 || len(r.DuplicateIDs) > 0
+}
+
+// Warned reports whether the file has problems that do not stop it from loading
+func (r SourceReport) Warned() bool {
+	return len(r.UnknownColumns) > 0 || len(r.UnparseableContent) > 0 || len(r.UpdatedBeforeCreated) > 0
+}
+
+// dryRun scans a source as ParseEvents would and reports on its contents instead of returning events
+// The error is only set when the file cannot be read; schema problems are part of the report
+func dryRun(source rowScanner, file string) (SourceReport, error) {
+	report := SourceReport{
+		File:                 file,
+		MissingColumns:       []string{},
+		UnknownColumns:       []string{},
+		Rejected:             []RowIssue{},
+		DuplicateIDs:         []DuplicateID{},
+		Companies:            []CompanyRows{},
+		UnparseableContent:   []RowIssue{},
+		UpdatedBeforeCreated: []RowIssue{},
+	}
+
+	idLines := make(map[string][]int)
+	var ids []string
+	companies := make(map[string]*CompanyRows)
+
+	columns, err := source.scan(func(row sourceRow) {
+		report.Rows++
+		if row.err != nil {
+			report.Rejected = append(report.Rejected, RowIssue{Line: row.line, ID: row.id, Reason: row.err.Error()})
+			return
+		}
+		report.Accepted++
+		event := row.event
+
+		if _, seen := idLines[event.ID]; !seen {
+			ids = append(ids, event.ID)
+		}
+		idLines[event.ID] = append(idLines[event.ID], row.line)
+
+		content := ParseEventContent(event.Content)
+		company, ok := companies[event.CompanyID]
+		if !ok {
+			company = &CompanyRows{CompanyID: event.CompanyID, Name: content.CompanyName}
+			companies[event.CompanyID] = company
+		}
+		company.Rows++
+
+		report.CreatedAt = extend(report.CreatedAt, event.CreatedAt)
+		report.UpdatedAt = extend(report.UpdatedAt, event.UpdatedAt)
+		report.OriginalTimestamp = extend(report.OriginalTimestamp, event.OriginalTimestamp)
+
+		if reason := contentProblem(content); reason != "" {
+			report.UnparseableContent = append(report.UnparseableContent, RowIssue{Line: row.line, ID: event.ID, Reason: reason})
+		}
+		if event.UpdatedAt.Before(event.CreatedAt) {
+			report.UpdatedBeforeCreated = append(report.UpdatedBeforeCreated, RowIssue{
+				Line:   row.line,
+				ID:     event.ID,
+				Reason: "updated_at is " + event.CreatedAt.Sub(event.UpdatedAt).String() + " before created_at",
+			})
+		}
+	})
+
+	// A missing column is reported rather than returned, so CI gets the full report
+	report.Columns = columns.header
+	if columns.header != nil {
+		report.MissingColumns = append(report.MissingColumns, columns.missing()...)
+		report.UnknownColumns = append(report.UnknownColumns, columns.unknown()...)
+	}
+	if err != nil && len(report.MissingColumns) == 0 {
+		return report, err
+	}
+
+	for _, id := range ids {
+		if lines := idLines[id]; len(lines) > 1 {
+			report.DuplicateIDs = append(report.DuplicateIDs, DuplicateID{ID: id, Lines: lines})
+		}
+	}
+	for _, company := range companies {
+		report.Companies = append(report.Companies, *company)
+	}
+	sort.Slice(report.Companies, func(i, j int) bool {
+		if report.Companies[i].Rows != report.Companies[j].Rows {
+			return report.Companies[i].Rows > report.Companies[j].Rows
+		}
+		return report.Companies[i].CompanyID < report.Companies[j].CompanyID
+	})
+
+	return report, nil
+}
+
+// contentProblem explains why analytics cannot attribute a content string to a user, or returns ""
+func contentProblem(content EventContent) string {
+	switch {
+	case content.CompanyName == "" && content.Email == "":
+		return `expected "Action - Company user@email /path"`
+	case content.Email == "":
+		return "no user email"
+	case content.CompanyName == "":
+		return "no company name before the email"
+	}
+	return ""
+}
+
+// extend widens a range to include t
+func extend(r *TimeRange, t time.Time) *TimeRange {
+	if r == nil {
+		return &TimeRange{First: t, Last: t}
+	}
+	if t.Before(r.First) {
+		r.First = t
+	}
+	if t.After(r.Last) {
+		r.Last = t
+	}
+	return r
+}
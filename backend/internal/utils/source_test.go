@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+)
+
+const exportHeader = "id,created_at,company_id,type,content,attribute,updated_at,original_timestamp,value\n"
+
+// writeExport writes a CSV file into a test directory and returns its path
+func writeExport(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseEventsReadsColumnsByName(t *testing.T) {
+	created := time.Date(2025, 5, 31, 19, 17, 29, 0, time.UTC)
+	value := "2.5"
+	want := models.UsageEvent{
+		ID:                "1",
+		CreatedAt:         created,
+		CompanyID:         "company-1",
+		Type:              "Action",
+		Content:           "Login - Sample Company ann@sample.com /home",
+		Attribute:         "UserLogin",
+		UpdatedAt:         created.Add(time.Hour),
+		OriginalTimestamp: created.Add(-time.Second),
+		Value:             &value,
+	}
+	withoutOptional := want
+	withoutOptional.Attribute = ""
+	withoutOptional.UpdatedAt = created
+	withoutOptional.OriginalTimestamp = created
+	withoutOptional.Value = nil
+
+	tests := []struct {
+		name string
+		csv  string
+		want models.UsageEvent
+	}{
+		{
+			"export order",
+			exportHeader + "1,2025-05-31 19:17:29+00,company-1,Action,Login - Sample Company ann@sample.com /home,UserLogin,2025-05-31 20:17:29+00,2025-05-31 19:17:28+00,2.50\n",
+			want,
+		},
+		{
+			"other order",
+			"value,original_timestamp,updated_at,attribute,content,type,company_id,created_at,id\n" +
+				"2.50,2025-05-31 19:17:28+00,2025-05-31 20:17:29+00,UserLogin,Login - Sample Company ann@sample.com /home,Action,company-1,2025-05-31 19:17:29+00,1\n",
+			want,
+		},
+		{
+			"RFC 3339 timestamps",
+			exportHeader + "1,2025-05-31T19:17:29Z,company-1,Action,Login - Sample Company ann@sample.com /home,UserLogin,2025-05-31T20:17:29Z,2025-05-31T19:17:28Z,2.5\n",
+			want,
+		},
+		{
+			"null optional columns",
+			exportHeader + "1,2025-05-31 19:17:29+00,company-1,Action,Login - Sample Company ann@sample.com /home,,null,null,null\n",
+			withoutOptional,
+		},
+		{
+			"no optional columns",
+			"id,created_at,company_id,type,content\n1,2025-05-31 19:17:29+00,company-1,Action,Login - Sample Company ann@sample.com /home\n",
+			withoutOptional,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := NewCSVParser(writeExport(t, tt.csv)).ParseEvents()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+			got := events[0]
+			if got.ID != tt.want.ID || got.CompanyID != tt.want.CompanyID || got.Type != tt.want.Type ||
+				got.Content != tt.want.Content || got.Attribute != tt.want.Attribute {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			for _, column := range []struct {
+				name      string
+				got, want time.Time
+			}{
+				{"created_at", got.CreatedAt, tt.want.CreatedAt},
+				{"updated_at", got.UpdatedAt, tt.want.UpdatedAt},
+				{"original_timestamp", got.OriginalTimestamp, tt.want.OriginalTimestamp},
+			} {
+				if !column.got.Equal(column.want) {
+					t.Errorf("%s = %v, want %v", column.name, column.got, column.want)
+				}
+			}
+			if (got.Value == nil) != (tt.want.Value == nil) || (got.Value != nil && *got.Value != *tt.want.Value) {
+				t.Errorf("value = %v, want %v", got.Value, tt.want.Value)
+			}
+		})
+	}
+}
+
+func TestParseEventsFailsWithoutRequiredColumns(t *testing.T) {
+	path := writeExport(t, "id,created_at,type,content\n1,2025-05-31 19:17:29+00,Action,Login - Sample Company ann@sample.com /home\n")
+	if _, err := NewCSVParser(path).ParseEvents(); err == nil {
+		t.Fatal("expected an error for a file without company_id")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	const (
+		good     = "2025-05-31 19:17:29+00,company-1,Action,Login - Sample Company ann@sample.com /home,UserLogin,2025-05-31 19:17:29+00,null,null\n"
+		noUser   = "2025-05-31 19:17:29+00,company-1,Action,Login,UserLogin,2025-05-31 19:17:29+00,null,null\n"
+		backdate = "2025-05-31 19:17:29+00,company-1,Action,Login - Sample Company ann@sample.com /home,UserLogin,2025-05-30 19:17:29+00,null,null\n"
+	)
+
+	tests := []struct {
+		name string
+		csv  string
+		// check inspects the report
+		check  func(t *testing.T, report SourceReport)
+		failed bool
+		warned bool
+	}{
+		{
+			name: "clean file",
+			csv:  exportHeader + "1," + good + "2," + good,
+			check: func(t *testing.T, report SourceReport) {
+				if report.Rows != 2 || report.Accepted != 2 {
+					t.Errorf("rows %d, accepted %d; want 2 and 2", report.Rows, report.Accepted)
+				}
+				want := []CompanyRows{{CompanyID: "company-1", Name: "Sample Company", Rows: 2}}
+				if !slices.Equal(report.Companies, want) {
+					t.Errorf("companies = %+v, want %+v", report.Companies, want)
+				}
+			},
+		},
+		{
+			name: "rejected rows",
+			csv:  exportHeader + "1," + good + "2,yesterday,company-1,Action,x,y,null,null,null\n3,2025-05-31 19:17:29+00\n",
+			check: func(t *testing.T, report SourceReport) {
+				var lines []int
+				for _, issue := range report.Rejected {
+					lines = append(lines, issue.Line)
+				}
+				if report.Accepted != 1 || !slices.Equal(lines, []int{3, 4}) {
+					t.Errorf("accepted %d, rejected lines %v; want 1 and [3 4]", report.Accepted, lines)
+				}
+			},
+			failed: true,
+		},
+		{
+			name: "duplicate IDs",
+			csv:  exportHeader + "1," + good + "2," + good + "1," + good,
+			check: func(t *testing.T, report SourceReport) {
+				want := []DuplicateID{{ID: "1", Lines: []int{2, 4}}}
+				if len(report.DuplicateIDs) != 1 || report.DuplicateIDs[0].ID != want[0].ID || !slices.Equal(report.DuplicateIDs[0].Lines, want[0].Lines) {
+					t.Errorf("duplicates = %+v, want %+v", report.DuplicateIDs, want)
+				}
+			},
+			failed: true,
+		},
+		{
+			name: "missing column",
+			csv:  "id,created_at,type,content\n1,2025-05-31 19:17:29+00,Action,x\n",
+			check: func(t *testing.T, report SourceReport) {
+				if !slices.Equal(report.MissingColumns, []string{"company_id"}) {
+					t.Errorf("missing columns = %v, want [company_id]", report.MissingColumns)
+				}
+			},
+			failed: true,
+		},
+		{
+			name: "warnings",
+			csv:  "id,created_at,company_id,type,content,attribute,updated_at,original_timestamp,value,source\n" + "1," + good[:len(good)-1] + ",app\n2," + noUser[:len(noUser)-1] + ",app\n3," + backdate[:len(backdate)-1] + ",app\n",
+			check: func(t *testing.T, report SourceReport) {
+				if !slices.Equal(report.UnknownColumns, []string{"source"}) {
+					t.Errorf("unknown columns = %v, want [source]", report.UnknownColumns)
+				}
+				if len(report.UnparseableContent) != 1 || report.UnparseableContent[0].ID != "2" {
+					t.Errorf("unparseable content = %+v, want row 2", report.UnparseableContent)
+				}
+				if len(report.UpdatedBeforeCreated) != 1 || report.UpdatedBeforeCreated[0].ID != "3" {
+					t.Errorf("updated before created = %+v, want row 3", report.UpdatedBeforeCreated)
+				}
+			},
+			warned: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := NewCSVParser(writeExport(t, tt.csv)).DryRun()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, report)
+			if report.Failed() != tt.failed || report.Warned() != tt.warned {
+				t.Errorf("failed %v, warned %v; want %v and %v", report.Failed(), report.Warned(), tt.failed, tt.warned)
+			}
+		})
+	}
+}
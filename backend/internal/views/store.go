@@ -0,0 +1,257 @@
+package views
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// ErrViewNotFound is returned when a saved view does not exist
+var ErrViewNotFound = errors.New("view not found")
+
+// Supported view option values
+var (
+	granularities = []string{"", "day", "week", "month"}
+	compareModes  = []string{"", "previousPeriod", "previousYear"}
+	chartTypes    = []string{"", "line", "bar"}
+)
+
+// Store persists saved views to a JSON file
+type Store struct {
+	path  string
+	mu    sync.RWMutex
+	views map[string]models.SavedView
+}
+
+// NewStore loads saved views from path, starting empty if the file does not exist
+func NewStore(path string) (*Store, error) {
+	store := &Store{
+		path:  path,
+		views: make(map[string]models.SavedView),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read views file: %w", err)
+	}
+
+	var views []models.SavedView
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("failed to parse views file: %w", err)
+	}
+	for _, view := range views {
+		store.views[view.ID] = view
+	}
+
+	return store, nil
+}
+
+// List returns saved views ordered by name, optionally limited to one owner
+func (s *Store) List(owner string) []models.SavedView {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	views := []models.SavedView{}
+	for _, view := range s.views {
+		if owner == "" || view.Owner == owner {
+			views = append(views, view)
+		}
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Name != views[j].Name {
+			return views[i].Name < views[j].Name
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views
+}
+
+// Get returns a saved view by ID
+func (s *Store) Get(id string) (models.SavedView, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	view, ok := s.views[id]
+	if !ok {
+		return models.SavedView{}, ErrViewNotFound
+	}
+	return view, nil
+}
+
+// Create stores a new saved view owned by owner
+func (s *Store) Create(request models.SavedViewRequest, owner string) (models.SavedView, error) {
+	id, err := newViewID()
+	if err != nil {
+		return models.SavedView{}, err
+	}
+
+	now := time.Now().UTC()
+	view := models.SavedView{
+		ID:        id,
+		Name:      strings.TrimSpace(request.Name),
+		Owner:     owner,
+		Query:     request.Query,
+		Options:   request.Options,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.views[id] = view
+	if err := s.saveLocked(); err != nil {
+		delete(s.views, id)
+		return models.SavedView{}, err
+	}
+	return view, nil
+}
+
+// Update replaces the contents of an existing saved view, keeping its owner
+func (s *Store) Update(id string, request models.SavedViewRequest) (models.SavedView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.views[id]
+	if !ok {
+		return models.SavedView{}, ErrViewNotFound
+	}
+
+	view := previous
+	view.Name = strings.TrimSpace(request.Name)
+	view.Query = request.Query
+	view.Options = request.Options
+	view.UpdatedAt = time.Now().UTC()
+
+	s.views[id] = view
+	if err := s.saveLocked(); err != nil {
+		s.views[id] = previous
+		return models.SavedView{}, err
+	}
+	return view, nil
+}
+
+// Delete removes a saved view
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	view, ok := s.views[id]
+	if !ok {
+		return ErrViewNotFound
+	}
+
+	delete(s.views, id)
+	if err := s.saveLocked(); err != nil {
+		s.views[id] = view
+		return err
+	}
+	return nil
+}
+
+// saveLocked writes all views to disk atomically; caller must hold the write lock
+func (s *Store) saveLocked() error {
+	views := make([]models.SavedView, 0, len(s.views))
+	for _, view := range s.views {
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].CreatedAt.Before(views[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode views: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create views directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write views file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write views file: %w", err)
+	}
+	return nil
+}
+
+// Validate checks a view request, using companyExists to verify the referenced company
+func Validate(request models.SavedViewRequest, companyExists func(string) bool) error {
+	var problems []string
+
+	if strings.TrimSpace(request.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+
+	query := request.Query
+	if query.DateRange < 0 || query.DateRange > 365 {
+		problems = append(problems, "query.dateRange must be between 0 and 365")
+	}
+	for field, value := range map[string]string{"query.fromDate": query.FromDate, "query.toDate": query.ToDate} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be in YYYY-MM-DD format", field))
+		}
+	}
+	if query.CompanyID != "" && !companyExists(query.CompanyID) {
+		problems = append(problems, fmt.Sprintf("query.companyId %q does not match any company", query.CompanyID))
+	}
+	if query.GroupBy != "" && !contains(services.GroupDimensions, query.GroupBy) {
+		problems = append(problems, "query.groupBy must be plan, region, csmOwner or tag")
+	}
+
+	if !contains(granularities, request.Options.Granularity) {
+		problems = append(problems, "options.granularity must be day, week or month")
+	}
+	if !contains(compareModes, request.Options.Compare) {
+		problems = append(problems, "options.compare must be previousPeriod or previousYear")
+	}
+	if !contains(chartTypes, request.Options.ChartType) {
+		problems = append(problems, "options.chartType must be line or bar")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// newViewID generates a random view identifier
+func newViewID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate view id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+)
+
+// Client posts signed webhook payloads
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a webhook client with a request timeout
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send posts a payload signed with secret and returns the response status code
+// Any non-2xx response is treated as a failure
+func (c *Client) Send(ctx context.Context, endpointURL, secret string, payload models.WebhookPayload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, payload.ID)
+	req.Header.Set(HeaderEventType, payload.Type)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Drain so the connection can be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// ValidateEndpoint checks an endpoint definition
+func ValidateEndpoint(endpoint models.WebhookEndpoint) error {
+	var problems []string
+
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		problems = append(problems, "url must be an absolute http or https URL")
+	}
+	for _, eventType := range endpoint.Events {
+		if !slices.Contains(EventTypes, eventType) {
+			problems = append(problems, fmt.Sprintf("unknown event type %q, expected one of %s", eventType, strings.Join(EventTypes, ", ")))
+		}
+	}
+	if endpoint.InactiveDays < 0 || endpoint.InactiveDays > 365 {
+		problems = append(problems, fmt.Sprintf("inactiveDays must be between 0 (the default, %d days) and 365", defaultInactiveDays))
+	}
+	if endpoint.Privacy != "" && !slices.Contains(privacy.Modes, endpoint.Privacy) {
+		problems = append(problems, fmt.Sprintf("privacy must be one of %s", strings.Join(privacy.Modes, ", ")))
+	}
+	for _, milestone := range endpoint.Milestones {
+		if milestone <= 0 {
+			problems = append(problems, "milestones must be positive event counts")
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
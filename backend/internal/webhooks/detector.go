@@ -0,0 +1,141 @@
+package webhooks
+
+import (
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/utils"
+)
+
+// Notification types
+const (
+	EventCompanyInactive  = "company.inactive"
+	EventCompanyFirstSeen = "company.first_seen"
+	EventUserFirstSeen    = "user.first_seen"
+	EventCompanyMilestone = "company.milestone"
+	EventTest             = "webhook.test"
+)
+
+// EventTypes lists the notification types an endpoint can subscribe to
+var EventTypes = []string{
+	EventCompanyInactive,
+	EventCompanyFirstSeen,
+	EventUserFirstSeen,
+	EventCompanyMilestone,
+}
+
+// Defaults applied when an endpoint does not configure its own values
+const defaultInactiveDays = 7
+
+var defaultMilestones = []int{100, 500, 1000, 5000, 10000}
+
+// companyState tracks what has been seen of a company so far
+type companyState struct {
+	events   int
+	lastSeen time.Time
+}
+
+// userSeen records a user observed for the first time
+type userSeen struct {
+	email     string
+	companyID string
+}
+
+// countChange records a company's event count before and after a batch
+type countChange struct {
+	before int
+	after  int
+}
+
+// batchChanges describes what an ingested batch changed
+type batchChanges struct {
+	newCompanies []string
+	newUsers     []userSeen
+	counts       map[string]countChange
+	active       map[string]bool
+}
+
+// detector keeps running per-company and per-user state for the ingested stream
+type detector struct {
+	companies map[string]*companyState
+	users     map[string]bool
+	// clock is the time of the most recent event, so inactivity is measured
+	// against the data rather than the wall clock
+	clock time.Time
+}
+
+// newDetector builds the baseline from events that are already loaded
+// so the initial dataset does not trigger first-seen notifications
+func newDetector(events []models.UsageEvent) *detector {
+	d := &detector{
+		companies: make(map[string]*companyState),
+		users:     make(map[string]bool),
+	}
+	d.observe(events)
+	return d
+}
+
+// observe folds a batch of events into the state and reports what changed
+func (d *detector) observe(events []models.UsageEvent) batchChanges {
+	changes := batchChanges{
+		counts: make(map[string]countChange),
+		active: make(map[string]bool),
+	}
+
+	for _, event := range events {
+		content := utils.ParseEventContent(event.Content)
+
+		company, known := d.companies[event.CompanyID]
+		if !known {
+			company = &companyState{}
+			d.companies[event.CompanyID] = company
+			changes.newCompanies = append(changes.newCompanies, event.CompanyID)
+		}
+
+		change, touched := changes.counts[event.CompanyID]
+		if !touched {
+			change.before = company.events
+		}
+		company.events++
+		change.after = company.events
+		changes.counts[event.CompanyID] = change
+		changes.active[event.CompanyID] = true
+
+		if event.CreatedAt.After(company.lastSeen) {
+			company.lastSeen = event.CreatedAt
+		}
+		if event.CreatedAt.After(d.clock) {
+			d.clock = event.CreatedAt
+		}
+
+		if content.Email != "" && !d.users[content.Email] {
+			d.users[content.Email] = true
+			changes.newUsers = append(changes.newUsers, userSeen{email: content.Email, companyID: event.CompanyID})
+		}
+	}
+
+	return changes
+}
+
+// inactiveCompanies returns the companies with no events in the last days, measured from the clock
+func (d *detector) inactiveCompanies(days int) []string {
+	cutoff := d.clock.AddDate(0, 0, -days)
+
+	var inactive []string
+	for id, company := range d.companies {
+		if !company.lastSeen.After(cutoff) {
+			inactive = append(inactive, id)
+		}
+	}
+	return inactive
+}
+
+// crossedMilestones returns the milestones passed by a count change
+func crossedMilestones(change countChange, milestones []int) []int {
+	var crossed []int
+	for _, milestone := range milestones {
+		if change.before < milestone && change.after >= milestone {
+			crossed = append(crossed, milestone)
+		}
+	}
+	return crossed
+}
@@ -0,0 +1,597 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// Delivery tuning
+const (
+	maxAttempts    = 6
+	baseRetryDelay = 2 * time.Second
+	maxDeliveries  = 500
+	queueSize      = 1000
+	workers        = 4
+)
+
+// ErrEndpointNotFound is returned when a webhook endpoint does not exist
+var ErrEndpointNotFound = errors.New("webhook endpoint not found")
+
+// managerState is the on-disk representation of the manager
+type managerState struct {
+	Endpoints []models.WebhookEndpoint `json:"endpoints"`
+	// Inactive maps endpoint ID to the companies already reported inactive
+	Inactive   map[string][]string      `json:"inactive"`
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// job is one pending delivery attempt
+type job struct {
+	endpointID string
+	deliveryID string
+	payload    models.WebhookPayload
+	attempt    int
+}
+
+// Manager stores webhook endpoints, turns ingested events into notifications
+// and delivers them with retries
+type Manager struct {
+	analyticsService *services.AnalyticsService
+	path             string
+	client           *Client
+	queue            chan job
+	// pending counts queued deliveries not yet attempted
+	pending atomic.Int64
+
+	mu         sync.Mutex
+	ctx        context.Context
+	endpoints  map[string]models.WebhookEndpoint
+	inactive   map[string]map[string]bool
+	deliveries []models.WebhookDelivery
+	detector   *detector
+}
+
+// NewManager loads endpoints and delivery logs from path, starting empty if the file does not exist
+// The events already held by the analytics service form the baseline for first-seen detection
+func NewManager(analyticsService *services.AnalyticsService, path string) (*Manager, error) {
+	manager := &Manager{
+		analyticsService: analyticsService,
+		path:             path,
+		client:           NewClient(),
+		queue:            make(chan job, queueSize),
+		ctx:              context.Background(),
+		endpoints:        make(map[string]models.WebhookEndpoint),
+		inactive:         make(map[string]map[string]bool),
+		detector:         newDetector(analyticsService.Events()),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return manager, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks file: %w", err)
+	}
+
+	var state managerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file: %w", err)
+	}
+	for _, endpoint := range state.Endpoints {
+		manager.endpoints[endpoint.ID] = endpoint
+	}
+	for endpointID, companies := range state.Inactive {
+		manager.inactive[endpointID] = make(map[string]bool, len(companies))
+		for _, companyID := range companies {
+			manager.inactive[endpointID][companyID] = true
+		}
+	}
+	manager.deliveries = state.Deliveries
+
+	return manager, nil
+}
+
+// Start runs the delivery workers until ctx is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-m.queue:
+					m.attempt(ctx, j)
+					m.pending.Add(-1)
+				}
+			}
+		}()
+	}
+}
+
+// Drain waits until every queued delivery has been attempted, or until ctx is done
+// Retries still waiting out their backoff are not waited for; the delivery log records when they were due
+func (m *Manager) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for m.pending.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d webhook deliveries still queued: %w", m.pending.Load(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// HandlePurge rebuilds the detection baseline after events are removed, so erased
+// users and counts are forgotten; it is registered as a purge subscriber
+func (m *Manager) HandlePurge(removed []models.UsageEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.detector = newDetector(m.analyticsService.Events())
+}
+
+// HandleIngest turns a batch of newly ingested events into notifications
+// It is registered as an ingest subscriber and only queues deliveries; state is
+// saved only when the batch changed which companies are reported inactive
+func (m *Manager) HandleIngest(events []models.UsageEvent) {
+	m.mu.Lock()
+	changes := m.detector.observe(events)
+
+	var jobs []job
+	changed := false
+	for _, endpoint := range m.sortedEndpointsLocked() {
+		if endpoint.Disabled {
+			continue
+		}
+		payloads, endpointChanged := m.payloadsLocked(endpoint, changes)
+		changed = changed || endpointChanged
+		for _, payload := range payloads {
+			jobs = append(jobs, job{
+				endpointID: endpoint.ID,
+				deliveryID: newID(),
+				payload:    payload,
+				attempt:    1,
+			})
+		}
+	}
+	if changed {
+		if err := m.saveLocked(); err != nil {
+			log.Printf("Failed to save webhook state: %v", err)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, j := range jobs {
+		m.enqueue(j)
+	}
+}
+
+// SendTest queues a test notification for an endpoint, even when it is disabled
+func (m *Manager) SendTest(id string) (models.WebhookPayload, error) {
+	if _, err := m.Endpoint(id); err != nil {
+		return models.WebhookPayload{}, err
+	}
+
+	payload := newPayload(EventTest, models.WebhookEventData{})
+	m.enqueue(job{endpointID: id, deliveryID: newID(), payload: payload, attempt: 1})
+	return payload, nil
+}
+
+// payloadsLocked builds the notifications a batch produces for one endpoint, and reports
+// whether it changed the saved inactivity state; caller must hold mu
+func (m *Manager) payloadsLocked(endpoint models.WebhookEndpoint, changes batchChanges) ([]models.WebhookPayload, bool) {
+	var payloads []models.WebhookPayload
+
+	if subscribes(endpoint, EventCompanyFirstSeen) {
+		for _, companyID := range changes.newCompanies {
+			payloads = append(payloads, newPayload(EventCompanyFirstSeen, models.WebhookEventData{
+				CompanyID:   companyID,
+				CompanyName: m.analyticsService.CompanyName(companyID),
+			}))
+		}
+	}
+
+	if subscribes(endpoint, EventUserFirstSeen) {
+		for _, user := range changes.newUsers {
+			userID, email := m.analyticsService.RedactUser(user.email, endpointPrivacy(endpoint))
+			payloads = append(payloads, newPayload(EventUserFirstSeen, models.WebhookEventData{
+				CompanyID:   user.companyID,
+				CompanyName: m.analyticsService.CompanyName(user.companyID),
+				UserID:      userID,
+				Email:       email,
+			}))
+		}
+	}
+
+	if subscribes(endpoint, EventCompanyMilestone) {
+		milestones := endpoint.Milestones
+		if len(milestones) == 0 {
+			milestones = defaultMilestones
+		}
+		for _, companyID := range sortedKeys(changes.counts) {
+			change := changes.counts[companyID]
+			for _, milestone := range crossedMilestones(change, milestones) {
+				payloads = append(payloads, newPayload(EventCompanyMilestone, models.WebhookEventData{
+					CompanyID:   companyID,
+					CompanyName: m.analyticsService.CompanyName(companyID),
+					Milestone:   milestone,
+					EventCount:  change.after,
+				}))
+			}
+		}
+	}
+
+	changed := false
+	if subscribes(endpoint, EventCompanyInactive) {
+		var inactive []models.WebhookPayload
+		inactive, changed = m.inactivityPayloadsLocked(endpoint, changes)
+		payloads = append(payloads, inactive...)
+	}
+
+	return payloads, changed
+}
+
+// inactivityPayloadsLocked reports companies that newly went quiet, once per inactive spell,
+// and whether the set of reported companies changed
+func (m *Manager) inactivityPayloadsLocked(endpoint models.WebhookEndpoint, changes batchChanges) ([]models.WebhookPayload, bool) {
+	days := endpoint.InactiveDays
+	if days <= 0 {
+		days = defaultInactiveDays
+	}
+
+	reported := m.inactive[endpoint.ID]
+	if reported == nil {
+		reported = make(map[string]bool)
+		m.inactive[endpoint.ID] = reported
+	}
+
+	// Activity in this batch ends an inactive spell
+	changed := false
+	for companyID := range changes.active {
+		if reported[companyID] {
+			delete(reported, companyID)
+			changed = true
+		}
+	}
+
+	inactive := m.detector.inactiveCompanies(days)
+	sort.Strings(inactive)
+
+	var payloads []models.WebhookPayload
+	for _, companyID := range inactive {
+		if reported[companyID] {
+			continue
+		}
+		reported[companyID] = true
+		changed = true
+
+		lastSeen := m.detector.companies[companyID].lastSeen
+		payloads = append(payloads, newPayload(EventCompanyInactive, models.WebhookEventData{
+			CompanyID:    companyID,
+			CompanyName:  m.analyticsService.CompanyName(companyID),
+			InactiveDays: int(m.detector.clock.Sub(lastSeen).Hours() / 24),
+			LastSeen:     &lastSeen,
+		}))
+	}
+	return payloads, changed
+}
+
+// enqueue hands a job to the workers without blocking the caller
+func (m *Manager) enqueue(j job) {
+	m.pending.Add(1)
+	select {
+	case m.queue <- j:
+	default:
+		m.pending.Add(-1)
+		log.Printf("Webhook queue full, dropping %s delivery %s", j.payload.Type, j.deliveryID)
+	}
+}
+
+// attempt performs one delivery and schedules a retry with exponential backoff on failure
+func (m *Manager) attempt(ctx context.Context, j job) {
+	endpoint, err := m.Endpoint(j.endpointID)
+	if err != nil {
+		return // Endpoint was deleted while the delivery was pending
+	}
+	if endpoint.Disabled && j.payload.Type != EventTest {
+		return
+	}
+
+	started := time.Now()
+	statusCode, err := m.client.Send(ctx, endpoint.URL, m.secret(endpoint.ID), j.payload)
+
+	record := models.WebhookDelivery{
+		ID:         j.deliveryID,
+		EndpointID: endpoint.ID,
+		EventID:    j.payload.ID,
+		EventType:  j.payload.Type,
+		Attempt:    j.attempt,
+		StatusCode: statusCode,
+		Succeeded:  err == nil,
+		DurationMs: time.Since(started).Milliseconds(),
+		At:         started.UTC(),
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+		if j.attempt < maxAttempts {
+			delay := baseRetryDelay << (j.attempt - 1)
+			next := started.Add(delay).UTC()
+			record.NextRetryAt = &next
+
+			retry := j
+			retry.attempt++
+			time.AfterFunc(delay, func() {
+				if m.context().Err() == nil {
+					m.enqueue(retry)
+				}
+			})
+		}
+		log.Printf("Webhook %s delivery %s attempt %d failed: %v", j.payload.Type, j.deliveryID, j.attempt, err)
+	}
+
+	m.mu.Lock()
+	m.deliveries = append(m.deliveries, record)
+	if len(m.deliveries) > maxDeliveries {
+		m.deliveries = m.deliveries[len(m.deliveries)-maxDeliveries:]
+	}
+	if err := m.saveLocked(); err != nil {
+		log.Printf("Failed to save webhook state: %v", err)
+	}
+	m.mu.Unlock()
+}
+
+// Endpoints returns every endpoint ordered by URL, without secrets
+func (m *Manager) Endpoints() []models.WebhookEndpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoints := m.sortedEndpointsLocked()
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints
+}
+
+// Endpoint returns an endpoint by ID, without its secret
+func (m *Manager) Endpoint(id string) (models.WebhookEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoint, ok := m.endpoints[id]
+	if !ok {
+		return models.WebhookEndpoint{}, ErrEndpointNotFound
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// CreateEndpoint stores a new endpoint, generating a signing secret when none is given
+// The returned endpoint is the only place the secret is shown
+func (m *Manager) CreateEndpoint(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+	now := time.Now().UTC()
+	endpoint.ID = newID()
+	endpoint.CreatedAt = now
+	endpoint.UpdatedAt = now
+	if endpoint.Secret == "" {
+		endpoint.Secret = newSecret()
+	}
+	normalizeEndpoint(&endpoint)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoints[endpoint.ID] = endpoint
+	if err := m.saveLocked(); err != nil {
+		delete(m.endpoints, endpoint.ID)
+		return models.WebhookEndpoint{}, err
+	}
+	return endpoint, nil
+}
+
+// UpdateEndpoint replaces an endpoint's settings, keeping its secret unless a new one is given
+func (m *Manager) UpdateEndpoint(id string, endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous, ok := m.endpoints[id]
+	if !ok {
+		return models.WebhookEndpoint{}, ErrEndpointNotFound
+	}
+
+	endpoint.ID = id
+	endpoint.CreatedAt = previous.CreatedAt
+	endpoint.UpdatedAt = time.Now().UTC()
+	if endpoint.Secret == "" {
+		endpoint.Secret = previous.Secret
+	}
+	normalizeEndpoint(&endpoint)
+
+	m.endpoints[id] = endpoint
+	if err := m.saveLocked(); err != nil {
+		m.endpoints[id] = previous
+		return models.WebhookEndpoint{}, err
+	}
+
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes an endpoint and its inactivity state; delivery logs are kept
+func (m *Manager) DeleteEndpoint(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoint, ok := m.endpoints[id]
+	if !ok {
+		return ErrEndpointNotFound
+	}
+
+	reported := m.inactive[id]
+	delete(m.endpoints, id)
+	delete(m.inactive, id)
+	if err := m.saveLocked(); err != nil {
+		m.endpoints[id] = endpoint
+		if reported != nil {
+			m.inactive[id] = reported
+		}
+		return err
+	}
+	return nil
+}
+
+// Deliveries returns delivery attempts for an endpoint newest first
+func (m *Manager) Deliveries(endpointID string, limit int) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.endpoints[endpointID]; !ok {
+		return nil, ErrEndpointNotFound
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(m.deliveries) - 1; i >= 0 && (limit <= 0 || len(deliveries) < limit); i-- {
+		if m.deliveries[i].EndpointID == endpointID {
+			deliveries = append(deliveries, m.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+// secret returns the signing secret for an endpoint
+func (m *Manager) secret(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.endpoints[id].Secret
+}
+
+// context returns the context the workers were started with
+func (m *Manager) context() context.Context {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ctx
+}
+
+// sortedEndpointsLocked returns endpoints ordered by URL; caller must hold mu
+func (m *Manager) sortedEndpointsLocked() []models.WebhookEndpoint {
+	endpoints := make([]models.WebhookEndpoint, 0, len(m.endpoints))
+	for _, endpoint := range m.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].URL != endpoints[j].URL {
+			return endpoints[i].URL < endpoints[j].URL
+		}
+		return endpoints[i].ID < endpoints[j].ID
+	})
+	return endpoints
+}
+
+// saveLocked writes endpoints, inactivity state and delivery logs to disk atomically; caller must hold mu
+func (m *Manager) saveLocked() error {
+	state := managerState{
+		Endpoints:  m.sortedEndpointsLocked(),
+		Inactive:   make(map[string][]string, len(m.inactive)),
+		Deliveries: m.deliveries,
+	}
+	for endpointID, reported := range m.inactive {
+		state.Inactive[endpointID] = sortedKeys(reported)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode webhooks: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create webhooks directory: %w", err)
+	}
+
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write webhooks file: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to write webhooks file: %w", err)
+	}
+	return nil
+}
+
+// normalizeEndpoint replaces nil lists so they encode as empty arrays and fills in the privacy mode
+func normalizeEndpoint(endpoint *models.WebhookEndpoint) {
+	endpoint.Privacy = endpointPrivacy(*endpoint)
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+	if endpoint.Milestones == nil {
+		endpoint.Milestones = []int{}
+	}
+}
+
+// endpointPrivacy returns how an endpoint's payloads present emails; endpoints saved
+// before the setting existed get pseudonyms, so only an explicit "none" sends raw emails
+func endpointPrivacy(endpoint models.WebhookEndpoint) string {
+	if endpoint.Privacy == "" {
+		return privacy.ModeHash
+	}
+	return endpoint.Privacy
+}
+
+// subscribes reports whether an endpoint wants a notification type
+func subscribes(endpoint models.WebhookEndpoint, eventType string) bool {
+	return len(endpoint.Events) == 0 || slices.Contains(endpoint.Events, eventType)
+}
+
+// newPayload builds a notification with a fresh ID
+func newPayload(eventType string, data models.WebhookEventData) models.WebhookPayload {
+	return models.WebhookPayload{
+		ID:        newID(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// sortedKeys returns the keys of a map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newID generates a random identifier for endpoints, payloads and deliveries
+func newID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf) // crypto/rand.Read never fails since Go 1.24
+	return hex.EncodeToString(buf)
+}
+
+// newSecret generates a random signing secret
+func newSecret() string {
+	buf := make([]byte, 24)
+	rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"usage-analytics-dashboard/internal/models"
+	"usage-analytics-dashboard/internal/privacy"
+	"usage-analytics-dashboard/internal/services"
+)
+
+// webhookEvent is an event of a company's user on a day of June 2025
+func webhookEvent(id, companyID, user string, day int) models.UsageEvent {
+	created := time.Date(2025, 6, day, 9, 0, 0, 0, time.UTC)
+	return models.UsageEvent{
+		ID:                id,
+		CreatedAt:         created,
+		CompanyID:         companyID,
+		Type:              "Action",
+		Content:           "Login - Company " + companyID + " " + user + " /home",
+		UpdatedAt:         created,
+		OriginalTimestamp: created,
+	}
+}
+
+func TestHandleIngestSavesOnlyChangedState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	service := services.NewAnalyticsService([]models.UsageEvent{
+		webhookEvent("1", "alpha", "ann@alpha.io", 1),
+		webhookEvent("2", "beta", "bob@beta.io", 1),
+	}, nil, nil)
+	manager, err := NewManager(service, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CreateEndpoint(models.WebhookEndpoint{URL: "http://127.0.0.1:1/hook", Events: []string{EventCompanyInactive}}); err != nil {
+		t.Fatal(err)
+	}
+	saved := func() bool {
+		_, err := os.Stat(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// No company has been quiet for 7 days yet, so nothing changes
+	manager.HandleIngest([]models.UsageEvent{webhookEvent("3", "alpha", "ann@alpha.io", 3)})
+	if saved() {
+		t.Error("state was saved after a batch that changed nothing")
+	}
+
+	// beta has now been quiet for 9 days and is reported, which must survive a restart
+	manager.HandleIngest([]models.UsageEvent{webhookEvent("4", "alpha", "ann@alpha.io", 10)})
+	if !saved() {
+		t.Fatal("state was not saved after beta was reported inactive")
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// beta is already reported, so another quiet batch changes nothing
+	manager.HandleIngest([]models.UsageEvent{webhookEvent("5", "alpha", "ann@alpha.io", 11)})
+	if saved() {
+		t.Error("state was saved although beta was already reported")
+	}
+}
+
+func TestUserFirstSeenFollowsEndpointPrivacy(t *testing.T) {
+	redactor := privacy.NewRedactor([]byte("0123456789abcdef"))
+	service := services.NewAnalyticsService(nil, redactor, nil)
+	manager, err := NewManager(service, filepath.Join(t.TempDir(), "webhooks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := batchChanges{newUsers: []userSeen{{email: "ann@alpha.io", companyID: "alpha"}}}
+	pseudonym := redactor.Pseudonym("ann@alpha.io")
+
+	tests := []struct {
+		privacy string
+		userID  string
+		email   string
+	}{
+		{"", pseudonym, pseudonym},
+		{privacy.ModeHash, pseudonym, pseudonym},
+		{privacy.ModeMask, pseudonym, redactor.Email("ann@alpha.io", privacy.ModeMask)},
+		{privacy.ModeNone, "", "ann@alpha.io"},
+	}
+	for _, tt := range tests {
+		endpoint, err := manager.CreateEndpoint(models.WebhookEndpoint{URL: "http://127.0.0.1:1/hook", Events: []string{EventUserFirstSeen}, Privacy: tt.privacy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloads, _ := manager.payloadsLocked(endpoint, changes)
+		if len(payloads) != 1 {
+			t.Fatalf("privacy %q: got %d payloads, want 1", tt.privacy, len(payloads))
+		}
+		if data := payloads[0].Data; data.UserID != tt.userID || data.Email != tt.email {
+			t.Errorf("privacy %q: got userId %q email %q, want %q %q", tt.privacy, data.UserID, data.Email, tt.userID, tt.email)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSignFormat(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"webhook.test"}`)
+	signature := Sign("whsec_test", 1717243200, body)
+
+	if !regexp.MustCompile(`^sha256=[0-9a-f]{64}$`).MatchString(signature) {
+		t.Fatalf("signature = %q, want sha256=<64 hex digits>", signature)
+	}
+
+	// Receivers compute HMAC-SHA256 of "<timestamp>.<body>" with the endpoint secret
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1717243200." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	signature := Sign("whsec_test", 1717243200, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		signature string
+		want      bool
+	}{
+		{"matching", "whsec_test", 1717243200, `{"id":"evt_1"}`, signature, true},
+		{"other secret", "whsec_other", 1717243200, `{"id":"evt_1"}`, signature, false},
+		{"replayed timestamp", "whsec_test", 1717243201, `{"id":"evt_1"}`, signature, false},
+		{"changed body", "whsec_test", 1717243200, `{"id":"evt_2"}`, signature, false},
+		{"no prefix", "whsec_test", 1717243200, `{"id":"evt_1"}`, signature[len("sha256="):], false},
+		{"uppercase hex", "whsec_test", 1717243200, `{"id":"evt_1"}`, "sha256=" + strings.ToUpper(signature[len("sha256="):]), false},
+		{"empty", "whsec_test", 1717243200, `{"id":"evt_1"}`, "", false},
+	}
+	for _, tt := range tests {
+		if got := Verify(tt.secret, tt.timestamp, []byte(tt.body), tt.signature); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
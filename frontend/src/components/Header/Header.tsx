@@ -1,21 +1,40 @@
-import { BarChart3 } from "lucide-react";
+import { useNavigate } from "react-router-dom";
+import { BarChart3, LogOut } from "lucide-react";
+import { Button } from "../../shared/ui/button";
+import { logout } from "../../services/api";
 
 const Header = () => {
+  const navigate = useNavigate();
+
+  const handleSignOut = async () => {
+    try {
+      await logout();
+    } finally {
+      navigate("/login", { replace: true });
+    }
+  };
+
   return (
     <div className="fixed top-0 left-0 right-0 z-50 bg-white/90 backdrop-blur-xl border-b border-gray-200/60 shadow-xl">
       <div className="container mx-auto px-6 py-4">
-        <div className="flex items-center space-x-4">
-          <div className="flex items-center justify-center w-12 h-12 bg-gradient-to-br from-blue-600 via-indigo-600 to-purple-600 rounded-xl shadow-lg transform hover:scale-105 transition-all duration-300">
-            <BarChart3 className="h-6 w-6 text-white" />
-          </div>
-          <div className="flex flex-col">
-            <h1 className="text-2xl font-bold bg-gradient-to-r from-gray-800 via-blue-700 to-indigo-700 bg-clip-text text-transparent">
-              Usage Analytics Dashboard
-            </h1>
-            <p className="text-sm text-gray-500 font-medium">
-              Real-time insights and analytics
-            </p>
+        <div className="flex items-center justify-between">
+          <div className="flex items-center space-x-4">
+            <div className="flex items-center justify-center w-12 h-12 bg-gradient-to-br from-blue-600 via-indigo-600 to-purple-600 rounded-xl shadow-lg transform hover:scale-105 transition-all duration-300">
+              <BarChart3 className="h-6 w-6 text-white" />
+            </div>
+            <div className="flex flex-col">
+              <h1 className="text-2xl font-bold bg-gradient-to-r from-gray-800 via-blue-700 to-indigo-700 bg-clip-text text-transparent">
+                Usage Analytics Dashboard
+              </h1>
+              <p className="text-sm text-gray-500 font-medium">
+                Real-time insights and analytics
+              </p>
+            </div>
           </div>
+          <Button variant="ghost" size="sm" onClick={handleSignOut}>
+            <LogOut className="h-4 w-4 mr-2" />
+            Sign out
+          </Button>
         </div>
       </div>
     </div>
@@ -0,0 +1,95 @@
+import React, { useState } from "react";
+import { useLocation, useNavigate } from "react-router-dom";
+import { BarChart3 } from "lucide-react";
+import { Card, CardContent } from "../../shared/ui/card";
+import { Input } from "../../shared/ui/input";
+import { Button } from "../../shared/ui/button";
+import { login } from "../../services/api";
+import { defaultRoute } from "../../routes";
+
+// Login view - signs in with username and password; the session lives in an HttpOnly cookie
+const Login: React.FC = () => {
+  const navigate = useNavigate();
+  const location = useLocation();
+  const [username, setUsername] = useState("");
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState<string | null>(null);
+  const [submitting, setSubmitting] = useState(false);
+
+  // Return to the page that sent the user here
+  const from =
+    (location.state as { from?: string } | null)?.from || defaultRoute;
+
+  const handleSubmit = async (event: React.FormEvent<HTMLFormElement>) => {
+    event.preventDefault();
+    try {
+      setSubmitting(true);
+      setError(null);
+      await login(username, password);
+      navigate(from, { replace: true });
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to sign in");
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-gray-50 via-blue-50 to-indigo-50 flex items-center justify-center p-6">
+      <Card className="w-full max-w-sm shadow-xl">
+        <CardContent className="p-8">
+          <div className="flex items-center space-x-3 mb-6">
+            <div className="flex items-center justify-center w-10 h-10 bg-gradient-to-br from-blue-600 via-indigo-600 to-purple-600 rounded-xl shadow-lg">
+              <BarChart3 className="h-5 w-5 text-white" />
+            </div>
+            <h1 className="text-xl font-bold text-gray-800">
+              Usage Analytics Dashboard
+            </h1>
+          </div>
+
+          <form onSubmit={handleSubmit} className="space-y-4">
+            <div className="space-y-1">
+              <label
+                htmlFor="username"
+                className="text-sm font-medium text-gray-700"
+              >
+                Username
+              </label>
+              <Input
+                id="username"
+                autoComplete="username"
+                value={username}
+                onChange={(e) => setUsername(e.target.value)}
+                required
+              />
+            </div>
+            <div className="space-y-1">
+              <label
+                htmlFor="password"
+                className="text-sm font-medium text-gray-700"
+              >
+                Password
+              </label>
+              <Input
+                id="password"
+                type="password"
+                autoComplete="current-password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                required
+              />
+            </div>
+
+            {error && <p className="text-sm text-red-600">{error}</p>}
+
+            <Button type="submit" className="w-full" disabled={submitting}>
+              {submitting ? "Signing in..." : "Sign in"}
+            </Button>
+          </form>
+        </CardContent>
+      </Card>
+    </div>
+  );
+};
+
+export default Login;
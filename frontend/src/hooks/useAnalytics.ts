@@ -1,6 +1,7 @@
 import { useState, useEffect, useRef } from "react";
 import { AnalyticsResponse, QueryParams } from "../types/analytics";
-import { getAnalytics } from "../services/api";
+import { useNavigate } from "react-router-dom";
+import { getAnalytics, UnauthorizedError } from "../services/api";
 
 export const initialFilterValues: QueryParams = {
   search: "",
@@ -11,6 +12,7 @@ export const initialFilterValues: QueryParams = {
 };
 
 export const useAnalytics = () => {
+  const navigate = useNavigate();
   const [analytics, setAnalytics] = useState<AnalyticsResponse | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
@@ -38,6 +40,11 @@ export const useAnalytics = () => {
       const data = await getAnalytics(apiParams);
       setAnalytics(data);
     } catch (err) {
+      // An expired session goes back to the login view
+      if (err instanceof UnauthorizedError) {
+        navigate("/login", { replace: true });
+        return;
+      }
       setError(
         err instanceof Error ? err.message : "Failed to fetch analytics"
       );
@@ -2,6 +2,8 @@ import React from "react";
 import { Routes, Route, Navigate } from "react-router-dom";
 import { defaultRoute } from ".";
 import DashboardWrapper from "@/components/Dashboard/DashboardWrapper";
+import Login from "@/components/Login/Login";
+import RequireSession from "./RequireSession";
 
 const AppRoutes: React.FC = () => {
   return (
@@ -9,8 +11,18 @@ const AppRoutes: React.FC = () => {
       {/* Default route redirects to dashboard */}
       <Route path="/" element={<Navigate to={defaultRoute} replace />} />
 
-      {/* Dashboard route */}
-      <Route path={defaultRoute} element={<DashboardWrapper />} />
+      {/* Login route */}
+      <Route path="/login" element={<Login />} />
+
+      {/* Dashboard route, for signed-in users only */}
+      <Route
+        path={defaultRoute}
+        element={
+          <RequireSession>
+            <DashboardWrapper />
+          </RequireSession>
+        }
+      />
 
       {/* Catch all other routes and redirect to dashboard */}
       <Route path="*" element={<Navigate to={defaultRoute} replace />} />
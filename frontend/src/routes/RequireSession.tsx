@@ -0,0 +1,33 @@
+import React, { useEffect, useState } from "react";
+import { Navigate, useLocation } from "react-router-dom";
+import LoadingState from "../shared/components/LoadingState";
+import { getCurrentUser } from "../services/api";
+
+interface RequireSessionProps {
+  children: React.ReactElement;
+}
+
+// Renders its children for signed-in callers and sends everyone else to the login view
+const RequireSession: React.FC<RequireSessionProps> = ({ children }) => {
+  const location = useLocation();
+  const [status, setStatus] = useState<"checking" | "signedIn" | "signedOut">(
+    "checking"
+  );
+
+  useEffect(() => {
+    getCurrentUser()
+      .then((principal) => setStatus(principal ? "signedIn" : "signedOut"))
+      // Let the page report other failures, such as the server being down
+      .catch(() => setStatus("signedIn"));
+  }, []);
+
+  if (status === "checking") {
+    return <LoadingState height="h-screen" className="border-0 shadow-none" />;
+  }
+  if (status === "signedOut") {
+    return <Navigate to="/login" replace state={{ from: location.pathname }} />;
+  }
+  return children;
+};
+
+export default RequireSession;
@@ -1,4 +1,5 @@
 import { AnalyticsResponse, QueryParams } from "../types/analytics";
+import { Principal } from "../types/auth";
 
 export interface ExportOptions {
   format: "csv" | "json" | "xlsx";
@@ -10,6 +11,68 @@ export interface ExportOptions {
 const API_BASE_URL =
   import.meta.env.VITE_API_BASE_URL || "http://localhost:8080/api";
 
+// Requests authenticate with the HttpOnly session cookie set by /auth/login,
+// so no credential is ever held in the bundle or in script-readable storage
+const requestInit = (): RequestInit => ({
+  credentials: "include",
+});
+
+// Thrown when the session is missing or expired, so callers can send the user to the login view
+export class UnauthorizedError extends Error {
+  constructor() {
+    super("Your session has expired, please sign in again");
+    this.name = "UnauthorizedError";
+  }
+}
+
+// Sign in; the server answers with the session cookie
+export const login = async (
+  username: string,
+  password: string
+): Promise<Principal> => {
+  const response = await fetch(`${API_BASE_URL}/auth/login`, {
+    ...requestInit(),
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ username, password }),
+  });
+
+  if (response.status === 401) {
+    throw new Error("Unknown username or wrong password");
+  }
+  if (response.status === 404) {
+    throw new Error("Dashboard login is not enabled on the server");
+  }
+  if (!response.ok) {
+    throw new Error(`HTTP error! status: ${response.status}`);
+  }
+
+  // The token in the body is ignored; the cookie carries the session
+  const { principal } = await response.json();
+  return principal;
+};
+
+// Sign out by clearing the session cookie
+export const logout = async (): Promise<void> => {
+  await fetch(`${API_BASE_URL}/auth/logout`, {
+    ...requestInit(),
+    method: "POST",
+  });
+};
+
+// The signed-in caller, or null without a valid session
+export const getCurrentUser = async (): Promise<Principal | null> => {
+  const response = await fetch(`${API_BASE_URL}/auth/me`, requestInit());
+
+  if (response.status === 401) {
+    return null;
+  }
+  if (!response.ok) {
+    throw new Error(`HTTP error! status: ${response.status}`);
+  }
+  return await response.json();
+};
+
 // Basic analytics API
 export const getAnalytics = async (
   params: QueryParams
@@ -48,8 +111,14 @@ export const getAnalytics = async (
       }
     }
 
-    const response = await fetch(`${API_BASE_URL}/analytics?${queryParams}`);
+    const response = await fetch(
+      `${API_BASE_URL}/analytics?${queryParams}`,
+      requestInit()
+    );
 
+    if (response.status === 401) {
+      throw new UnauthorizedError();
+    }
     if (!response.ok) {
       throw new Error(`HTTP error! status: ${response.status}`);
     }
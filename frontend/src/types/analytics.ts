@@ -32,6 +32,8 @@ export interface Company {
 
 export interface UserActivity {
   email: string;
+  // Stable pseudonymous ID, present when emails are masked or hashed
+  userId?: string;
   eventCount: number;
   companyName: string;
 }
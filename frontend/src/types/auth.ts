@@ -0,0 +1,9 @@
+// The signed-in caller, as returned by /api/auth/me and /api/auth/login
+export interface Principal {
+  subject: string;
+  method: string;
+  scopes: string[];
+  role: string;
+  companies?: string[];
+  privacy: string;
+}